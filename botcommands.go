@@ -0,0 +1,438 @@
+package main
+
+import (
+	"dhlottery/config"
+	"dhlottery/lottery"
+	"dhlottery/notify"
+	"dhlottery/pause"
+	"dhlottery/report"
+	"dhlottery/scheduler"
+	"dhlottery/tasks"
+	"dhlottery/telegram"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// botCommands는 스케줄러 모드에서 텔레그램 명령을 처리합니다
+// 설정을 다시 읽으면 setConfig로 새 설정과 알림 Hub를 넘겨받습니다
+type botCommands struct {
+	sched    *scheduler.Scheduler
+	listener *telegram.Listener
+	started  time.Time
+
+	mu  sync.Mutex
+	cfg config.Config
+	hub *notify.Hub // 작업 알림 (알림이 꺼져 있으면 nil)
+}
+
+// startBotCommands는 텔레그램 명령 수신을 시작합니다 (설정에서 꺼져 있으면 nil)
+func startBotCommands(cfg config.Config, hub *notify.Hub, sched *scheduler.Scheduler) *botCommands {
+	if !cfg.TelegramCommands.Enabled || cfg.TelegramBotToken == "" {
+		return nil
+	}
+
+	c := &botCommands{sched: sched, started: time.Now(), cfg: cfg, hub: hub}
+	c.listener = telegram.NewListener(telegramBot(cfg, cfg.TelegramChatID), cfg.AllowedIDs())
+
+	c.listener.Handle("help", "명령 목록", c.help)
+	c.listener.Handle("balance", "예치금 확인", c.balance)
+	c.listener.Handle("buy", "예치금 확인 후 구매 [계정]", c.buy)
+	c.listener.Handle("result", "당첨번호 조회 [회차]", c.result)
+	c.listener.Handle("winning", "당첨 확인 [회차]", c.winning)
+	c.listener.Handle("history", "최근 구매 내역 [회차 수]", c.history)
+	c.listener.Handle("next", "다음 예약 작업", c.next)
+	c.listener.Handle("pause", "일시정지 [계정] [날짜|N회]", c.pause)
+	c.listener.Handle("resume", "일시정지 해제 [계정]", c.resume)
+	c.listener.Handle("status", "서비스 상태", c.status)
+
+	if err := c.listener.Start(); err != nil {
+		log.Printf("⚠️  텔레그램 명령 수신 시작 실패: %v\n", err)
+		return nil
+	}
+
+	log.Printf("📨 텔레그램 명령 수신 시작 (허용 ID %d개)\n", len(cfg.AllowedIDs()))
+	return c
+}
+
+// stop은 명령 수신을 멈춥니다 (nil이면 아무것도 안 함)
+func (c *botCommands) stop() {
+	if c == nil {
+		return
+	}
+	c.listener.Stop()
+}
+
+// setConfig는 다시 읽은 설정과 알림 Hub로 바꿉니다 (허용 ID와 봇 토큰은 재시작해야 바뀜)
+func (c *botCommands) setConfig(cfg config.Config, hub *notify.Hub) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg = cfg
+	c.hub = hub
+}
+
+// current는 현재 설정과 알림 Hub를 반환합니다
+func (c *botCommands) current() (config.Config, *notify.Hub) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cfg, c.hub
+}
+
+func (c *botCommands) help(telegram.Command) string {
+	var sb strings.Builder
+	sb.WriteString("🤖 <b>사용할 수 있는 명령</b>\n\n")
+	for _, item := range c.listener.Commands() {
+		fmt.Fprintf(&sb, "/%s - %s\n", item[0], html.EscapeString(item[1]))
+	}
+	return sb.String()
+}
+
+func (c *botCommands) balance(telegram.Command) string {
+	cfg, _ := c.current()
+	records, err := tasks.CheckBalance(cfg, nil)
+
+	var sb strings.Builder
+	sb.WriteString("💰 <b>예치금</b>\n\n")
+	for _, r := range records {
+		switch {
+		case !r.OK:
+			fmt.Fprintf(&sb, "❌ %s: %s\n", html.EscapeString(r.Account), html.EscapeString(r.Error))
+		case !r.Sufficient:
+			fmt.Fprintf(&sb, "⚠️ %s: <b>%s원</b> (10,000원 미만)\n", html.EscapeString(r.Account), lottery.FormatMoney(r.Balance))
+		default:
+			fmt.Fprintf(&sb, "✅ %s: <b>%s원</b>\n", html.EscapeString(r.Account), lottery.FormatMoney(r.Balance))
+		}
+	}
+	if err != nil && len(records) == 0 {
+		fmt.Fprintf(&sb, "❌ %s\n", html.EscapeString(err.Error()))
+	}
+	return sb.String()
+}
+
+// buy는 구매를 백그라운드에서 실행하고 끝나면 요약을 답장합니다
+// 예약 구매, 구매 재시도와 같은 묶음으로 실행되어 겹치지 않고, 이번 회차를 이미 구매한 계정은 건너뜁니다
+// (승인을 기다리는 동안에도 /status, /pause 등 다른 명령은 계속 처리)
+func (c *botCommands) buy(cmd telegram.Command) string {
+	cfg, hub := c.current()
+	if len(cmd.Args) > 0 {
+		selected, err := cfg.Select(cmd.Args, nil)
+		if err != nil {
+			return "❌ " + html.EscapeString(err.Error())
+		}
+		cfg = selected
+	}
+
+	started := c.sched.Go("buy-command", config.JobBuy, func() error {
+		active, ok := tasks.SkipPurchased(cfg)
+		if !ok {
+			c.listener.Reply(cmd, "⏭ 모든 계정이 이번 회차를 이미 구매했습니다.")
+			return nil
+		}
+
+		// 구매 결과와 승인 요청은 평소처럼 알림 채팅방으로 가고, 여기서는 요약만 답장
+		records := tasks.CheckBalanceAndBuy(active, hub)
+		c.listener.Reply(cmd, formatPurchases(records))
+		return nil
+	})
+	if !started {
+		return "⏳ 구매 작업이 진행 중입니다. 끝난 뒤 다시 시도해주세요."
+	}
+	return "🎱 구매를 시작합니다. 끝나면 결과를 답장으로 알려드립니다."
+}
+
+// formatPurchases는 /buy 결과 요약입니다
+func formatPurchases(records []report.Purchase) string {
+	var sb strings.Builder
+	sb.WriteString("🎱 <b>구매 결과</b>\n\n")
+	for _, r := range records {
+		account := html.EscapeString(r.Account)
+		switch r.Status {
+		case report.StatusPurchased:
+			fmt.Fprintf(&sb, "✅ %s: %d게임 (%s원)\n", account, len(r.Games), lottery.FormatMoney(r.Amount))
+		case report.StatusSkipped:
+			fmt.Fprintf(&sb, "⏭ %s: 건너뜀 (%s)\n", account, html.EscapeString(r.Reason))
+		default:
+			fmt.Fprintf(&sb, "❌ %s: %s\n", account, html.EscapeString(r.Reason))
+		}
+	}
+	return sb.String()
+}
+
+func (c *botCommands) result(cmd telegram.Command) string {
+	round, err := roundArg(cmd.Args)
+	if err != nil {
+		return "❌ " + html.EscapeString(err.Error())
+	}
+
+	r, err := tasks.PrintResult(round)
+	if err != nil {
+		return "❌ " + html.EscapeString(err.Error())
+	}
+
+	first := "당첨자 없음 (이월)"
+	if r.FirstWinners > 0 {
+		first = fmt.Sprintf("%d명, 1인당 %s원", r.FirstWinners, lottery.FormatMoney(int(r.FirstPrize)))
+	}
+	return fmt.Sprintf(
+		"🎱 <b>로또 %s회 당첨번호</b>\n\n"+
+			"🗓 추첨일: %s\n"+
+			"🎯 당첨번호: <b>%s</b>\n"+
+			"➕ 보너스: <b>%02d</b>\n"+
+			"🏆 1등: %s",
+		html.EscapeString(r.Round), html.EscapeString(r.DrawDate), joinNumbers(r.Numbers), r.Bonus, first)
+}
+
+func (c *botCommands) winning(cmd telegram.Command) string {
+	round, err := roundArg(cmd.Args)
+	if err != nil {
+		return "❌ " + html.EscapeString(err.Error())
+	}
+
+	cfg, _ := c.current()
+	records, err := tasks.CheckWinningRound(cfg, nil, round)
+	if err != nil {
+		return "❌ " + html.EscapeString(err.Error())
+	}
+
+	var sb strings.Builder
+	for i, r := range records {
+		if i == 0 {
+			fmt.Fprintf(&sb, "🎰 <b>로또 %s회 당첨 확인</b>\n\n", html.EscapeString(r.Round))
+		}
+		account := html.EscapeString(r.Account)
+		switch {
+		case r.Status != report.WinningChecked:
+			fmt.Fprintf(&sb, "ℹ️ %s: %s\n", account, winningStatusLabel(r.Status))
+		case r.BestRank > 0:
+			fmt.Fprintf(&sb, "🎉 %s: <b>%d등 당첨!</b>\n", account, r.BestRank)
+		default:
+			fmt.Fprintf(&sb, "❌ %s: 낙첨 (%d게임)\n", account, len(r.Games))
+		}
+		for _, g := range r.Games {
+			if g.Rank > 0 {
+				fmt.Fprintf(&sb, "   [%s] %s → %d등\n", html.EscapeString(g.Type), joinNumbers(g.Numbers), g.Rank)
+			}
+		}
+	}
+	if sb.Len() == 0 {
+		return "ℹ️ 확인할 계정이 없습니다."
+	}
+	return sb.String()
+}
+
+func (c *botCommands) history(cmd telegram.Command) string {
+	limit := 3
+	if len(cmd.Args) > 0 {
+		n, err := strconv.Atoi(cmd.Args[0])
+		if err != nil || n <= 0 {
+			return "❌ 회차 수는 1 이상의 숫자여야 합니다 (예: /history 5)"
+		}
+		limit = n
+	}
+
+	cfg, _ := c.current()
+	entries, err := tasks.PrintHistory(cfg, limit)
+	if err != nil {
+		return "❌ " + html.EscapeString(err.Error())
+	}
+	if len(entries) == 0 {
+		return "ℹ️ 저장된 구매 내역이 없습니다."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📜 <b>구매 내역</b>\n")
+	round := ""
+	for _, e := range entries {
+		if e.Round != round {
+			round = e.Round
+			fmt.Fprintf(&sb, "\n▶ <b>%s회</b> (추첨일 %s)\n", html.EscapeString(e.Round), html.EscapeString(e.DrawDate))
+		}
+		switch e.Status {
+		case report.StatusPurchased:
+			fmt.Fprintf(&sb, "%s: %d게임\n", html.EscapeString(e.Account), len(e.Games))
+			for _, g := range e.Games {
+				fmt.Fprintf(&sb, "   [%s] %s\n", html.EscapeString(g.Type), joinNumbers(g.Numbers))
+			}
+		case report.StatusSkipped:
+			fmt.Fprintf(&sb, "%s: 건너뜀\n", html.EscapeString(e.Account))
+		default:
+			fmt.Fprintf(&sb, "%s: 구매 실패\n", html.EscapeString(e.Account))
+		}
+	}
+	return sb.String()
+}
+
+func (c *botCommands) next(telegram.Command) string {
+	jobs := c.sched.Jobs()
+	if len(jobs) == 0 {
+		return "ℹ️ 예약된 작업이 없습니다."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📅 <b>다음 예약 작업</b>\n\n")
+	for _, info := range jobs {
+		fmt.Fprintf(&sb, "• %s - <b>%s</b>\n", html.EscapeString(info.Description), info.Next.Format("01/02 (Mon) 15:04"))
+	}
+	return sb.String()
+}
+
+// pause는 "/pause [계정] [2026-12-31 | 72h | 2회]" 형식으로 일시정지합니다
+func (c *botCommands) pause(cmd telegram.Command) string {
+	cfg, _ := c.current()
+
+	var account, untilValue string
+	rounds := 0
+	for _, arg := range cmd.Args {
+		if _, err := cfg.Select([]string{arg}, nil); err == nil {
+			account = arg
+			continue
+		}
+		if n, ok := parseRounds(arg); ok {
+			rounds = n
+			continue
+		}
+		untilValue = strings.TrimSpace(untilValue + " " + arg)
+	}
+
+	now := time.Now()
+	var until time.Time
+	var skipped string // 건너뛸 회차 (회차 수 지정 시)
+	switch {
+	case untilValue != "" && rounds > 0:
+		return "❌ 날짜와 회차 수 중 하나만 지정해주세요."
+	case untilValue != "":
+		var err error
+		if until, err = pause.ParseUntil(untilValue, now, lottery.Location()); err != nil {
+			return "❌ " + html.EscapeString(err.Error())
+		}
+	case rounds > 0:
+		var first, last int
+		until, first, last = tasks.SkipRoundsUntil(cfg, account, now, rounds)
+		skipped = tasks.FormatRounds(first, last)
+	}
+
+	entry, err := tasks.Pause(cfg, account, until, "텔레그램 "+cmd.UserName)
+	if err != nil {
+		return "❌ " + html.EscapeString(err.Error())
+	}
+	reply := fmt.Sprintf("⏸ <b>%s 일시정지</b>\n\n%s", html.EscapeString(targetLabel(account)), html.EscapeString(entry.Describe()))
+	if skipped != "" {
+		reply += "\n🎟 건너뛸 회차: " + skipped
+	}
+	return reply
+}
+
+func (c *botCommands) resume(cmd telegram.Command) string {
+	cfg, _ := c.current()
+
+	account := ""
+	if len(cmd.Args) > 0 {
+		account = cmd.Args[0]
+	}
+	resumed, err := tasks.Resume(cfg, account)
+	if err != nil {
+		return "❌ " + html.EscapeString(err.Error())
+	}
+	if !resumed {
+		return fmt.Sprintf("ℹ️ %s: 일시정지 상태가 아닙니다.", html.EscapeString(targetLabel(account)))
+	}
+	return fmt.Sprintf("▶️ <b>%s 일시정지 해제</b>", html.EscapeString(targetLabel(account)))
+}
+
+func (c *botCommands) status(telegram.Command) string {
+	cfg, _ := c.current()
+
+	var sb strings.Builder
+	sb.WriteString("📊 <b>서비스 상태</b>\n\n")
+	fmt.Fprintf(&sb, "⏱ 실행 시간: %s (%s부터)\n", time.Since(c.started).Round(time.Minute), c.started.Format("01/02 15:04"))
+	fmt.Fprintf(&sb, "👤 계정: %d개\n\n", len(cfg.Accounts))
+
+	lines, err := tasks.PauseStatus(cfg)
+	if err != nil {
+		lines = []string{"⚠️ " + err.Error()}
+	}
+	for _, line := range lines {
+		sb.WriteString(html.EscapeString(line) + "\n")
+	}
+
+	sb.WriteString("\n<b>마지막 실행</b>\n")
+	for _, info := range c.sched.Jobs() {
+		state := info.State
+		if state.LastRun.IsZero() {
+			fmt.Fprintf(&sb, "• %s: 없음\n", html.EscapeString(info.Name))
+			continue
+		}
+		icon := "✅"
+		if state.LastOutcome != scheduler.OutcomeSuccess {
+			icon = "❌"
+		}
+		fmt.Fprintf(&sb, "• %s: %s %s (%s)\n", html.EscapeString(info.Name), icon,
+			state.LastRun.In(c.sched.Location()).Format("01/02 15:04"), html.EscapeString(state.LastOutcome))
+	}
+	return sb.String()
+}
+
+// roundArg는 명령 인자의 회차를 읽습니다 (없으면 0 = 최근 회차)
+func roundArg(args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, nil
+	}
+	round, err := strconv.Atoi(strings.TrimSuffix(args[0], "회"))
+	if err != nil || round <= 0 {
+		return 0, fmt.Errorf("회차는 1 이상의 숫자여야 합니다 (%q)", args[0])
+	}
+	return round, nil
+}
+
+// parseRounds는 "2회" 또는 "2r" 형식의 회차 수를 읽습니다
+func parseRounds(arg string) (int, bool) {
+	for _, suffix := range []string{"회", "r"} {
+		if value, ok := strings.CutSuffix(arg, suffix); ok {
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// targetLabel은 일시정지 대상 표시 이름입니다
+func targetLabel(account string) string {
+	if account == "" {
+		return "전체"
+	}
+	return account
+}
+
+// winningStatusLabel은 당첨 확인 상태를 설명합니다
+func winningStatusLabel(status string) string {
+	switch status {
+	case report.WinningNoHistory:
+		return "저장된 구매 내역 없음"
+	case report.WinningRoundMismatch:
+		return "구매 회차와 추첨 회차가 다름"
+	case report.WinningNoPurchase:
+		return "구매 내역 없음"
+	case report.WinningSkipped:
+		return "구매 건너뜀"
+	case report.WinningFailed:
+		return "구매 실패"
+	}
+	return status
+}
+
+// joinNumbers는 번호를 두 자리로 나열합니다 (예: 03, 11, 25)
+func joinNumbers(numbers []int) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = fmt.Sprintf("%02d", n)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,1033 @@
+package main
+
+import (
+	"dhlottery/config"
+	"dhlottery/datadir"
+	"dhlottery/kakao"
+	"dhlottery/lottery"
+	"dhlottery/notify"
+	"dhlottery/outbox"
+	"dhlottery/pause"
+	"dhlottery/report"
+	"dhlottery/scheduler"
+	"dhlottery/tasks"
+	"dhlottery/vault"
+	"dhlottery/webhook"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// usage는 명령 목록입니다
+const usage = `사용법: dhlottery [-config 경로] [-data-dir 경로] <명령> [옵션]
+
+작업 명령 (--account id1,id2 / --tag 태그로 대상 계정 선택,
+          --output json|ndjson|table로 결과를 표준 출력에 기록):
+  buy [-skip-balance]    예치금 확인 후 1회 구매 (명령이 없을 때 기본값)
+  balance                예치금 확인
+  winning [--round N]    당첨 확인 (기본: 최근 회차와 마지막 구매 내역)
+  history [-n N]         구매 내역 출력 (최근 N회차, 기본 10)
+  dryrun                 테스트 모드 (실제 구매 안 함)
+  serve                  스케줄러 모드
+
+조회 및 관리:
+  result [--round N]     당첨번호 조회 (--output 지원)
+  schedule list          예약 작업과 실행 기록
+  pause / resume         일시정지 / 재개
+  policy explain         구매 정책 평가 결과
+  outbox list|show|replay|drop   전송 대기 중인 알림
+  kakao login|test       카카오톡 나에게 보내기 토큰 발급 / 테스트 메시지
+
+설정:
+  init                   설정 마법사
+  config validate|show   설정 파일 검증 / 출력
+  vault add|list|remove|rotate   비밀번호 금고
+  backup / restore       데이터 백업 / 복원
+
+플래그:
+`
+
+// printUsage는 명령 목록과 플래그를 출력합니다
+func printUsage() {
+	fmt.Fprint(flag.CommandLine.Output(), usage)
+	flag.PrintDefaults()
+}
+
+// runCommand는 설정을 로드한 뒤 명령을 실행합니다 (예: dhlottery buy --account id1)
+func runCommand(configPath string, cfg config.Config, hub *notify.Hub, args []string) error {
+	switch args[0] {
+	case "buy":
+		return runBuyCommand(cfg, hub, args[1:])
+	case "balance":
+		return runBalanceCommand(cfg, hub, args[1:])
+	case "winning":
+		return runWinningCommand(cfg, hub, args[1:])
+	case "history":
+		return runHistoryCommand(cfg, args[1:])
+	case "dryrun":
+		return runDryRunCommand(cfg, hub, args[1:])
+	case "serve":
+		return runServeCommand(configPath, cfg, hub, args[1:])
+	case "policy":
+		return runPolicyCommand(cfg, args[1:])
+	case "schedule":
+		return runScheduleCommand(cfg, hub, args[1:])
+	case "pause":
+		return runPauseCommand(cfg, args[1:])
+	case "resume":
+		return runResumeCommand(cfg, args[1:])
+	case "outbox":
+		return runOutboxCommand(args[1:])
+	case "kakao":
+		return runKakaoCommand(cfg, args[1:])
+	default:
+		return fmt.Errorf("알 수 없는 명령: %s (dhlottery help로 명령 목록 확인)", args[0])
+	}
+}
+
+// accountFilter는 작업 명령의 --account, --tag 옵션입니다
+type accountFilter struct {
+	accounts string
+	tags     string
+}
+
+// addAccountFilter는 명령의 플래그에 --account, --tag 옵션을 추가합니다
+func addAccountFilter(fs *flag.FlagSet) *accountFilter {
+	f := &accountFilter{}
+	fs.StringVar(&f.accounts, "account", "", "대상 계정 아이디 (쉼표로 구분, 예: id1,id2)")
+	fs.StringVar(&f.tags, "tag", "", "대상 계정 태그 (쉼표로 구분, 예: family)")
+	return f
+}
+
+// apply는 필터에 맞는 계정만 남긴 설정을 반환합니다 (필터가 없으면 그대로)
+func (f *accountFilter) apply(cfg config.Config) (config.Config, error) {
+	if f == nil {
+		return cfg, nil
+	}
+	selected, err := cfg.Select(splitList(f.accounts), splitList(f.tags))
+	if err != nil {
+		return config.Config{}, err
+	}
+	if len(selected.Accounts) < len(cfg.Accounts) {
+		ids := make([]string, len(selected.Accounts))
+		for i, account := range selected.Accounts {
+			ids[i] = account.UserID
+		}
+		log.Printf("🎯 대상 계정: %s\n", strings.Join(ids, ", "))
+	}
+	return selected, nil
+}
+
+// splitList는 쉼표로 구분된 목록을 나눕니다 (빈 항목 제외)
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseJobFlags는 작업 명령의 플래그를 파싱하고 대상 계정만 남긴 설정을 반환합니다
+func parseJobFlags(fs *flag.FlagSet, cfg config.Config, args []string) (config.Config, *accountFilter, error) {
+	filter := addAccountFilter(fs)
+	if err := fs.Parse(args); err != nil {
+		return config.Config{}, nil, err
+	}
+	if fs.NArg() > 0 {
+		return config.Config{}, nil, fmt.Errorf("%s: 알 수 없는 인자 %q", fs.Name(), fs.Arg(0))
+	}
+	cfg, err := filter.apply(cfg)
+	return cfg, filter, err
+}
+
+// addOutputFlag는 결과 출력 형식 플래그(--output)를 등록합니다
+func addOutputFlag(fs *flag.FlagSet) *string {
+	return fs.String("output", "", "결과 출력 형식: json, ndjson, table (기본: 로그만 출력)")
+}
+
+// writeOutput은 작업 결과를 표준 출력에 씁니다 (로그는 표준 에러로 나가므로 섞이지 않음)
+// 작업 에러가 있어도 결과를 먼저 쓰고 작업 에러를 반환합니다
+func writeOutput[T report.Record](format report.Format, kind string, records []T, jobErr error) error {
+	if err := report.Write(os.Stdout, format, kind, records); err != nil {
+		return fmt.Errorf("결과 출력 실패: %w", err)
+	}
+	return jobErr
+}
+
+// runBuyCommand는 예치금 확인 후 로또를 구매합니다
+//
+//	dhlottery buy [-skip-balance] [--output json|ndjson|table] [--account id1,id2] [--tag 태그]
+func runBuyCommand(cfg config.Config, hub *notify.Hub, args []string) error {
+	fs := flag.NewFlagSet("buy", flag.ContinueOnError)
+	skipBalance := fs.Bool("skip-balance", false, "예치금 확인 없이 바로 구매")
+	output := addOutputFlag(fs)
+	cfg, _, err := parseJobFlags(fs, cfg, args)
+	if err != nil {
+		return err
+	}
+	format, err := report.ParseFormat(*output)
+	if err != nil {
+		return err
+	}
+
+	var records []report.Purchase
+	if *skipBalance {
+		records = tasks.BuyLotto(cfg, hub)
+	} else {
+		records = tasks.CheckBalanceAndBuy(cfg, hub)
+	}
+	return writeOutput(format, report.KindPurchase, records, nil)
+}
+
+// runBalanceCommand는 예치금을 확인합니다
+//
+//	dhlottery balance [--output json|ndjson|table] [--account id1,id2] [--tag 태그]
+func runBalanceCommand(cfg config.Config, hub *notify.Hub, args []string) error {
+	fs := flag.NewFlagSet("balance", flag.ContinueOnError)
+	output := addOutputFlag(fs)
+	cfg, _, err := parseJobFlags(fs, cfg, args)
+	if err != nil {
+		return err
+	}
+	format, err := report.ParseFormat(*output)
+	if err != nil {
+		return err
+	}
+
+	records, err := tasks.CheckBalance(cfg, hub)
+	return writeOutput(format, report.KindBalance, records, err)
+}
+
+// runWinningCommand는 당첨 여부를 확인합니다
+//
+//	dhlottery winning [--round N] [--output json|ndjson|table] [--account id1,id2] [--tag 태그]
+func runWinningCommand(cfg config.Config, hub *notify.Hub, args []string) error {
+	fs := flag.NewFlagSet("winning", flag.ContinueOnError)
+	round := fs.Int("round", 0, "확인할 회차 (기본: 최근 회차)")
+	output := addOutputFlag(fs)
+	cfg, _, err := parseJobFlags(fs, cfg, args)
+	if err != nil {
+		return err
+	}
+	format, err := report.ParseFormat(*output)
+	if err != nil {
+		return err
+	}
+
+	records, err := tasks.CheckWinningRound(cfg, hub, *round)
+	if err != nil {
+		return err
+	}
+	return writeOutput(format, report.KindWinning, records, nil)
+}
+
+// runHistoryCommand는 보관된 구매 내역을 출력합니다
+//
+//	dhlottery history [-n N] [--output json|ndjson|table] [--account id1,id2] [--tag 태그]
+func runHistoryCommand(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	limit := fs.Int("n", 10, "출력할 최근 회차 수 (0이면 전체)")
+	output := addOutputFlag(fs)
+	cfg, _, err := parseJobFlags(fs, cfg, args)
+	if err != nil {
+		return err
+	}
+	format, err := report.ParseFormat(*output)
+	if err != nil {
+		return err
+	}
+
+	records, err := tasks.PrintHistory(cfg, *limit)
+	if err != nil {
+		return err
+	}
+	return writeOutput(format, report.KindHistory, records, nil)
+}
+
+// runDryRunCommand는 실제 구매 없이 구매 과정을 점검합니다
+//
+//	dhlottery dryrun [--account id1,id2] [--tag 태그]
+func runDryRunCommand(cfg config.Config, hub *notify.Hub, args []string) error {
+	cfg, _, err := parseJobFlags(flag.NewFlagSet("dryrun", flag.ContinueOnError), cfg, args)
+	if err != nil {
+		return err
+	}
+	tasks.DryRun(cfg, hub)
+	return nil
+}
+
+// runServeCommand는 스케줄러 모드로 실행합니다
+//
+//	dhlottery serve [--account id1,id2] [--tag 태그]
+func runServeCommand(configPath string, cfg config.Config, hub *notify.Hub, args []string) error {
+	cfg, filter, err := parseJobFlags(flag.NewFlagSet("serve", flag.ContinueOnError), cfg, args)
+	if err != nil {
+		return err
+	}
+	runScheduler(config.FindFile(configPath), cfg, hub, filter)
+	return nil
+}
+
+// runResultCommand는 당첨번호를 조회합니다 (설정 로드 전에 실행)
+//
+//	dhlottery result [--round N] [--output json|ndjson|table]
+func runResultCommand(_ string, args []string) error {
+	fs := flag.NewFlagSet("result", flag.ContinueOnError)
+	round := fs.Int("round", 0, "조회할 회차 (기본: 최근 회차)")
+	output := addOutputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	format, err := report.ParseFormat(*output)
+	if err != nil {
+		return err
+	}
+
+	result, err := tasks.PrintResult(*round)
+	if err != nil {
+		return err
+	}
+	return writeOutput(format, report.KindResult, []report.Result{result}, nil)
+}
+
+// runPolicyCommand는 구매 정책 관련 명령을 실행합니다
+func runPolicyCommand(cfg config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "explain" {
+		return fmt.Errorf("사용법: dhlottery policy explain")
+	}
+
+	tasks.ExplainPolicy(cfg)
+	return nil
+}
+
+// runScheduleCommand는 일정 관련 명령을 실행합니다
+func runScheduleCommand(cfg config.Config, hub *notify.Hub, args []string) error {
+	if len(args) == 0 || args[0] != "list" {
+		return fmt.Errorf("사용법: dhlottery schedule list [-n 개수]")
+	}
+
+	fs := flag.NewFlagSet("schedule list", flag.ContinueOnError)
+	count := fs.Int("n", 3, "작업별로 보여줄 다음 실행 시각 개수")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	sched := scheduler.New()
+	if err := registerSchedules(cfg, hub, sched); err != nil {
+		return err
+	}
+
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	log.Println("          📅 등록된 작업")
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	for _, info := range sched.Jobs() {
+		log.Println()
+		log.Printf("▶ %s (%s)\n", info.Name, info.Description)
+		log.Printf("   일정: %s\n", scheduler.DescribeSpec(info.Spec))
+
+		state := info.State
+		if state.LastRun.IsZero() {
+			log.Println("   마지막 실행: 없음")
+		} else {
+			log.Printf("   마지막 실행: %s (%s, %s)\n",
+				state.LastRun.In(sched.Location()).Format("2006/01/02 15:04:05"), state.LastOutcome, state.LastDuration)
+			if state.LastError != "" {
+				log.Printf("   마지막 오류: %s\n", state.LastError)
+			}
+		}
+
+		log.Println("   다음 실행:")
+		for _, next := range sched.NextRuns(info.Name, *count) {
+			log.Printf("     - %s\n", next.Format("2006/01/02 (Mon) 15:04"))
+		}
+	}
+
+	log.Println()
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	return nil
+}
+
+// runPauseCommand는 전체 또는 계정별 일시정지를 설정하거나 상태를 보여줍니다
+//
+//	dhlottery pause status
+//	dhlottery pause [-account ID] [-until 2026-12-31 | -rounds N] [-reason 휴가]
+func runPauseCommand(cfg config.Config, args []string) error {
+	if len(args) > 0 && args[0] == "status" {
+		tasks.PrintPauseStatus(cfg)
+		return nil
+	}
+
+	fs := flag.NewFlagSet("pause", flag.ContinueOnError)
+	account := fs.String("account", "", "일시정지할 계정 (생략하면 전체)")
+	untilValue := fs.String("until", "", "해제 시각 (2026-12-31, \"2026-12-31 18:00\", 72h)")
+	rounds := fs.Int("rounds", 0, "다음 예약 구매의 회차부터 건너뛸 회차 수 (1 = 다음 구매 1회만 건너뛰기)")
+	reason := fs.String("reason", "", "일시정지 사유")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var until time.Time
+	var skipped string // 건너뛸 회차 (-rounds 사용 시)
+	switch {
+	case *untilValue != "" && *rounds > 0:
+		return fmt.Errorf("-until과 -rounds 중 하나만 지정해야 합니다")
+	case *untilValue != "":
+		var err error
+		if until, err = pause.ParseUntil(*untilValue, now, lottery.Location()); err != nil {
+			return err
+		}
+	case *rounds > 0:
+		var first, last int
+		until, first, last = tasks.SkipRoundsUntil(cfg, *account, now, *rounds)
+		skipped = tasks.FormatRounds(first, last)
+	case *rounds < 0:
+		return fmt.Errorf("-rounds는 1 이상이어야 합니다")
+	}
+
+	entry, err := tasks.Pause(cfg, *account, until, *reason)
+	if err != nil {
+		return err
+	}
+
+	target := "전체"
+	if *account != "" {
+		target = *account
+	}
+	log.Printf("⏸  %s 일시정지: %s\n", target, entry.Describe())
+	if skipped != "" {
+		log.Printf("   건너뛸 회차: %s\n", skipped)
+	}
+	return nil
+}
+
+// runResumeCommand는 전체 또는 계정별 일시정지를 해제합니다
+//
+//	dhlottery resume [-account ID]
+func runResumeCommand(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("resume", flag.ContinueOnError)
+	account := fs.String("account", "", "재개할 계정 (생략하면 전체)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	resumed, err := tasks.Resume(cfg, *account)
+	if err != nil {
+		return err
+	}
+
+	target := "전체"
+	if *account != "" {
+		target = *account
+	}
+	if !resumed {
+		log.Printf("ℹ️  %s: 일시정지 상태가 아닙니다\n", target)
+		return nil
+	}
+	log.Printf("▶️  %s 일시정지 해제\n", target)
+	return nil
+}
+
+// kakaoRedirectURI는 카카오 로그인 후 돌아갈 기본 주소입니다 (앱의 카카오 로그인 Redirect URI에 등록)
+const kakaoRedirectURI = "http://localhost:8080/kakao"
+
+// runKakaoCommand는 카카오톡 나에게 보내기 토큰을 발급하고 테스트 메시지를 보냅니다
+//
+//	dhlottery kakao login [-redirect-uri 주소]   카카오 로그인으로 리프레시 토큰 발급
+//	dhlottery kakao test                        설정한 모든 토큰으로 테스트 메시지 전송
+func runKakaoCommand(cfg config.Config, args []string) error {
+	usage := fmt.Errorf("사용법: dhlottery kakao login [-redirect-uri 주소] | test")
+	if len(args) == 0 {
+		return usage
+	}
+	if cfg.Kakao.RestAPIKey == "" {
+		return fmt.Errorf("카카오 앱의 REST API 키(kakao.restApiKey 또는 KAKAO_REST_API_KEY)가 필요합니다")
+	}
+
+	switch args[0] {
+	case "login":
+		fs := flag.NewFlagSet("kakao login", flag.ContinueOnError)
+		redirectURI := fs.String("redirect-uri", kakaoRedirectURI, "앱에 등록한 Redirect URI")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		talk := kakao.New(kakaoOptions(cfg), nil)
+		fmt.Println("1. 알림을 받을 카카오 계정으로 아래 주소를 열어 로그인하고 메시지 전송에 동의하세요:")
+		fmt.Println()
+		fmt.Println("   " + talk.AuthorizeURL(*redirectURI))
+		fmt.Println()
+		fmt.Println("2. 이동한 페이지 주소창의 code= 값 (또는 주소 전체)을 붙여 넣으세요.")
+		code := kakaoCode(newPrompter().ask("인가 코드", ""))
+		if code == "" {
+			return fmt.Errorf("인가 코드가 필요합니다")
+		}
+
+		refreshToken, err := talk.Login(code, *redirectURI)
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ 카카오 토큰 발급 완료 (%s)\n", kakao.Key(refreshToken))
+		fmt.Println()
+		fmt.Println("설정 파일의 kakao.refreshToken (공통 수신자), accounts[].kakaoRefreshToken 또는")
+		fmt.Println("notify.recipients[].kakaoRefreshToken에 아래 값을 넣으세요:")
+		fmt.Println()
+		fmt.Println(refreshToken)
+		return nil
+
+	case "test":
+		tokens := cfg.KakaoTokens()
+		if len(tokens) == 0 {
+			return fmt.Errorf("설정에 카카오 리프레시 토큰이 없습니다 (dhlottery kakao login으로 발급)")
+		}
+		talk := kakaoClient(cfg)
+		failed := 0
+		for _, token := range tokens {
+			memo := talk.Memo(token, nil)
+			if err := memo.Send(kakao.Text("🔔 동행복권 알림 테스트 메시지입니다.", notify.KakaoLink(cfg))); err != nil {
+				log.Printf("❌ %s: %v\n", memo.Key(), err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("카카오톡 테스트 메시지 %d/%d개 전송 실패", failed, len(tokens))
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("알 수 없는 명령: kakao %s", args[0])
+	}
+}
+
+// kakaoCode는 입력에서 인가 코드를 꺼냅니다 (돌아간 주소 전체를 붙여 넣어도 됨)
+func kakaoCode(input string) string {
+	if u, err := url.Parse(input); err == nil && u.Query().Has("code") {
+		return u.Query().Get("code")
+	}
+	return input
+}
+
+// runOutboxCommand는 알림 보관함(텔레그램, 슬랙, 디스코드, 카카오톡)을 조회하고 다시 보냅니다
+//
+//	dhlottery outbox list [-dead]          전송 대기 (또는 포기한) 알림 목록
+//	dhlottery outbox show <ID>             알림 내용
+//	dhlottery outbox replay [-dead] [ID...]   바로 다시 전송 (ID를 생략하면 전체)
+//	dhlottery outbox drop <ID>             알림 삭제
+func runOutboxCommand(args []string) error {
+	usage := fmt.Errorf("사용법: dhlottery outbox list [-dead] | show <ID> | replay [-dead] [ID...] | drop <ID>")
+	if len(args) == 0 {
+		return usage
+	}
+
+	box := notifications
+	if box == nil {
+		var err error
+		if box, err = outbox.Open(datadir.Path(datadir.Outbox), nil); err != nil {
+			return err
+		}
+	}
+
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("outbox list", flag.ContinueOnError)
+		dead := fs.Bool("dead", false, "전송을 포기한 알림 목록")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		list, label := box.Pending, "전송 대기"
+		if *dead {
+			list, label = box.Dead, "전송 포기"
+		}
+		messages, err := list()
+		if err != nil {
+			return err
+		}
+		log.Printf("📮 %s 알림: %d개\n", label, len(messages))
+		for _, msg := range messages {
+			fmt.Printf("%s\t%s\t%d회 실패\t%s\n", msg.ID, msg.CreatedAt.Local().Format("01/02 15:04:05"), msg.Attempts, summary(msg))
+			if msg.LastError != "" {
+				fmt.Printf("\t└ %s\n", msg.LastError)
+			}
+		}
+		return nil
+
+	case "show":
+		if len(args) != 2 {
+			return usage
+		}
+		msg, dead, err := box.Get(args[1])
+		if err != nil {
+			return err
+		}
+		state := "전송 대기"
+		if dead {
+			state = "전송 포기 (" + msg.DeadAt.Local().Format("2006/01/02 15:04:05") + ")"
+		}
+		log.Printf("📮 %s: %s, %s, %d회 실패\n", msg.ID, state, destination(msg), msg.Attempts)
+		if msg.LastError != "" {
+			log.Printf("   마지막 오류: %s\n", msg.LastError)
+		}
+		if !dead && !msg.NextAttempt.IsZero() {
+			log.Printf("   다음 시도: %s\n", msg.NextAttempt.Local().Format("2006/01/02 15:04:05"))
+		}
+		if msg.IsWebhook() || msg.IsChannel() {
+			fmt.Println(string(msg.Payload))
+			return nil
+		}
+		if len(msg.Photos) > 0 {
+			log.Printf("   사진 %d장\n", len(msg.Photos))
+			for _, photo := range msg.Photos {
+				fmt.Println(photo.Caption)
+			}
+			return nil
+		}
+		fmt.Println(msg.Text)
+		return nil
+
+	case "replay":
+		fs := flag.NewFlagSet("outbox replay", flag.ContinueOnError)
+		dead := fs.Bool("dead", false, "전송을 포기한 알림을 다시 전송")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if notifications == nil {
+			return fmt.Errorf("알림 설정(텔레그램, 슬랙, 디스코드, 카카오톡)이 없어 알림을 전송할 수 없습니다")
+		}
+
+		count, err := box.Replay(fs.Args(), *dead)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			log.Println("ℹ️  다시 보낼 알림이 없습니다")
+			return nil
+		}
+		log.Printf("📮 알림 %d개를 다시 전송합니다\n", count)
+		flushNotifications()
+		return nil
+
+	case "drop":
+		if len(args) != 2 {
+			return usage
+		}
+		if err := box.Drop(args[1]); err != nil {
+			return err
+		}
+		log.Printf("🗑  알림 %s를 삭제했습니다\n", args[1])
+		return nil
+
+	default:
+		return fmt.Errorf("알 수 없는 명령: outbox %s", args[0])
+	}
+}
+
+// destination은 알림을 받는 곳 표시입니다 (웹훅은 토큰을 가린 주소, 카카오톡은 토큰 대신 저장한 Key)
+func destination(msg outbox.Message) string {
+	if msg.IsWebhook() {
+		return msg.Channel + " " + webhook.Redact(msg.Webhook)
+	}
+	if msg.IsChannel() {
+		return msg.Target
+	}
+	return "채팅방 " + msg.ChatID
+}
+
+// summary는 알림 목록에 보여줄 메시지 첫 줄입니다 (HTML 태그 제외, 사진은 장수와 첫 캡션, 웹훅·채널은 채널 이름)
+func summary(msg outbox.Message) string {
+	if msg.IsWebhook() || msg.IsChannel() {
+		return "[" + msg.Channel + "] " + webhookSummary(msg.Payload)
+	}
+	text := msg.Text
+	if len(msg.Photos) > 0 {
+		text = fmt.Sprintf("[사진 %d장] %s", len(msg.Photos), msg.Photos[0].Caption)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	line = htmlTag.ReplaceAllString(line, "")
+	if runes := []rune(line); len(runes) > 40 {
+		line = string(runes[:40]) + "…"
+	}
+	line = html.UnescapeString(line)
+	if msg.Sent > 0 {
+		line += fmt.Sprintf(" (앞 %d부분 전송됨)", msg.Sent)
+	}
+	return line
+}
+
+// webhookSummary는 웹훅·채널 본문의 대표 문구입니다
+// (슬랙 대체 문구, 디스코드 본문이나 임베드 제목, 카카오톡 텍스트나 피드·리스트 제목)
+func webhookSummary(payload []byte) string {
+	var p struct {
+		Text    string          `json:"text"`
+		Content json.RawMessage `json:"content"`
+		Embeds  []struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		} `json:"embeds"`
+		HeaderTitle string `json:"header_title"`
+	}
+	_ = json.Unmarshal(payload, &p)
+
+	// content는 디스코드에서는 문자열, 카카오톡 피드에서는 제목이 든 객체
+	var content string
+	var feed struct {
+		Title string `json:"title"`
+	}
+	if json.Unmarshal(p.Content, &content) != nil && json.Unmarshal(p.Content, &feed) == nil {
+		content = feed.Title
+	}
+	text := p.Text + content + p.HeaderTitle
+	if text == "" && len(p.Embeds) > 0 {
+		text = p.Embeds[0].Title + p.Embeds[0].Description
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if runes := []rune(line); len(runes) > 40 {
+		line = string(runes[:40]) + "…"
+	}
+	return line
+}
+
+// htmlTag는 텔레그램 HTML 메시지의 태그입니다
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// runConfigCommand는 설정 파일 관련 명령을 실행합니다 (설정 로드 전에 실행)
+//
+//	dhlottery [-config 경로] config validate
+//	dhlottery [-config 경로] config show [-redacted] [-format json|yaml|toml]
+func runConfigCommand(configPath string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("사용법: dhlottery config validate | config show [-redacted] [-format json|yaml|toml]")
+	}
+
+	file := config.FindFile(configPath)
+
+	switch args[0] {
+	case "validate":
+		if file == "" {
+			return fmt.Errorf("설정 파일이 없습니다 (%s)", strings.Join(config.DefaultFiles, ", "))
+		}
+		if _, err := config.LoadFromFile(file); err != nil {
+			var verr *config.ValidationError
+			if errors.As(err, &verr) {
+				for _, issue := range verr.Issues {
+					log.Printf("❌ %s:%s\n", file, issue)
+				}
+				return fmt.Errorf("설정 파일 오류 %d건", len(verr.Issues))
+			}
+			return err
+		}
+		log.Printf("✅ 설정 파일이 올바릅니다: %s\n", file)
+		return nil
+
+	case "show":
+		fs := flag.NewFlagSet("config show", flag.ContinueOnError)
+		redacted := fs.Bool("redacted", false, "비밀번호와 토큰을 가려서 출력")
+		format := fs.String("format", "", "출력 형식 (json, yaml, toml / 기본: 설정 파일 형식)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		var cfg config.Config
+		var err error
+		if file != "" {
+			cfg, err = config.LoadFromFile(file)
+		} else {
+			cfg, err = config.LoadFromEnv()
+		}
+		if err != nil {
+			return err
+		}
+		if *redacted {
+			cfg = cfg.Redacted()
+		}
+
+		outFormat := config.Format(*format)
+		if outFormat == "" {
+			outFormat = config.FormatJSON
+			if file != "" {
+				outFormat, _ = config.FormatOf(file)
+			}
+		}
+
+		data, err := cfg.Marshal(outFormat)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+
+	default:
+		return fmt.Errorf("알 수 없는 명령: config %s", args[0])
+	}
+}
+
+// runVaultCommand는 비밀번호 금고 명령을 실행합니다 (설정 로드 전에 실행)
+//
+//	dhlottery vault add <아이디>       비밀번호 저장 (없으면 금고 생성)
+//	dhlottery vault list               저장된 계정 목록
+//	dhlottery vault remove <아이디>    비밀번호 삭제
+//	dhlottery vault rotate [-new-key-file 경로]   새 암호(또는 키 파일)로 다시 암호화
+func runVaultCommand(configPath string, args []string) error {
+	usage := fmt.Errorf("사용법: dhlottery vault add <아이디> | list | remove <아이디> | rotate [-new-key-file 경로]")
+	if len(args) == 0 {
+		return usage
+	}
+
+	settings, err := config.VaultSettings(configPath)
+	if err != nil {
+		return err
+	}
+	path := settings.FilePath()
+
+	switch args[0] {
+	case "add":
+		if len(args) != 2 {
+			return usage
+		}
+		userID := args[1]
+
+		var v *vault.Vault
+		if vault.Exists(path) {
+			v, err = settings.OpenVault()
+		} else {
+			log.Printf("🔐 새 금고를 만듭니다: %s\n", path)
+			var key vault.Key
+			if key, err = newVaultKey(settings.KeyFile); err == nil {
+				v, err = vault.Create(path, key)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		password, err := readNewSecret(fmt.Sprintf("%s 비밀번호: ", userID))
+		if err != nil {
+			return err
+		}
+		_, replaced := v.Get(userID)
+		v.Set(userID, password)
+		if err := v.Save(); err != nil {
+			return err
+		}
+
+		if replaced {
+			log.Printf("✅ %s 비밀번호를 바꿨습니다 (%s)\n", userID, path)
+		} else {
+			log.Printf("✅ %s 비밀번호를 저장했습니다 (%s)\n", userID, path)
+		}
+		log.Println("   설정 파일의 password 항목은 지워도 됩니다 (비어 있으면 금고에서 찾음)")
+		return nil
+
+	case "list":
+		v, err := settings.OpenVault()
+		if err != nil {
+			return err
+		}
+		names := v.Names()
+		log.Printf("🔐 금고: %s (%d개 계정)\n", path, len(names))
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+
+	case "remove":
+		if len(args) != 2 {
+			return usage
+		}
+		v, err := settings.OpenVault()
+		if err != nil {
+			return err
+		}
+		if !v.Remove(args[1]) {
+			return fmt.Errorf("금고에 %s 계정이 없습니다", args[1])
+		}
+		if err := v.Save(); err != nil {
+			return err
+		}
+		log.Printf("🗑  %s 비밀번호를 삭제했습니다\n", args[1])
+		return nil
+
+	case "rotate":
+		fs := flag.NewFlagSet("vault rotate", flag.ContinueOnError)
+		newKeyFile := fs.String("new-key-file", "", "암호 대신 새 키 파일을 생성하여 사용")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		v, err := settings.OpenVault()
+		if err != nil {
+			return err
+		}
+
+		var key vault.Key
+		if *newKeyFile != "" {
+			if _, err := os.Stat(*newKeyFile); err == nil {
+				return fmt.Errorf("키 파일이 이미 있습니다: %s (덮어쓰지 않음)", *newKeyFile)
+			}
+			key, err = vault.GenerateKeyFile(*newKeyFile)
+		} else {
+			key, err = newPassphrase()
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := v.Rotate(key); err != nil {
+			return err
+		}
+		log.Printf("✅ 금고를 새 키로 다시 암호화했습니다 (%s)\n", path)
+		if *newKeyFile != "" {
+			log.Printf("   설정 파일에 vault.keyFile: %s 를 지정하세요\n", *newKeyFile)
+		} else if settings.KeyFile != "" {
+			log.Println("   이제 암호로 잠겨 있으므로 설정 파일의 vault.keyFile을 지우세요")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("알 수 없는 명령: vault %s", args[0])
+	}
+}
+
+// newVaultKey는 새 금고의 키를 정합니다 (키 파일 설정이 있으면 생성 또는 사용, 없으면 새 암호)
+func newVaultKey(keyFile string) (vault.Key, error) {
+	if keyFile == "" {
+		return newPassphrase()
+	}
+	if _, err := os.Stat(keyFile); err == nil {
+		return vault.KeyFromFile(keyFile)
+	}
+	log.Printf("🔑 새 키 파일을 만듭니다: %s\n", keyFile)
+	return vault.GenerateKeyFile(keyFile)
+}
+
+// newPassphrase는 새 금고 암호를 입력받습니다 (DH_VAULT_PASSPHRASE가 있으면 사용)
+func newPassphrase() (vault.Key, error) {
+	if passphrase := os.Getenv("DH_VAULT_PASSPHRASE"); passphrase != "" {
+		return vault.Passphrase(passphrase), nil
+	}
+	passphrase, err := readNewSecret("🔐 새 금고 암호: ")
+	if err != nil {
+		return vault.Key{}, err
+	}
+	return vault.Passphrase(passphrase), nil
+}
+
+// readNewSecret은 비밀 값을 화면에 표시하지 않고 두 번 입력받아 확인합니다 (터미널이 아니면 한 번)
+func readNewSecret(prompt string) (string, error) {
+	secret, err := config.ReadSecret(prompt)
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", fmt.Errorf("빈 값은 저장할 수 없습니다")
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		confirm, err := config.ReadSecret("한 번 더 입력: ")
+		if err != nil {
+			return "", err
+		}
+		if confirm != secret {
+			return "", fmt.Errorf("입력한 값이 서로 다릅니다")
+		}
+	}
+	return secret, nil
+}
+
+// runBackupCommand는 데이터 디렉토리와 설정 파일, 금고를 백업 파일 하나로 만듭니다 (설정 로드 전에 실행)
+// 금고 키 파일은 금고와 같은 파일에 두지 않도록 포함하지 않습니다
+//
+//	dhlottery backup [-o 파일] [-logs]
+func runBackupCommand(configPath string, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	output := fs.String("o", fmt.Sprintf("dhlottery-backup-%s.tar.gz", time.Now().Format("20060102-150405")), "백업 파일 경로")
+	includeLogs := fs.Bool("logs", false, "실행 로그도 포함")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var files []string
+	if file := config.FindFile(configPath); file != "" {
+		files = append(files, file)
+	}
+	settings, err := config.VaultSettings(configPath)
+	if err != nil {
+		return err
+	}
+	// 데이터 디렉토리 안의 금고(기본 위치)는 데이터와 함께 백업됨
+	if path := settings.FilePath(); vault.Exists(path) && !datadir.Contains(path) {
+		files = append(files, path)
+	}
+
+	out, err := os.OpenFile(*output, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("백업 파일 생성 실패: %w", err)
+	}
+	manifest, err := datadir.Backup(out, datadir.BackupOptions{IncludeLogs: *includeLogs, ConfigFiles: files})
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*output)
+		return err
+	}
+
+	log.Printf("✅ 백업 완료: %s (파일 %d개)\n", *output, len(manifest.Files))
+	log.Printf("   데이터 디렉토리: %s\n", manifest.DataDir)
+	for _, file := range files {
+		log.Printf("   설정: %s\n", file)
+	}
+	if settings.KeyFile != "" {
+		log.Printf("⚠️  금고 키 파일(%s)은 포함되지 않았습니다. 새 서버로 따로 옮기세요\n", settings.KeyFile)
+	}
+	return nil
+}
+
+// runRestoreCommand는 백업 파일을 데이터 디렉토리와 설정 파일 위치에 복원합니다 (설정 로드 전에 실행)
+//
+//	dhlottery [-data-dir 경로] restore [-force] <백업 파일>
+func runRestoreCommand(configPath string, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	force := fs.Bool("force", false, "이미 있는 파일 덮어쓰기")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("사용법: dhlottery restore [-force] <백업 파일>")
+	}
+
+	in, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("백업 파일 열기 실패: %w", err)
+	}
+	defer in.Close()
+
+	configDir := "."
+	if configPath != "" {
+		configDir = filepath.Dir(configPath)
+	}
+
+	manifest, err := datadir.Restore(in, configDir, *force)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("✅ 복원 완료: 파일 %d개 (%s 백업)\n", len(manifest.Files), manifest.CreatedAt.Format("2006/01/02 15:04"))
+	log.Printf("   데이터 디렉토리: %s\n", datadir.Dir())
+	log.Printf("   설정 파일 위치: %s\n", configDir)
+	return nil
+}
@@ -2,96 +2,585 @@ package config
 
 import (
 	"bufio"
+	"dhlottery/policy"
+	"dhlottery/scheduler"
+	"dhlottery/webhook"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Account는 개별 계정 정보를 담는 구조체입니다
+// 비밀번호는 password, passwordFile, passwordCommand 중 하나로 지정하며, 모두 비우면 금고에서 찾습니다
 type Account struct {
-	UserID   string `json:"userId"`
-	Password string `json:"password"`
+	UserID            string          `json:"userId"`
+	Password          string          `json:"password,omitempty"`          // 평문 비밀번호 (권장하지 않음)
+	PasswordFile      string          `json:"passwordFile,omitempty"`      // 비밀번호가 담긴 파일 (예: Docker secret)
+	PasswordCommand   string          `json:"passwordCommand,omitempty"`   // 비밀번호를 출력하는 명령 (예: "pass show dhlottery")
+	Alias             string          `json:"alias,omitempty"`             // 메시지에 아이디 대신 표시할 이름 (예: "아빠")
+	TelegramChatID    string          `json:"telegramChatId,omitempty"`    // 계정별 알림 채팅방 (비우면 공통 채팅방)
+	KakaoRefreshToken string          `json:"kakaoRefreshToken,omitempty"` // 계정별 카카오톡 나에게 보내기 리프레시 토큰 (비우면 kakao.refreshToken)
+	Notify            []string        `json:"notify,omitempty"`            // 알림 수신자 이름 (notify.recipients, 지정하면 telegramChatId, kakaoRefreshToken 대신 사용)
+	Tags              []string        `json:"tags,omitempty"`              // 계정 묶음 이름 (예: ["family"], 명령의 --tag 필터에 사용)
+	Approval          Approval        `json:"approval"`
+	Policy            *PurchasePolicy `json:"policy,omitempty"`
+
+	vault   *VaultConfig // 금고 설정 (로드 시 연결)
+	routing *routing     // 알림 수신자와 규칙 (로드 시 연결)
+}
+
+// PurchasePolicy는 회차별 구매 게임 수를 정하는 조건부 구매 정책입니다
+// 규칙은 위에서부터 평가되며 처음 일치하는 규칙의 게임 수로 구매합니다
+type PurchasePolicy struct {
+	Rules        []PolicyRule `json:"rules"`
+	DefaultGames *int         `json:"defaultGames,omitempty"` // 일치하는 규칙이 없을 때 게임 수 (기본 5)
+	RecentRounds int          `json:"recentRounds,omitempty"` // recentSpend 계산에 사용할 최근 회차 수 (기본 4)
+}
+
+// PolicyRule은 조건식과 구매 게임 수로 이루어진 정책 규칙입니다
+type PolicyRule struct {
+	Name  string `json:"name"`
+	When  string `json:"when"`  // 조건식 (예: "carryover || lastJackpot >= 30억")
+	Games int    `json:"games"` // 구매 게임 수 (0~5, 0이면 구매 안 함)
+}
+
+// 구매 정책 기본값
+const (
+	defaultPolicyGames  = 5
+	defaultRecentRounds = 4
+)
+
+// Compile은 정책 규칙의 조건식을 파싱합니다
+func (p *PurchasePolicy) Compile() ([]policy.Rule, error) {
+	rules := make([]policy.Rule, 0, len(p.Rules))
+	for i, r := range p.Rules {
+		expr, err := policy.Parse(r.When)
+		if err != nil {
+			return nil, fmt.Errorf("규칙 %d (%s) 조건식 오류: %w", i+1, r.Name, err)
+		}
+		if r.Games < 0 || r.Games > 5 {
+			return nil, fmt.Errorf("규칙 %d (%s): games는 0~5 사이여야 합니다 (%d)", i+1, r.Name, r.Games)
+		}
+
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("규칙 %d", i+1)
+		}
+		rules = append(rules, policy.Rule{Name: name, When: expr, Games: r.Games})
+	}
+	return rules, nil
+}
+
+// Default는 일치하는 규칙이 없을 때 구매할 게임 수를 반환합니다
+func (p *PurchasePolicy) Default() int {
+	if p.DefaultGames == nil {
+		return defaultPolicyGames
+	}
+	return *p.DefaultGames
+}
+
+// Recent는 recentSpend 계산에 사용할 회차 수를 반환합니다
+func (p *PurchasePolicy) Recent() int {
+	if p.RecentRounds <= 0 {
+		return defaultRecentRounds
+	}
+	return p.RecentRounds
+}
+
+// Approval은 예약 구매 전 텔레그램 승인 설정입니다
+type Approval struct {
+	Enabled       bool   `json:"enabled"`
+	Timeout       string `json:"timeout,omitempty"`       // 응답 대기 시간 (예: "2h", 기본 1시간)
+	DefaultAction string `json:"defaultAction,omitempty"` // 시간 초과 시 동작: "buy" 또는 "skip" (기본 "skip")
+}
+
+// 승인 대기 기본 시간
+const defaultApprovalTimeout = time.Hour
+
+// TimeoutDuration은 승인 응답 대기 시간을 반환합니다
+func (a Approval) TimeoutDuration() time.Duration {
+	if a.Timeout == "" {
+		return defaultApprovalTimeout
+	}
+
+	d, err := time.ParseDuration(a.Timeout)
+	if err != nil || d <= 0 {
+		log.Printf("⚠️  잘못된 승인 대기 시간, 기본값 사용: %q\n", a.Timeout)
+		return defaultApprovalTimeout
+	}
+	return d
+}
+
+// BuyOnTimeout은 응답이 없을 때 구매를 진행하는지 반환합니다
+func (a Approval) BuyOnTimeout() bool {
+	return a.DefaultAction == "buy"
+}
+
+// RetryPolicy는 구매 실패 시 재시도 정책입니다
+type RetryPolicy struct {
+	Enabled        bool     `json:"enabled"`
+	Intervals      []string `json:"intervals,omitempty"`      // 재시도 간격 (예: ["10m", "30m", "1h"]), 마지막 간격 반복
+	DeadlineMargin string   `json:"deadlineMargin,omitempty"` // 판매 마감(토요일 20:00) 전 재시도 중단 여유 시간 (예: "30m")
+}
+
+// TelegramAPI는 텔레그램 Bot API 연결 설정입니다
+type TelegramAPI struct {
+	BaseURL string `json:"baseUrl,omitempty"` // API 주소 (기본 https://api.telegram.org, 로컬 Bot API 서버 사용 시)
+	Proxy   string `json:"proxy,omitempty"`   // 프록시 URL (http://, https://, socks5://), 비우면 HTTPS_PROXY 환경변수
+	Timeout string `json:"timeout,omitempty"` // 요청 시간 제한 (기본 15s)
+	Silent  bool   `json:"silent,omitempty"`  // 모든 알림을 무음으로 전송
+	Images  bool   `json:"images,omitempty"`  // 구매 번호, 당첨 결과를 공 이미지로도 전송
+}
+
+// TimeoutDuration은 요청 시간 제한을 반환합니다 (0이면 기본값)
+func (t TelegramAPI) TimeoutDuration() time.Duration {
+	d, err := time.ParseDuration(t.Timeout)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// SlackConfig는 슬랙 알림 설정입니다 (공통 수신자로 텔레그램과 함께 받음)
+type SlackConfig struct {
+	WebhookURL string `json:"webhookUrl,omitempty"` // incoming webhook 주소 (https://hooks.slack.com/services/...)
+}
+
+// DiscordConfig는 디스코드 알림 설정입니다 (공통 수신자로 텔레그램과 함께 받음)
+type DiscordConfig struct {
+	WebhookURL string `json:"webhookUrl,omitempty"` // 웹훅 주소 (https://discord.com/api/webhooks/...)
+	Username   string `json:"username,omitempty"`   // 보내는 사람 이름 (비우면 웹훅에 설정한 이름)
+	AvatarURL  string `json:"avatarUrl,omitempty"`  // 보내는 사람 아이콘 이미지 주소
+}
+
+// KakaoConfig는 카카오톡 나에게 보내기 알림 설정입니다 (공통 수신자로 텔레그램과 함께 받음)
+// 리프레시 토큰은 dhlottery kakao login으로 받으며, 갱신되면 상태 디렉토리에 저장한 값을 씁니다
+type KakaoConfig struct {
+	RestAPIKey   string `json:"restApiKey,omitempty"`   // 카카오 앱 REST API 키
+	ClientSecret string `json:"clientSecret,omitempty"` // Client Secret (앱 보안 설정에서 켰을 때만)
+	RefreshToken string `json:"refreshToken,omitempty"` // 공통 수신자의 리프레시 토큰
+	LinkURL      string `json:"linkUrl,omitempty"`      // 메시지를 눌렀을 때 열 주소 (앱 플랫폼에 등록한 도메인, 기본 동행복권)
+	APIURL       string `json:"apiUrl,omitempty"`       // API 서버 주소 (기본 https://kapi.kakao.com, 테스트용)
+	AuthURL      string `json:"authUrl,omitempty"`      // 인증 서버 주소 (기본 https://kauth.kakao.com, 테스트용)
+}
+
+// TelegramCommands는 스케줄러 모드에서 텔레그램으로 받는 명령(/balance, /buy 등) 설정입니다
+type TelegramCommands struct {
+	Enabled bool     `json:"enabled"`
+	Allow   []string `json:"allow,omitempty"` // 명령을 허용할 채팅방 또는 사용자 ID (비우면 telegramChatId와 계정별 채팅방)
+}
+
+// AllowedIDs는 명령을 허용할 채팅방/사용자 ID 목록을 반환합니다
+func (c Config) AllowedIDs() []int64 {
+	values := c.TelegramCommands.Allow
+	if len(values) == 0 {
+		values = []string{c.TelegramChatID}
+		for _, account := range c.Accounts {
+			values = append(values, account.TelegramChatID)
+		}
+	}
+
+	var ids []int64
+	for _, value := range values {
+		if id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
 }
 
 // Config는 전체 설정을 담는 구조체입니다
 type Config struct {
-	Accounts         []Account `json:"accounts"`
-	TelegramBotToken string    `json:"telegramBotToken,omitempty"`
-	TelegramChatID   string    `json:"telegramChatId,omitempty"`
+	Accounts         []Account        `json:"accounts"`
+	TelegramBotToken string           `json:"telegramBotToken,omitempty"`
+	TelegramChatID   string           `json:"telegramChatId,omitempty"`
+	TelegramAPI      TelegramAPI      `json:"telegramApi"`      // 텔레그램 Bot API 연결 설정
+	TelegramCommands TelegramCommands `json:"telegramCommands"` // 텔레그램 명령 (스케줄러 모드)
+	Slack            SlackConfig      `json:"slack"`            // 슬랙 incoming webhook 알림
+	Discord          DiscordConfig    `json:"discord"`          // 디스코드 웹훅 알림
+	Kakao            KakaoConfig      `json:"kakao"`            // 카카오톡 나에게 보내기 알림
+	Notify           Notify           `json:"notify"`           // 알림 수신자와 이벤트별 전달 규칙
+	Retry            RetryPolicy      `json:"retry"`
+	CatchUpWindow    string           `json:"catchUpWindow,omitempty"` // 재시작 시 놓친 작업을 실행할 최대 지연 (예: "48h")
+	Schedules        []Schedule       `json:"schedules,omitempty"`     // 예약 작업 (비우면 기본 일정)
+	Vault            VaultConfig      `json:"vault"`                   // 비밀번호 금고
+	DataDir          string           `json:"dataDir,omitempty"`       // 로그, 구매 내역, 상태 파일 위치 (기본: XDG 데이터 디렉토리)
+	Language         string           `json:"language,omitempty"`      // 알림, 콘솔 메시지 언어 (ko, en, 기본 ko)
 }
 
-// Load는 설정을 로드합니다
-func Load() (Config, error) {
-	// 1. 환경변수에서 로드 시도
-	config, err := LoadFromEnv()
-	if err == nil {
-		return config, nil
+// Schedule은 스케줄러 모드에서 실행할 예약 작업 설정입니다
+type Schedule struct {
+	Name     string   `json:"name,omitempty"`     // 작업 이름 (실행 기록 구분용, 기본값은 job)
+	Job      string   `json:"job"`                // 작업 종류: "buy", "balance", "winning"
+	Cron     string   `json:"cron,omitempty"`     // 크론 표현식 (예: "0 19 * * 1")
+	At       string   `json:"at,omitempty"`       // 달력 표현식 (예: "mon 19:00", "sat 20:00 -2h")
+	Accounts []string `json:"accounts,omitempty"` // 대상 계정 아이디 (비우면 전체)
+	Enabled  *bool    `json:"enabled,omitempty"`  // 사용 여부 (기본 true)
+	Timezone string   `json:"timezone,omitempty"` // 시간대 (기본 Asia/Seoul)
+}
+
+// 예약 작업 종류
+const (
+	JobBuy     = "buy"
+	JobBalance = "balance"
+	JobWinning = "winning"
+)
+
+// jobLabels는 작업 종류별 표시 이름입니다
+var jobLabels = map[string]string{
+	JobBuy:     "로또 구매",
+	JobBalance: "예치금 확인",
+	JobWinning: "당첨 확인",
+}
+
+// defaultSchedules는 schedules 설정이 없을 때 사용하는 기본 일정입니다
+var defaultSchedules = []Schedule{
+	{Job: JobWinning, Cron: "50 12 * * 1"}, // 매주 월요일 오후 12시 50분
+	{Job: JobBalance, Cron: "0 13 * * 1"},  // 매주 월요일 오후 1시
+	{Job: JobBuy, Cron: "0 19 * * 1"},      // 매주 월요일 오후 7시
+}
+
+// JobName은 작업 이름을 반환합니다 (이름이 없으면 작업 종류)
+func (s Schedule) JobName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Job
+}
+
+// Label은 작업 종류의 표시 이름을 반환합니다
+func (s Schedule) Label() string {
+	if label, ok := jobLabels[s.Job]; ok {
+		return label
+	}
+	return s.Job
+}
+
+// IsEnabled는 작업 사용 여부를 반환합니다
+func (s Schedule) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// Spec은 시간대가 반영된 크론 표현식을 반환합니다
+func (s Schedule) Spec() (string, error) {
+	return scheduler.BuildSpec(s.Cron, s.At, s.Timezone)
+}
+
+// ScheduleList는 설정된 예약 작업 목록을 반환합니다 (설정이 없으면 기본 일정)
+func (c *Config) ScheduleList() []Schedule {
+	if len(c.Schedules) == 0 {
+		return defaultSchedules
+	}
+	return c.Schedules
+}
+
+// WithAccounts는 지정한 아이디의 계정만 남긴 설정을 반환합니다 (목록이 비어 있으면 그대로)
+func (c Config) WithAccounts(userIDs []string) Config {
+	if len(userIDs) == 0 {
+		return c
+	}
+
+	wanted := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+
+	filtered := c
+	filtered.Accounts = nil
+	for _, account := range c.Accounts {
+		if wanted[account.UserID] {
+			filtered.Accounts = append(filtered.Accounts, account)
+		}
+	}
+	return filtered
+}
+
+// HasTag는 계정에 태그가 붙어 있는지 확인합니다
+func (a Account) HasTag(tag string) bool {
+	for _, t := range a.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Select는 아이디나 태그 중 하나라도 일치하는 계정만 남긴 설정을 반환합니다 (둘 다 비어 있으면 그대로)
+// 등록되지 않은 아이디나 어느 계정에도 없는 태그는 오류입니다
+func (c Config) Select(userIDs, tags []string) (Config, error) {
+	if len(userIDs) == 0 && len(tags) == 0 {
+		return c, nil
+	}
+
+	wanted := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+	found := make(map[string]bool)
+
+	selected := c
+	selected.Accounts = nil
+	for _, account := range c.Accounts {
+		match := wanted[account.UserID]
+		if match {
+			found[account.UserID] = true
+		}
+		for _, tag := range tags {
+			if account.HasTag(tag) {
+				found["#"+tag] = true
+				match = true
+			}
+		}
+		if match {
+			selected.Accounts = append(selected.Accounts, account)
+		}
+	}
+
+	for _, id := range userIDs {
+		if !found[id] {
+			return Config{}, fmt.Errorf("등록되지 않은 계정: %s", id)
+		}
+	}
+	for _, tag := range tags {
+		if !found["#"+tag] {
+			return Config{}, fmt.Errorf("태그 %q가 붙은 계정이 없습니다", tag)
+		}
+	}
+	return selected, nil
+}
+
+// validateSchedules는 예약 작업 설정을 검증합니다
+func (c *Config) validateSchedules() issueList {
+	var issues issueList
+
+	known := make(map[string]bool, len(c.Accounts))
+	for _, account := range c.Accounts {
+		known[account.UserID] = true
 	}
 
-	log.Printf("환경변수 로드 실패: %v\n", err)
+	names := make(map[string]bool)
+	for i, sched := range c.Schedules {
+		path := fmt.Sprintf("schedules[%d]", i)
+		if _, ok := jobLabels[sched.Job]; !ok {
+			issues.add(path+".job", "알 수 없는 작업 %q (buy, balance, winning 중 하나)", sched.Job)
+			continue
+		}
+		if _, err := sched.Spec(); err != nil {
+			issues.add(path, "%v", err)
+		}
+		if names[sched.JobName()] {
+			issues.add(path+".name", "작업 이름 %q이 중복됩니다 (같은 작업을 여러 번 등록하려면 name을 지정하세요)", sched.JobName())
+		}
+		names[sched.JobName()] = true
+
+		for j, id := range sched.Accounts {
+			if !known[id] {
+				issues.add(fmt.Sprintf("%s.accounts[%d]", path, j), "등록되지 않은 계정 %q", id)
+			}
+		}
+	}
+
+	return issues
+}
+
+// 재시도 정책 기본값
+var (
+	defaultRetryIntervals      = []time.Duration{10 * time.Minute, 30 * time.Minute, time.Hour, 3 * time.Hour, 6 * time.Hour}
+	defaultRetryDeadlineMargin = 30 * time.Minute
+)
+
+// 놓친 작업 실행 기본 허용 지연
+const defaultCatchUpWindow = 48 * time.Hour
+
+// CatchUpWindowDuration은 놓친 작업을 실행할 최대 지연 시간을 반환합니다 ("0"이면 실행 안 함)
+func (c *Config) CatchUpWindowDuration() time.Duration {
+	if c.CatchUpWindow == "" {
+		return defaultCatchUpWindow
+	}
 
-	// 2. 설정 파일에서 로드 시도
-	config, err = LoadFromFile("config.json")
-	if err == nil {
-		return config, nil
+	d, err := time.ParseDuration(c.CatchUpWindow)
+	if err != nil || d < 0 {
+		log.Printf("⚠️  잘못된 catchUpWindow, 기본값 사용: %q\n", c.CatchUpWindow)
+		return defaultCatchUpWindow
 	}
+	return d
+}
 
-	log.Printf("설정 파일 로드 실패: %v\n", err)
+// RetryIntervals는 재시도 간격 목록을 반환합니다 (설정이 없거나 잘못되면 기본값)
+func (r RetryPolicy) RetryIntervals() []time.Duration {
+	intervals := make([]time.Duration, 0, len(r.Intervals))
+	for _, s := range r.Intervals {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			log.Printf("⚠️  잘못된 재시도 간격 무시: %q\n", s)
+			continue
+		}
+		intervals = append(intervals, d)
+	}
 
-	// 3. 대화형 입력
+	if len(intervals) == 0 {
+		return defaultRetryIntervals
+	}
+	return intervals
+}
+
+// RetryDeadlineMargin은 판매 마감 전 재시도 중단 여유 시간을 반환합니다
+func (r RetryPolicy) RetryDeadlineMargin() time.Duration {
+	if r.DeadlineMargin == "" {
+		return defaultRetryDeadlineMargin
+	}
+
+	d, err := time.ParseDuration(r.DeadlineMargin)
+	if err != nil || d < 0 {
+		log.Printf("⚠️  잘못된 재시도 마감 여유 시간, 기본값 사용: %q\n", r.DeadlineMargin)
+		return defaultRetryDeadlineMargin
+	}
+	return d
+}
+
+// DefaultFiles는 --config를 지정하지 않았을 때 찾는 설정 파일입니다 (앞에서부터)
+var DefaultFiles = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// FindFile은 사용할 설정 파일 경로를 반환합니다 (지정한 경로가 없으면 기본 파일 중 존재하는 것, 없으면 "")
+func FindFile(path string) string {
+	if path != "" {
+		return path
+	}
+	for _, name := range DefaultFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// Load는 설정을 로드합니다
+// 설정 파일(path 또는 기본 파일)을 읽은 뒤 환경변수로 덮어쓰며,
+// 설정 파일과 환경변수 모두에 계정이 없으면 대화형으로 입력받습니다
+func Load(path string) (Config, error) {
+	if file := FindFile(path); file != "" {
+		return LoadFromFile(file)
+	}
+
+	log.Printf("설정 파일이 없습니다 (%s)\n", strings.Join(DefaultFiles, ", "))
+	if hasEnvAccounts() {
+		return LoadFromEnv()
+	}
+
+	// 대화형 입력 (저장되지 않음)
+	log.Println("💡 설정 파일은 dhlottery init 명령으로 만들 수 있습니다")
 	log.Println("설정 정보를 입력해주세요:")
 	return LoadInteractive()
 }
 
-// LoadFromEnv는 환경변수에서 설정을 로드합니다 (단일 계정)
+// LoadFromEnv는 환경변수만으로 설정을 로드합니다
+//
+//	DH_LOTTERY_1_ID, DH_LOTTERY_1_PW, DH_LOTTERY_1_CHAT_ID, DH_LOTTERY_1_GAMES, DH_LOTTERY_1_ALIAS, DH_LOTTERY_2_ID, ...
+//	DH_LOTTERY_ID, DH_LOTTERY_PW (단일 계정, 이전 형식)
+//	TELEGRAM_BOT_TOKEN, TELEGRAM_CHAT_ID
 func LoadFromEnv() (Config, error) {
-	userID := os.Getenv("DH_LOTTERY_ID")
-	password := os.Getenv("DH_LOTTERY_PW")
-	telegramToken := os.Getenv("TELEGRAM_BOT_TOKEN")
-	telegramChatID := os.Getenv("TELEGRAM_CHAT_ID")
+	if !hasEnvAccounts() {
+		return Config{}, fmt.Errorf("환경변수가 설정되지 않았습니다 (DH_LOTTERY_1_ID, DH_LOTTERY_1_PW 또는 DH_LOTTERY_ID, DH_LOTTERY_PW)")
+	}
 
-	if userID == "" || password == "" {
-		return Config{}, fmt.Errorf("환경변수가 설정되지 않았습니다 (DH_LOTTERY_ID, DH_LOTTERY_PW)")
+	var config Config
+	added, issues := applyEnv(&config)
+	if len(issues) == 0 {
+		issues = config.validate()
+		markEnvIssues(issues, added)
+	}
+	if len(issues) > 0 {
+		return Config{}, &ValidationError{Issues: issues}
 	}
 
-	return Config{
-		Accounts: []Account{
-			{
-				UserID:   userID,
-				Password: password,
-			},
-		},
-		TelegramBotToken: telegramToken,
-		TelegramChatID:   telegramChatID,
-	}, nil
+	config.bind()
+	return config, nil
 }
 
-// LoadFromFile은 파일에서 설정을 로드합니다
+// LoadFromFile은 파일에서 설정을 로드하고 환경변수로 덮어씁니다 (확장자로 JSON/YAML/TOML 형식 결정)
+// 문자열 값의 ${ENV} 참조를 치환한 뒤, 항목 이름과 타입, 값의 의미를 검증합니다
 func LoadFromFile(filename string) (Config, error) {
-	data, err := os.ReadFile(filename)
+	config, root, err := decodeFile(filename, true)
 	if err != nil {
-		return Config{}, fmt.Errorf("설정 파일 읽기 실패: %w", err)
+		return Config{}, err
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return Config{}, fmt.Errorf("설정 파일 파싱 실패: %w", err)
+	// 환경변수로 계정별 비밀번호 등을 덮어쓰기 (아이디 기준)
+	added, issues := applyEnv(&config)
+	if len(issues) == 0 {
+		issues = config.validate()
+		for i := range issues {
+			issues[i].Line, issues[i].Col = root.locate(issues[i].Path)
+		}
+		markEnvIssues(issues, added)
 	}
+	if len(issues) > 0 {
+		return Config{}, &ValidationError{File: filename, Issues: issues}
+	}
+
+	config.bind()
+	return config, nil
+}
 
-	if len(config.Accounts) == 0 {
-		return Config{}, fmt.Errorf("설정 파일에 계정 정보가 없습니다")
+// ReadFile은 설정 파일에 적힌 내용 그대로 읽습니다 (파일을 고쳐 쓸 때 사용)
+// ${ENV} 참조를 치환하지 않고, 환경변수 덮어쓰기와 의미 검증도 하지 않습니다
+func ReadFile(filename string) (Config, error) {
+	config, _, err := decodeFile(filename, false)
+	if err != nil {
+		return Config{}, err
+	}
+	config.bind()
+	return config, nil
+}
+
+// decodeFile은 설정 파일을 파싱하고 항목 이름과 타입을 검사하여 구조체로 변환합니다 (expand: ${ENV} 치환 여부)
+func decodeFile(filename string, expand bool) (Config, *node, error) {
+	format, err := FormatOf(filename)
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return Config{}, nil, fmt.Errorf("설정 파일 읽기 실패: %w", err)
 	}
 
-	// 각 계정의 필수 정보 검증
-	for i, account := range config.Accounts {
-		if account.UserID == "" || account.Password == "" {
-			return Config{}, fmt.Errorf("계정 %d: 아이디와 비밀번호가 필요합니다", i+1)
+	root, err := parseDocument(format, data)
+	if err != nil {
+		var located *locatedError
+		if errors.As(err, &located) {
+			return Config{}, nil, fmt.Errorf("설정 파일 파싱 실패: %s:%w", filename, err)
 		}
+		return Config{}, nil, fmt.Errorf("설정 파일 파싱 실패: %s: %w", filename, err)
 	}
 
-	return config, nil
+	var issues issueList
+	if expand {
+		interpolate(root, "", &issues)
+	}
+	checkSchema(root, reflect.TypeOf(Config{}), "", &issues)
+	if len(issues) > 0 {
+		return Config{}, nil, &ValidationError{File: filename, Issues: issues}
+	}
+
+	// 검증된 문서를 JSON으로 다시 인코딩하여 구조체로 변환 (모든 형식이 json 태그 사용)
+	normalized, err := json.Marshal(root.plain())
+	if err != nil {
+		return Config{}, nil, fmt.Errorf("설정 변환 실패: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(normalized, &config); err != nil {
+		return Config{}, nil, fmt.Errorf("설정 파일 파싱 실패: %w", err)
+	}
+
+	return config, root, nil
 }
 
 // LoadInteractive는 사용자 입력으로 설정을 로드합니다 (단일 계정)
@@ -102,9 +591,10 @@ func LoadInteractive() (Config, error) {
 	userID, _ := reader.ReadString('\n')
 	userID = strings.TrimSpace(userID)
 
-	fmt.Print("비밀번호: ")
-	password, _ := reader.ReadString('\n')
-	password = strings.TrimSpace(password)
+	password, err := ReadSecret("비밀번호: ")
+	if err != nil {
+		return Config{}, err
+	}
 
 	if userID == "" || password == "" {
 		return Config{}, fmt.Errorf("아이디와 비밀번호를 모두 입력해주세요")
@@ -120,14 +610,34 @@ func LoadInteractive() (Config, error) {
 	}, nil
 }
 
-// Print는 설정 정보를 출력합니다 (보안상 비밀번호는 마스킹)
+// Print는 설정 정보를 출력합니다 (비밀번호는 출처만 표시)
 func (c *Config) Print() {
 	log.Println("=== 설정 정보 ===")
 	log.Printf("등록된 계정 수: %d\n", len(c.Accounts))
 
 	for i, account := range c.Accounts {
-		maskedPw := strings.Repeat("*", len(account.Password))
-		log.Printf("  [계정 %d] %s / %s\n", i+1, account.UserID, maskedPw)
+		log.Printf("  [계정 %d] %s (비밀번호: %s)\n", i+1, account.UserID, account.PasswordSource())
+		if account.Alias != "" {
+			log.Printf("           별칭: %s\n", account.Alias)
+		}
+		if len(account.Tags) > 0 {
+			log.Printf("           태그: %s\n", strings.Join(account.Tags, ", "))
+		}
+		if len(account.Notify) > 0 {
+			log.Printf("           알림 수신자: %s\n", strings.Join(account.Notify, ", "))
+		} else if account.TelegramChatID != "" {
+			log.Printf("           알림 채팅방: %s\n", account.TelegramChatID)
+		}
+		if account.Approval.Enabled {
+			defaultAction := "건너뛰기"
+			if account.Approval.BuyOnTimeout() {
+				defaultAction = "구매"
+			}
+			log.Printf("           구매 승인 모드 (대기 %v, 응답 없으면 %s)\n", account.Approval.TimeoutDuration(), defaultAction)
+		}
+		if account.Policy != nil {
+			log.Printf("           구매 정책: 규칙 %d개 (기본 %d게임)\n", len(account.Policy.Rules), account.Policy.Default())
+		}
 	}
 
 	if c.TelegramBotToken != "" && c.TelegramChatID != "" {
@@ -135,4 +645,55 @@ func (c *Config) Print() {
 	} else {
 		log.Println("  텔레그램 알림: 비활성화")
 	}
+	if c.Slack.WebhookURL != "" {
+		log.Printf("  슬랙 알림: 활성화 (%s)\n", webhook.Redact(c.Slack.WebhookURL))
+	}
+	if c.Discord.WebhookURL != "" {
+		log.Printf("  디스코드 알림: 활성화 (%s)\n", webhook.Redact(c.Discord.WebhookURL))
+	}
+	if tokens := c.KakaoTokens(); len(tokens) > 0 {
+		log.Printf("  카카오톡 알림: 활성화 (%d명)\n", len(tokens))
+	}
+
+	switch {
+	case c.Notify.Digest.Enabled:
+		log.Println("  알림 요약: 작업별 요약 + 회차 요약 (실패, 1~3등 당첨은 바로 알림)")
+	case c.Notify.Digest.Weekly:
+		log.Println("  알림 요약: 회차 요약")
+	}
+	if len(c.Notify.Routes) > 0 {
+		log.Printf("  알림 규칙: %d개 (수신자 %d명)\n", len(c.Notify.Routes), len(c.Notify.Recipients))
+	}
+	if q := c.Notify.QuietHours; q != nil {
+		log.Printf("  조용한 시간: %s ~ %s (%s)\n", q.Start, q.End, valueOr(q.Timezone, defaultQuietTimezone))
+	}
+	if c.Notify.Dedupe != "" || len(c.Notify.Limits) > 0 {
+		log.Printf("  알림 중복 제거: %s, 전송 제한: %d개\n", valueOr(c.Notify.Dedupe, "안 함"), len(c.Notify.Limits))
+	}
+	if c.Notify.QuietHours != nil || c.Notify.Dedupe != "" || len(c.Notify.Limits) > 0 {
+		log.Printf("  항상 바로 보내는 알림: %s 이상\n", valueOr(c.Notify.Override, "critical"))
+	}
+
+	if c.TelegramAPI.BaseURL != "" || c.TelegramAPI.Proxy != "" {
+		log.Printf("  텔레그램 API: %s (프록시: %s)\n", valueOr(c.TelegramAPI.BaseURL, "기본"), valueOr(redactURL(c.TelegramAPI.Proxy), "없음"))
+	}
+	if c.TelegramAPI.Silent {
+		log.Println("  텔레그램 알림: 무음 전송")
+	}
+	if c.TelegramAPI.Images {
+		log.Println("  텔레그램 알림: 번호 이미지 함께 전송")
+	}
+	if c.Language != "" {
+		log.Printf("  메시지 언어: %s\n", c.Language)
+	}
+
+	if c.TelegramCommands.Enabled {
+		log.Printf("  텔레그램 명령: 활성화 (허용 ID %d개)\n", len(c.AllowedIDs()))
+	}
+
+	if c.Retry.Enabled {
+		log.Printf("  구매 재시도: 활성화 (마감 %v 전까지)\n", c.Retry.RetryDeadlineMargin())
+	} else {
+		log.Println("  구매 재시도: 비활성화")
+	}
 }
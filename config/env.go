@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 계정별 환경변수: DH_LOTTERY_<번호>_ID, _PW, _CHAT_ID, _GAMES, _ALIAS, _KAKAO_TOKEN
+var accountEnvPattern = regexp.MustCompile(`^DH_LOTTERY_(\d+)_(ID|PW|CHAT_ID|GAMES|ALIAS|KAKAO_TOKEN)$`)
+
+// envAccount는 환경변수로 지정한 계정 정보입니다
+type envAccount struct {
+	prefix string // 예: DH_LOTTERY_1 (오류 메시지용)
+	userID string
+	values map[string]string
+}
+
+// lookupEnvAccounts는 환경변수에서 계정 정보를 모읍니다 (번호 순서)
+// 이전 형식의 DH_LOTTERY_ID / DH_LOTTERY_PW는 가장 앞의 계정으로 취급합니다
+func lookupEnvAccounts() []envAccount {
+	byIndex := make(map[int]*envAccount)
+
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		m := accountEnvPattern.FindStringSubmatch(name)
+		if m == nil || value == "" {
+			continue
+		}
+		index, _ := strconv.Atoi(m[1])
+		acc, ok := byIndex[index]
+		if !ok {
+			acc = &envAccount{prefix: "DH_LOTTERY_" + m[1], values: make(map[string]string)}
+			byIndex[index] = acc
+		}
+		acc.values[m[2]] = value
+	}
+
+	indexes := make([]int, 0, len(byIndex))
+	for index := range byIndex {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	var accounts []envAccount
+	if id, pw := os.Getenv("DH_LOTTERY_ID"), os.Getenv("DH_LOTTERY_PW"); id != "" || pw != "" {
+		accounts = append(accounts, envAccount{prefix: "DH_LOTTERY", values: map[string]string{"ID": id, "PW": pw}})
+	}
+	for _, index := range indexes {
+		accounts = append(accounts, *byIndex[index])
+	}
+
+	for i := range accounts {
+		accounts[i].userID = accounts[i].values["ID"]
+	}
+	return accounts
+}
+
+// hasEnvAccounts는 환경변수로 지정한 계정이 있는지 확인합니다
+func hasEnvAccounts() bool {
+	return len(lookupEnvAccounts()) > 0
+}
+
+// applyEnv는 환경변수로 설정을 덮어씁니다
+// 계정은 아이디로 찾아 값이 있는 항목만 덮어쓰고, 설정 파일에 없는 계정은 뒤에 추가합니다
+// 반환값은 환경변수로 추가된 계정의 인덱스별 환경변수 접두사입니다
+func applyEnv(c *Config) (map[int]string, issueList) {
+	var issues issueList
+	added := make(map[int]string)
+
+	if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+		c.TelegramBotToken = token
+	}
+	if chatID := os.Getenv("TELEGRAM_CHAT_ID"); chatID != "" {
+		c.TelegramChatID = chatID
+	}
+	if apiURL := os.Getenv("TELEGRAM_API_URL"); apiURL != "" {
+		c.TelegramAPI.BaseURL = apiURL
+	}
+	if proxy := os.Getenv("TELEGRAM_PROXY"); proxy != "" {
+		c.TelegramAPI.Proxy = proxy
+	}
+	if url := os.Getenv("SLACK_WEBHOOK_URL"); url != "" {
+		c.Slack.WebhookURL = url
+	}
+	if url := os.Getenv("DISCORD_WEBHOOK_URL"); url != "" {
+		c.Discord.WebhookURL = url
+	}
+	if key := os.Getenv("KAKAO_REST_API_KEY"); key != "" {
+		c.Kakao.RestAPIKey = key
+	}
+	if secret := os.Getenv("KAKAO_CLIENT_SECRET"); secret != "" {
+		c.Kakao.ClientSecret = secret
+	}
+	if token := os.Getenv("KAKAO_REFRESH_TOKEN"); token != "" {
+		c.Kakao.RefreshToken = token
+	}
+
+	for _, env := range lookupEnvAccounts() {
+		if env.userID == "" {
+			issues.add(env.prefix+"_ID", "아이디 환경변수가 필요합니다 (계정은 아이디로 구분됩니다)")
+			continue
+		}
+
+		index := -1
+		for i, account := range c.Accounts {
+			if account.UserID == env.userID {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			c.Accounts = append(c.Accounts, Account{UserID: env.userID})
+			index = len(c.Accounts) - 1
+			added[index] = env.prefix
+		}
+
+		account := &c.Accounts[index]
+		if pw := env.values["PW"]; pw != "" {
+			// 환경변수가 우선이므로 설정 파일의 passwordFile, passwordCommand는 쓰지 않음
+			account.Password = pw
+			account.PasswordFile = ""
+			account.PasswordCommand = ""
+		}
+		if chatID := env.values["CHAT_ID"]; chatID != "" {
+			account.TelegramChatID = chatID
+		}
+		if alias := env.values["ALIAS"]; alias != "" {
+			account.Alias = alias
+		}
+		if token := env.values["KAKAO_TOKEN"]; token != "" {
+			account.KakaoRefreshToken = token
+		}
+		if value := env.values["GAMES"]; value != "" {
+			games, err := strconv.Atoi(value)
+			if err != nil {
+				issues.add(env.prefix+"_GAMES", "정수가 필요합니다 (%q)", value)
+				continue
+			}
+			if account.Policy == nil {
+				account.Policy = &PurchasePolicy{}
+			}
+			account.Policy.DefaultGames = &games
+		}
+	}
+
+	return added, issues
+}
+
+// markEnvIssues는 환경변수로 추가된 계정의 오류에 환경변수 이름을 표시합니다 (파일 위치 없음)
+func markEnvIssues(issues issueList, added map[int]string) {
+	for i := range issues {
+		for index, prefix := range added {
+			if strings.HasPrefix(issues[i].Path, fmt.Sprintf("accounts[%d]", index)) {
+				issues[i].Line, issues[i].Col = 0, 0
+				issues[i].Message += fmt.Sprintf(" (환경변수 %s_*)", prefix)
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvPasswordOverridesPasswordSources(t *testing.T) {
+	dir := t.TempDir()
+	pwFile := filepath.Join(dir, "password.txt")
+	if err := os.WriteFile(pwFile, []byte("file-password\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		field string
+	}{
+		{"passwordFile", `"passwordFile": "` + filepath.ToSlash(pwFile) + `"`},
+		{"passwordCommand", `"passwordCommand": "echo command-password"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".json")
+			data := `{"accounts": [{"userId": "user1", ` + tt.field + `}]}`
+			if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("DH_LOTTERY_1_ID", "user1")
+			t.Setenv("DH_LOTTERY_1_PW", "env-password")
+
+			cfg, err := LoadFromFile(path)
+			if err != nil {
+				t.Fatalf("환경변수 비밀번호가 있으면 로드되어야 함: %v", err)
+			}
+			account := cfg.Accounts[0]
+			if account.Password != "env-password" || account.PasswordFile != "" || account.PasswordCommand != "" {
+				t.Errorf("환경변수 비밀번호가 우선해야 함: %+v", account)
+			}
+		})
+	}
+}
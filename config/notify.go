@@ -0,0 +1,476 @@
+package config
+
+import (
+	"dhlottery/message"
+	"dhlottery/throttle"
+	"dhlottery/webhook"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Notify는 이름을 붙인 알림 수신자와 이벤트, 중요도별 전달 규칙입니다
+//
+//	notify:
+//	  recipients:
+//	    - {name: admin, telegramChatId: "111"}
+//	    - {name: family, telegramChatId: "-100222", discordWebhook: "https://discord.com/api/webhooks/..."}
+//	    - {name: mom, kakaoRefreshToken: "..."}   # 카카오톡 나에게 보내기
+//	  routes:
+//	    - {events: [winning], minSeverity: critical, to: [family]}   # 1~3등 당첨은 가족방에도
+//	    - {events: [failure.login], to: [admin], only: true}         # 로그인 실패는 관리자에게만
+//	  digest:
+//	    enabled: true   # 계정별 알림 대신 작업마다 요약 하나 (실패, 1~3등 당첨은 바로 알림)
+//	  quietHours: {start: "23:00", end: "07:00"}   # 밤에는 알림을 미뤘다가 아침에 전송
+//	  dedupe: 12h                                  # 같은 알림은 12시간에 한 번만
+//	  limits:
+//	    - {events: [balance], max: 1, per: 24h}    # 예치금 알림은 하루 한 번만
+type Notify struct {
+	Recipients []Recipient `json:"recipients,omitempty"`
+	Routes     []Route     `json:"routes,omitempty"`
+	Digest     Digest      `json:"digest"`
+
+	QuietHours *QuietHours `json:"quietHours,omitempty"` // 조용한 시간 (알림을 미뤘다가 끝나는 시각에 전송)
+	Dedupe     string      `json:"dedupe,omitempty"`     // 같은 채팅방에 같은 알림은 이 시간 안에 한 번만 (예: 12h)
+	Limits     []Limit     `json:"limits,omitempty"`     // 이벤트별 전송 횟수 제한
+	Override   string      `json:"override,omitempty"`   // 이 중요도 이상은 위 정책과 관계없이 바로 전송 (기본 critical)
+}
+
+// QuietHours는 알림을 미루는 시간대입니다 (start가 end보다 늦으면 자정을 넘김)
+type QuietHours struct {
+	Start    string `json:"start"`              // 시작 시각 (예: "23:00")
+	End      string `json:"end"`                // 끝 시각 (예: "07:00")
+	Timezone string `json:"timezone,omitempty"` // 시간대 (기본 Asia/Seoul)
+}
+
+// Limit은 채팅방별 이벤트 전송 횟수 제한입니다
+type Limit struct {
+	Events []string `json:"events,omitempty"` // 이벤트 (예: "balance", "failure.login", 비우면 전체)
+	Max    int      `json:"max"`              // per 동안 보낼 수 있는 횟수
+	Per    string   `json:"per"`              // 기간 (예: 24h)
+}
+
+// defaultQuietTimezone은 조용한 시간의 기본 시간대입니다
+const defaultQuietTimezone = "Asia/Seoul"
+
+// Digest는 여러 계정의 알림을 요약 메시지로 모으는 설정입니다
+type Digest struct {
+	// 작업(구매, 예치금 확인)마다 계정별 결과를 요약 하나로 보내고, 당첨 확인 후 회차 요약을 보냄
+	// error 이상 알림(작업 실패, 구매 실패, 예치금 부족으로 구매 불가)과 1~3등 당첨은 그대로 바로 보냄
+	Enabled bool `json:"enabled,omitempty"`
+	// 계정별 알림은 그대로 두고 당첨 확인 후 회차 요약만 추가
+	Weekly bool `json:"weekly,omitempty"`
+}
+
+// WeeklySummary는 당첨 확인 후 회차 요약을 보내는지 확인합니다
+func (d Digest) WeeklySummary() bool {
+	return d.Enabled || d.Weekly
+}
+
+// Recipient는 이름을 붙인 알림 수신자입니다 (채널을 여러 개 지정하면 모두로 보냄)
+type Recipient struct {
+	Name              string `json:"name"`
+	TelegramChatID    string `json:"telegramChatId,omitempty"`
+	SlackWebhook      string `json:"slackWebhook,omitempty"`      // 슬랙 incoming webhook 주소
+	DiscordWebhook    string `json:"discordWebhook,omitempty"`    // 디스코드 웹훅 주소
+	KakaoRefreshToken string `json:"kakaoRefreshToken,omitempty"` // 카카오톡 나에게 보내기 리프레시 토큰
+}
+
+// destinations는 수신자의 채널별 받는 곳입니다
+func (r Recipient) destinations() []Destination {
+	var out []Destination
+	for _, d := range []Destination{
+		{Channel: ChannelTelegram, Target: r.TelegramChatID},
+		{Channel: ChannelSlack, Target: r.SlackWebhook},
+		{Channel: ChannelDiscord, Target: r.DiscordWebhook},
+		{Channel: ChannelKakao, Target: r.KakaoRefreshToken},
+	} {
+		if d.Target != "" {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// 알림 채널
+const (
+	ChannelTelegram = "telegram"
+	ChannelSlack    = "slack"
+	ChannelDiscord  = "discord"
+	ChannelKakao    = "kakao"
+)
+
+// Destination은 알림을 받는 곳 하나입니다
+type Destination struct {
+	Channel string // telegram, slack, discord, kakao
+	Target  string // 텔레그램 채팅방 ID, 웹훅 주소 또는 카카오 리프레시 토큰
+}
+
+// Route는 이벤트와 중요도가 맞는 알림을 지정한 수신자에게도 보내는 규칙입니다
+type Route struct {
+	Events      []string `json:"events,omitempty"`      // 이벤트 (예: "winning", "failure.login", 비우면 전체)
+	MinSeverity string   `json:"minSeverity,omitempty"` // 최소 중요도: info, warning, error, critical (비우면 전체)
+	Accounts    []string `json:"accounts,omitempty"`    // 대상 계정 아이디 또는 별칭 (비우면 전체)
+	To          []string `json:"to"`                    // 수신자 이름 ("default"는 공통 수신자)
+	Only        bool     `json:"only,omitempty"`        // 계정의 기본 수신자에게는 보내지 않음
+}
+
+// DefaultRecipient는 설정의 공통 수신자(telegramChatId, slack.webhookUrl, discord.webhookUrl, kakao.refreshToken)를 가리키는 수신자 이름입니다
+const DefaultRecipient = "default"
+
+// routing은 계정이 참조하는 알림 설정입니다 (로드 시 연결)
+type routing struct {
+	notify   *Notify
+	defaults []Destination // 공통 수신자
+	policy   throttle.Policy
+}
+
+// Digest는 계정 알림을 요약 메시지로 모으는지 확인합니다 (notify.digest.enabled)
+func (a Account) Digest() bool {
+	return a.routing != nil && a.routing.notify.Digest.Enabled
+}
+
+// bindNotify는 각 계정이 알림 수신자와 규칙을 참조하도록 연결합니다
+func (c *Config) bindNotify() {
+	r := &routing{notify: &c.Notify, defaults: c.DefaultDestinations(), policy: c.Notify.policy()}
+	for i := range c.Accounts {
+		c.Accounts[i].routing = r
+	}
+}
+
+// DisplayName은 메시지에 표시할 계정 이름입니다 (별칭이 없으면 아이디)
+func (a Account) DisplayName() string {
+	if a.Alias != "" {
+		return a.Alias
+	}
+	return a.UserID
+}
+
+// DefaultDestinations는 공통 수신자입니다 (telegramChatId, slack.webhookUrl, discord.webhookUrl, kakao.refreshToken 중 설정한 것)
+func (c *Config) DefaultDestinations() []Destination {
+	return Recipient{
+		TelegramChatID:    c.TelegramChatID,
+		SlackWebhook:      c.Slack.WebhookURL,
+		DiscordWebhook:    c.Discord.WebhookURL,
+		KakaoRefreshToken: c.Kakao.RefreshToken,
+	}.destinations()
+}
+
+// HasWebhooks는 슬랙이나 디스코드 웹훅을 하나라도 설정했는지 확인합니다 (공통 수신자 또는 notify.recipients)
+func (c *Config) HasWebhooks() bool {
+	if c.Slack.WebhookURL != "" || c.Discord.WebhookURL != "" {
+		return true
+	}
+	return slices.ContainsFunc(c.Notify.Recipients, func(r Recipient) bool {
+		return r.SlackWebhook != "" || r.DiscordWebhook != ""
+	})
+}
+
+// KakaoTokens는 설정한 카카오 리프레시 토큰입니다 (공통 수신자, 계정, notify.recipients, 중복 없이)
+func (c *Config) KakaoTokens() []string {
+	var tokens []string
+	add := func(token string) {
+		if token != "" && !slices.Contains(tokens, token) {
+			tokens = append(tokens, token)
+		}
+	}
+	add(c.Kakao.RefreshToken)
+	for _, account := range c.Accounts {
+		add(account.KakaoRefreshToken)
+	}
+	for _, recipient := range c.Notify.Recipients {
+		add(recipient.KakaoRefreshToken)
+	}
+	return tokens
+}
+
+// ChatID는 계정의 기본 텔레그램 채팅방입니다 (구매 승인처럼 한 곳에서 답을 받아야 하는 알림에 사용, 비우면 공통 채팅방)
+func (a Account) ChatID() string {
+	for _, d := range a.defaultDestinations() {
+		if d.Channel == ChannelTelegram {
+			return d.Target
+		}
+	}
+	return ""
+}
+
+// defaultDestinations는 라우팅 규칙을 적용하기 전의 계정 알림 수신 목록입니다
+// notify에 지정한 수신자, 공통 수신자 순으로 사용하며, telegramChatId와 kakaoRefreshToken은
+// 공통 수신자의 텔레그램 채팅방과 카카오톡을 대신합니다
+func (a Account) defaultDestinations() []Destination {
+	if a.routing == nil {
+		return []Destination{{Channel: ChannelTelegram, Target: a.TelegramChatID}}
+	}
+	if len(a.Notify) > 0 {
+		return a.routing.resolve(a.Notify)
+	}
+
+	dests := slices.DeleteFunc(slices.Clone(a.routing.defaults), func(d Destination) bool {
+		return (a.TelegramChatID != "" && d.Channel == ChannelTelegram) || (a.KakaoRefreshToken != "" && d.Channel == ChannelKakao)
+	})
+	if a.TelegramChatID != "" {
+		dests = append([]Destination{{Channel: ChannelTelegram, Target: a.TelegramChatID}}, dests...)
+	}
+	if a.KakaoRefreshToken != "" {
+		dests = append(dests, Destination{Channel: ChannelKakao, Target: a.KakaoRefreshToken})
+	}
+	return dests
+}
+
+// NotifyPolicy는 계정 알림에 적용할 전송 정책입니다 (조용한 시간, 중복 제거, 전송 제한)
+func (a Account) NotifyPolicy() throttle.Policy {
+	if a.routing == nil {
+		return throttle.Policy{}
+	}
+	return a.routing.policy
+}
+
+// NotifyPolicy는 특정 계정이 아닌 알림에 적용할 전송 정책입니다
+func (c *Config) NotifyPolicy() throttle.Policy {
+	return c.Notify.policy()
+}
+
+// policy는 알림 전송 정책을 만듭니다 (잘못된 값은 설정 검증에서 걸러지므로 무시)
+func (n *Notify) policy() throttle.Policy {
+	p := throttle.Policy{Override: message.SeverityCritical}
+	if n.Override != "" {
+		if severity, err := message.ParseSeverity(n.Override); err == nil {
+			p.Override = severity
+		}
+	}
+
+	if q := n.QuietHours; q != nil {
+		start, errStart := parseClock(q.Start)
+		end, errEnd := parseClock(q.End)
+		location, errLoc := time.LoadLocation(valueOr(q.Timezone, defaultQuietTimezone))
+		if errStart == nil && errEnd == nil && errLoc == nil {
+			p.QuietStart, p.QuietEnd, p.Location = start, end, location
+		}
+	}
+
+	p.Dedupe, _ = time.ParseDuration(n.Dedupe)
+	for _, limit := range n.Limits {
+		per, err := time.ParseDuration(limit.Per)
+		if err != nil || per <= 0 || limit.Max <= 0 {
+			continue
+		}
+		p.Limits = append(p.Limits, throttle.Limit{Events: limit.Events, Max: limit.Max, Per: per})
+	}
+	return p
+}
+
+// parseClock은 "HH:MM" 시각을 자정부터의 시간으로 바꿉니다
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("시각 형식 오류 %q (예: 23:00)", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Recipients는 계정 알림을 받을 곳 목록입니다 (중복 없이)
+func (a Account) Recipients(event string, severity message.Severity) []Destination {
+	dests := a.defaultDestinations()
+	if a.routing == nil {
+		return dests
+	}
+	return a.routing.route(dests, &a, event, severity)
+}
+
+// Recipients는 특정 계정이 아닌 알림(당첨번호 조회 실패 등)을 받을 곳 목록입니다
+func (c *Config) Recipients(event string, severity message.Severity) []Destination {
+	r := routing{notify: &c.Notify, defaults: c.DefaultDestinations()}
+	return r.route(r.defaults, nil, event, severity)
+}
+
+// route는 기본 수신 목록에 규칙을 적용합니다 (only 규칙이 맞으면 기본 수신 목록 대신 규칙의 수신자에게만)
+func (r *routing) route(dests []Destination, account *Account, event string, severity message.Severity) []Destination {
+	var extra []Destination
+	only := false
+	for _, route := range r.notify.Routes {
+		if !route.matches(account, event, severity) {
+			continue
+		}
+		extra = append(extra, r.resolve(route.To)...)
+		only = only || route.Only
+	}
+	if only {
+		dests = nil
+	}
+
+	var out []Destination
+	for _, d := range append(slices.Clone(dests), extra...) {
+		if !slices.Contains(out, d) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// resolve는 수신자 이름을 받는 곳으로 바꿉니다 (없는 이름은 설정 검증에서 걸러짐)
+func (r *routing) resolve(names []string) []Destination {
+	var dests []Destination
+	for _, name := range names {
+		if name == DefaultRecipient {
+			dests = append(dests, r.defaults...)
+			continue
+		}
+		for _, recipient := range r.notify.Recipients {
+			if recipient.Name == name {
+				dests = append(dests, recipient.destinations()...)
+				break
+			}
+		}
+	}
+	return dests
+}
+
+// matches는 규칙이 알림에 해당하는지 확인합니다
+// 이벤트 "failure"는 "failure.login" 등 모든 세부 종류와 일치합니다
+func (route Route) matches(account *Account, event string, severity message.Severity) bool {
+	if len(route.Events) > 0 && !slices.ContainsFunc(route.Events, func(e string) bool { return message.MatchEvent(e, event) }) {
+		return false
+	}
+	if route.MinSeverity != "" {
+		if minimum, err := message.ParseSeverity(route.MinSeverity); err == nil && severity < minimum {
+			return false
+		}
+	}
+	if len(route.Accounts) > 0 {
+		if account == nil || !slices.ContainsFunc(route.Accounts, func(id string) bool {
+			return id == account.UserID || (account.Alias != "" && id == account.Alias)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateNotify는 별칭, 수신자, 라우팅 규칙을 검증합니다
+func (c *Config) validateNotify() issueList {
+	var issues issueList
+
+	names := map[string]bool{DefaultRecipient: true}
+	for i, recipient := range c.Notify.Recipients {
+		path := fmt.Sprintf("notify.recipients[%d]", i)
+		switch {
+		case recipient.Name == "":
+			issues.add(path+".name", "수신자 이름이 필요합니다")
+		case names[recipient.Name]:
+			issues.add(path+".name", "수신자 이름 %q이 중복되거나 예약된 이름입니다", recipient.Name)
+		}
+		names[recipient.Name] = true
+		if len(recipient.destinations()) == 0 {
+			issues.add(path, "telegramChatId, slackWebhook, discordWebhook, kakaoRefreshToken 중 하나가 필요합니다")
+		}
+		checkWebhook(&issues, path+".slackWebhook", recipient.SlackWebhook)
+		checkWebhook(&issues, path+".discordWebhook", recipient.DiscordWebhook)
+	}
+
+	checkNames := func(path string, list []string) {
+		for j, name := range list {
+			if !names[name] {
+				issues.add(fmt.Sprintf("%s[%d]", path, j), "등록되지 않은 수신자 %q (notify.recipients 또는 %q)", name, DefaultRecipient)
+			}
+		}
+	}
+
+	accounts := make(map[string]bool, len(c.Accounts)*2)
+	for _, account := range c.Accounts {
+		accounts[account.UserID] = true
+	}
+	for i, account := range c.Accounts {
+		path := fmt.Sprintf("accounts[%d]", i)
+		if account.Alias != "" && account.Alias != account.UserID {
+			if accounts[account.Alias] {
+				issues.add(path+".alias", "별칭 %q이 다른 계정의 아이디 또는 별칭과 겹칩니다", account.Alias)
+			}
+			accounts[account.Alias] = true
+		}
+		checkNames(path+".notify", account.Notify)
+	}
+
+	checkEvents := func(path string, events []string) {
+		for j, event := range events {
+			head, _, _ := strings.Cut(event, ".")
+			if !slices.Contains(message.Events, head) {
+				issues.add(fmt.Sprintf("%s[%d]", path, j), "알 수 없는 이벤트 %q (%s)", event, strings.Join(message.Events, ", "))
+			}
+		}
+	}
+
+	for i, route := range c.Notify.Routes {
+		path := fmt.Sprintf("notify.routes[%d]", i)
+		checkEvents(path+".events", route.Events)
+		if route.MinSeverity != "" {
+			if _, err := message.ParseSeverity(route.MinSeverity); err != nil {
+				issues.add(path+".minSeverity", "%v", err)
+			}
+		}
+		for j, id := range route.Accounts {
+			if !accounts[id] {
+				issues.add(fmt.Sprintf("%s.accounts[%d]", path, j), "등록되지 않은 계정 %q", id)
+			}
+		}
+		if len(route.To) == 0 {
+			issues.add(path+".to", "수신자가 필요합니다")
+		}
+		checkNames(path+".to", route.To)
+	}
+
+	if q := c.Notify.QuietHours; q != nil {
+		for _, field := range []struct{ name, value string }{{"start", q.Start}, {"end", q.End}} {
+			if _, err := parseClock(field.value); err != nil {
+				issues.add("notify.quietHours."+field.name, "%v", err)
+			}
+		}
+		if q.Timezone != "" {
+			if _, err := time.LoadLocation(q.Timezone); err != nil {
+				issues.add("notify.quietHours.timezone", "알 수 없는 시간대 %q", q.Timezone)
+			}
+		}
+	}
+	checkDuration(&issues, "notify.dedupe", c.Notify.Dedupe, true)
+	for i, limit := range c.Notify.Limits {
+		path := fmt.Sprintf("notify.limits[%d]", i)
+		checkEvents(path+".events", limit.Events)
+		if limit.Max <= 0 {
+			issues.add(path+".max", "1 이상이어야 합니다")
+		}
+		if limit.Per == "" {
+			issues.add(path+".per", "기간이 필요합니다 (예: 24h)")
+		}
+		checkDuration(&issues, path+".per", limit.Per, false)
+	}
+	if c.Notify.Override != "" {
+		if _, err := message.ParseSeverity(c.Notify.Override); err != nil {
+			issues.add("notify.override", "%v", err)
+		}
+	}
+
+	checkWebhook(&issues, "slack.webhookUrl", c.Slack.WebhookURL)
+	checkWebhook(&issues, "discord.webhookUrl", c.Discord.WebhookURL)
+	checkURL(&issues, "discord.avatarUrl", c.Discord.AvatarURL, "http", "https")
+
+	if len(c.KakaoTokens()) > 0 && c.Kakao.RestAPIKey == "" {
+		issues.add("kakao.restApiKey", "카카오톡 알림(kakaoRefreshToken)에는 앱의 REST API 키가 필요합니다")
+	}
+	checkURL(&issues, "kakao.linkUrl", c.Kakao.LinkURL, "http", "https")
+	checkURL(&issues, "kakao.apiUrl", c.Kakao.APIURL, "http", "https")
+	checkURL(&issues, "kakao.authUrl", c.Kakao.AuthURL, "http", "https")
+
+	return issues
+}
+
+// checkWebhook은 웹훅 주소를 검증합니다 (오류 메시지에는 토큰이 든 경로를 가린 주소만 표시)
+func checkWebhook(issues *issueList, path, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		issues.add(path, "http, https 웹훅 주소여야 합니다 (%s)", webhook.Redact(value))
+	}
+}
@@ -0,0 +1,274 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"dhlottery/datadir"
+	"dhlottery/vault"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// 금고 기본값
+const (
+	vaultFileName          = "vault.enc" // 데이터 디렉토리의 secrets 폴더
+	vaultPassphraseEnv     = "DH_VAULT_PASSPHRASE"
+	passwordCommandTimeout = 30 * time.Second
+)
+
+// VaultConfig는 암호화된 비밀번호 금고 설정입니다
+// 금고는 DH_VAULT_PASSPHRASE 환경변수(또는 터미널 입력)의 암호나 키 파일로 엽니다
+type VaultConfig struct {
+	Path    string `json:"path,omitempty"`    // 금고 파일 경로 (기본 <데이터 디렉토리>/secrets/vault.enc)
+	KeyFile string `json:"keyFile,omitempty"` // 암호 대신 사용할 키 파일 (vault rotate -new-key-file로 생성)
+}
+
+// FilePath는 금고 파일 경로를 반환합니다
+// 경로를 지정하지 않으면 데이터 디렉토리의 secrets/vault.enc이고,
+// 그곳에 금고가 없고 이전 버전처럼 현재 디렉토리에 vault.enc가 있으면 그 파일을 씁니다
+func (v VaultConfig) FilePath() string {
+	if v.Path != "" {
+		return v.Path
+	}
+	path := datadir.Path(datadir.Secrets, vaultFileName)
+	if !vault.Exists(path) && vault.Exists(vaultFileName) {
+		return vaultFileName
+	}
+	return path
+}
+
+// Key는 금고를 여는 키를 가져옵니다 (키 파일 → 환경변수 → 터미널 입력)
+func (v VaultConfig) Key() (vault.Key, error) {
+	if v.KeyFile != "" {
+		return vault.KeyFromFile(v.KeyFile)
+	}
+	if passphrase := os.Getenv(vaultPassphraseEnv); passphrase != "" {
+		return vault.Passphrase(passphrase), nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return vault.Key{}, fmt.Errorf("금고 암호가 필요합니다 (%s 환경변수 또는 vault.keyFile 설정)", vaultPassphraseEnv)
+	}
+
+	passphrase, err := ReadSecret("🔐 금고 암호: ")
+	if err != nil {
+		return vault.Key{}, err
+	}
+	return vault.Passphrase(passphrase), nil
+}
+
+// ReadSecret은 화면에 표시하지 않고 비밀 값을 입력받습니다 (터미널이 아니면 한 줄 읽기)
+func ReadSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		secret, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("입력 실패: %w", err)
+		}
+		return string(secret), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("입력 실패: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// openedVault는 한 번 연 금고를 보관합니다 (계정마다 암호를 다시 묻지 않도록)
+var openedVault struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	key     vault.Key
+	hasKey  bool
+	v       *vault.Vault
+}
+
+// OpenVault는 금고를 엽니다 (같은 파일이면 이전에 연 금고나 키를 재사용)
+func (v VaultConfig) OpenVault() (*vault.Vault, error) {
+	path := v.FilePath()
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("금고 파일이 없습니다: %s (dhlottery vault add <아이디>로 생성)", path)
+	}
+
+	openedVault.mu.Lock()
+	defer openedVault.mu.Unlock()
+
+	if openedVault.path == path && openedVault.v != nil && openedVault.modTime.Equal(info.ModTime()) {
+		return openedVault.v, nil
+	}
+
+	key := openedVault.key
+	if openedVault.path != path || !openedVault.hasKey {
+		if key, err = v.Key(); err != nil {
+			return nil, err
+		}
+	}
+
+	opened, err := vault.Open(path, key)
+	if err != nil {
+		return nil, err
+	}
+
+	openedVault.path, openedVault.modTime = path, info.ModTime()
+	openedVault.key, openedVault.hasKey = key, true
+	openedVault.v = opened
+	return opened, nil
+}
+
+// ---- 계정 비밀번호 ----
+
+// 비밀번호 출처
+const (
+	PasswordPlain    = "설정 파일"
+	PasswordFromFile = "파일"
+	PasswordCommand  = "명령"
+	PasswordVault    = "금고"
+)
+
+// PasswordSource는 비밀번호를 어디서 가져오는지 반환합니다 (비밀번호 자체는 읽지 않음)
+func (a Account) PasswordSource() string {
+	switch {
+	case a.Password != "":
+		return PasswordPlain
+	case a.PasswordFile != "":
+		return PasswordFromFile
+	case a.PasswordCommand != "":
+		return PasswordCommand
+	default:
+		return PasswordVault
+	}
+}
+
+// ResolvePassword는 필요할 때 비밀번호를 가져옵니다
+// password → passwordFile → passwordCommand → 금고(아이디로 검색) 순서로 확인합니다
+func (a Account) ResolvePassword() (string, error) {
+	switch a.PasswordSource() {
+	case PasswordPlain:
+		return a.Password, nil
+
+	case PasswordFromFile:
+		data, err := os.ReadFile(a.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("%s 비밀번호 파일 읽기 실패: %w", a.UserID, err)
+		}
+		password := strings.TrimRight(string(data), "\r\n")
+		if password == "" {
+			return "", fmt.Errorf("%s 비밀번호 파일이 비어 있습니다: %s", a.UserID, a.PasswordFile)
+		}
+		return password, nil
+
+	case PasswordCommand:
+		return runPasswordCommand(a.UserID, a.PasswordCommand)
+	}
+
+	opened, err := a.vaultConfig().OpenVault()
+	if err != nil {
+		return "", fmt.Errorf("%s 비밀번호 가져오기 실패: %w", a.UserID, err)
+	}
+	password, ok := opened.Get(a.UserID)
+	if !ok {
+		return "", fmt.Errorf("금고에 %s 계정의 비밀번호가 없습니다 (dhlottery vault add %s)", a.UserID, a.UserID)
+	}
+	return password, nil
+}
+
+func (a Account) vaultConfig() VaultConfig {
+	if a.vault == nil {
+		return VaultConfig{}
+	}
+	return *a.vault
+}
+
+// runPasswordCommand는 셸 명령을 실행해 표준 출력의 첫 줄을 비밀번호로 사용합니다
+// (오류 메시지에는 표준 출력을 넣지 않음)
+func runPasswordCommand(userID, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), passwordCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s 비밀번호 명령 실패: %w (%s)", userID, err, msg)
+		}
+		return "", fmt.Errorf("%s 비밀번호 명령 실패: %w", userID, err)
+	}
+
+	password, _, _ := strings.Cut(stdout.String(), "\n")
+	password = strings.TrimRight(password, "\r")
+	if password == "" {
+		return "", fmt.Errorf("%s 비밀번호 명령의 출력이 비어 있습니다", userID)
+	}
+	return password, nil
+}
+
+// bind는 각 계정이 설정의 금고와 알림 규칙을 참조하도록 연결합니다
+func (c *Config) bind() {
+	c.bindSecrets()
+	c.bindNotify()
+}
+
+// bindSecrets는 각 계정이 금고 설정을 참조하도록 연결합니다
+func (c *Config) bindSecrets() {
+	for i := range c.Accounts {
+		c.Accounts[i].vault = &c.Vault
+	}
+}
+
+// validateSecrets는 계정별 비밀번호 출처를 검증합니다 (비밀번호를 실제로 읽지는 않음)
+func (c *Config) validateSecrets() issueList {
+	var issues issueList
+
+	vaultExists := vault.Exists(c.Vault.FilePath())
+	for i, account := range c.Accounts {
+		path := fmt.Sprintf("accounts[%d]", i)
+
+		sources := 0
+		for _, value := range []string{account.Password, account.PasswordFile, account.PasswordCommand} {
+			if value != "" {
+				sources++
+			}
+		}
+		if sources > 1 {
+			issues.add(path, "password, passwordFile, passwordCommand 중 하나만 지정하세요")
+			continue
+		}
+		if sources == 0 && !vaultExists && account.UserID != "" {
+			issues.add(path+".password", "비밀번호가 필요합니다 (password, passwordFile, passwordCommand 중 하나를 지정하거나 dhlottery vault add %s로 금고에 저장)", account.UserID)
+		}
+	}
+
+	if c.Vault.KeyFile != "" {
+		if _, err := os.Stat(c.Vault.KeyFile); err != nil {
+			issues.add("vault.keyFile", "키 파일이 없습니다: %s", c.Vault.KeyFile)
+		}
+	}
+
+	return issues
+}
+
+// VaultSettings는 설정 파일에서 금고 설정만 읽습니다 (계정 검증 없이, 금고 명령용)
+func VaultSettings(path string) (VaultConfig, error) {
+	var settings VaultConfig
+	_, err := readSection(path, "vault", &settings)
+	return settings, err
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// DataDirSetting은 설정 파일의 dataDir 값을 읽습니다 (계정 검증 없이, 로거 초기화 전에 사용)
+// 상대 경로는 설정 파일이 있는 디렉토리를 기준으로 합니다 (설정 파일이 없거나 값이 없으면 "")
+func DataDirSetting(path string) (string, error) {
+	var dir string
+	file, err := readSection(path, "dataDir", &dir)
+	if err != nil || dir == "" {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(file), dir)
+	}
+	return dir, nil
+}
+
+// readSection은 설정 파일의 최상위 항목 하나만 읽어 out에 담고, 읽은 설정 파일 경로를 반환합니다
+// 설정 파일이나 항목이 없으면 out을 그대로 둡니다
+func readSection(path, key string, out interface{}) (string, error) {
+	file := FindFile(path)
+	if file == "" {
+		return "", nil
+	}
+
+	format, err := FormatOf(file)
+	if err != nil {
+		return file, err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return file, fmt.Errorf("설정 파일 읽기 실패: %w", err)
+	}
+	root, err := parseDocument(format, data)
+	if err != nil {
+		return file, fmt.Errorf("설정 파일 파싱 실패: %s: %w", file, err)
+	}
+
+	section, ok := root.fields[key]
+	if root.kind != nodeMap || !ok {
+		return file, nil
+	}
+
+	var issues issueList
+	interpolate(section, key, &issues)
+	checkSchema(section, reflect.TypeOf(out).Elem(), key, &issues)
+	if len(issues) > 0 {
+		return file, &ValidationError{File: file, Issues: issues}
+	}
+
+	normalized, err := json.Marshal(section.plain())
+	if err == nil {
+		err = json.Unmarshal(normalized, out)
+	}
+	if err != nil {
+		return file, fmt.Errorf("%s 설정 변환 실패: %w", key, err)
+	}
+	return file, nil
+}
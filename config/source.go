@@ -0,0 +1,572 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// nodeKind는 설정 문서 노드의 종류입니다
+type nodeKind int
+
+const (
+	nodeScalar nodeKind = iota
+	nodeMap
+	nodeList
+)
+
+// node는 형식(JSON/YAML/TOML)과 관계없이 설정 문서를 위치 정보와 함께 표현합니다
+type node struct {
+	kind   nodeKind
+	value  interface{} // 스칼라 값: string, bool, int64, float64, nil
+	keys   []string    // 맵 키 (문서 순서)
+	fields map[string]*node
+	items  []*node
+	line   int // 맵 항목은 키 위치, 목록 항목은 값 위치
+	col    int
+}
+
+func newMap(line, col int) *node {
+	return &node{kind: nodeMap, fields: make(map[string]*node), line: line, col: col}
+}
+
+// set은 맵에 항목을 추가합니다 (중복 키는 오류)
+func (n *node) set(key string, child *node) error {
+	if _, exists := n.fields[key]; exists {
+		return &locatedError{line: child.line, col: child.col, msg: fmt.Sprintf("중복된 키 %q", key)}
+	}
+	n.keys = append(n.keys, key)
+	n.fields[key] = child
+	return nil
+}
+
+// typeName은 오류 메시지에 사용할 노드 값의 종류입니다
+func (n *node) typeName() string {
+	switch n.kind {
+	case nodeMap:
+		return "객체"
+	case nodeList:
+		return "목록"
+	}
+	switch n.value.(type) {
+	case string:
+		return "문자열"
+	case bool:
+		return "참/거짓"
+	case int64, float64:
+		return "숫자"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", n.value)
+}
+
+// plain은 노드를 encoding/json으로 다시 인코딩할 수 있는 값으로 변환합니다
+func (n *node) plain() interface{} {
+	switch n.kind {
+	case nodeMap:
+		m := make(map[string]interface{}, len(n.fields))
+		for key, child := range n.fields {
+			m[key] = child.plain()
+		}
+		return m
+	case nodeList:
+		list := make([]interface{}, len(n.items))
+		for i, item := range n.items {
+			list[i] = item.plain()
+		}
+		return list
+	}
+	return n.value
+}
+
+// locate는 "accounts[1].policy.rules[0].when" 형식의 경로에 가장 가까운 노드의 위치를 찾습니다
+func (n *node) locate(path string) (int, int) {
+	line, col := n.line, n.col
+	cur := n
+
+	for _, part := range splitPath(path) {
+		var next *node
+		if index, err := strconv.Atoi(part); err == nil {
+			if cur.kind == nodeList && index >= 0 && index < len(cur.items) {
+				next = cur.items[index]
+			}
+		} else if cur.kind == nodeMap {
+			next = cur.fields[part]
+		}
+
+		if next == nil {
+			break
+		}
+		cur = next
+		if cur.line > 0 {
+			line, col = cur.line, cur.col
+		}
+	}
+
+	return line, col
+}
+
+// splitPath는 경로를 키와 인덱스로 나눕니다 ("a[1].b" → a, 1, b)
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool {
+		return r == '.' || r == '[' || r == ']'
+	})
+}
+
+// locatedError는 문서 내 위치가 있는 파싱 오류입니다
+type locatedError struct {
+	line, col int
+	msg       string
+}
+
+func (e *locatedError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.line, e.col, e.msg)
+}
+
+// Format은 설정 파일 형식입니다
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// FormatOf는 파일 확장자로 설정 파일 형식을 결정합니다
+func FormatOf(filename string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".toml":
+		return FormatTOML, nil
+	}
+	return "", fmt.Errorf("지원하지 않는 설정 파일 형식입니다: %s (.json, .yaml, .yml, .toml)", filename)
+}
+
+// parseDocument는 형식에 맞게 설정 문서를 파싱합니다
+func parseDocument(format Format, data []byte) (*node, error) {
+	switch format {
+	case FormatYAML:
+		return parseYAML(data)
+	case FormatTOML:
+		return parseTOML(data)
+	default:
+		return parseJSON(data)
+	}
+}
+
+// ---- JSON ----
+
+type jsonParser struct {
+	data []byte
+	dec  *json.Decoder
+}
+
+func parseJSON(data []byte) (*node, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	p := &jsonParser{data: data, dec: dec}
+
+	root, err := p.value()
+	if err != nil {
+		return nil, p.wrap(err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		line, col := offsetPosition(data, p.skip(dec.InputOffset()))
+		return nil, &locatedError{line: line, col: col, msg: "문서 끝에 불필요한 내용이 있습니다"}
+	}
+
+	return root, nil
+}
+
+// skip은 offset부터 공백과 구분자(, :)를 건너뛴 위치를 반환합니다
+func (p *jsonParser) skip(offset int64) int {
+	i := int(offset)
+	for i < len(p.data) && strings.IndexByte(" \t\r\n,:", p.data[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+func (p *jsonParser) value() (*node, error) {
+	line, col := offsetPosition(p.data, p.skip(p.dec.InputOffset()))
+
+	tok, err := p.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			m := newMap(line, col)
+			for p.dec.More() {
+				keyLine, keyCol := offsetPosition(p.data, p.skip(p.dec.InputOffset()))
+				keyTok, err := p.dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ := keyTok.(string)
+
+				child, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				child.line, child.col = keyLine, keyCol
+				if err := m.set(key, child); err != nil {
+					return nil, err
+				}
+			}
+			_, err := p.dec.Token() // '}'
+			return m, err
+		}
+
+		list := &node{kind: nodeList, line: line, col: col}
+		for p.dec.More() {
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, item)
+		}
+		_, err := p.dec.Token() // ']'
+		return list, err
+
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return &node{value: i, line: line, col: col}, nil
+		}
+		f, err := t.Float64()
+		if err != nil {
+			return nil, &locatedError{line: line, col: col, msg: fmt.Sprintf("잘못된 숫자 %s", t)}
+		}
+		return &node{value: f, line: line, col: col}, nil
+
+	default:
+		return &node{value: t, line: line, col: col}, nil
+	}
+}
+
+// wrap은 encoding/json 오류에 위치 정보를 붙입니다
+func (p *jsonParser) wrap(err error) error {
+	var located *locatedError
+	if errors.As(err, &located) {
+		return err
+	}
+
+	var syntax *json.SyntaxError
+	if errors.As(err, &syntax) {
+		line, col := offsetPosition(p.data, int(syntax.Offset))
+		return &locatedError{line: line, col: col, msg: syntax.Error()}
+	}
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		line, col := offsetPosition(p.data, len(p.data))
+		return &locatedError{line: line, col: col, msg: "문서가 중간에 끝났습니다"}
+	}
+	return err
+}
+
+// offsetPosition은 바이트 위치를 줄/칸 번호로 변환합니다 (1부터 시작)
+func offsetPosition(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := offset - (bytes.LastIndexByte(before, '\n') + 1) + 1
+	return line, col
+}
+
+// ---- YAML ----
+
+func parseYAML(data []byte) (*node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return newMap(1, 1), nil
+	}
+	return convertYAML(doc.Content[0])
+}
+
+func convertYAML(y *yaml.Node) (*node, error) {
+	switch y.Kind {
+	case yaml.AliasNode:
+		return convertYAML(y.Alias)
+
+	case yaml.MappingNode:
+		m := newMap(y.Line, y.Column)
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			key, value := y.Content[i], y.Content[i+1]
+			child, err := convertYAML(value)
+			if err != nil {
+				return nil, err
+			}
+			child.line, child.col = key.Line, key.Column
+			if err := m.set(key.Value, child); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+
+	case yaml.SequenceNode:
+		list := &node{kind: nodeList, line: y.Line, col: y.Column}
+		for _, item := range y.Content {
+			child, err := convertYAML(item)
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, child)
+		}
+		return list, nil
+	}
+
+	var value interface{}
+	if err := y.Decode(&value); err != nil {
+		return nil, &locatedError{line: y.Line, col: y.Column, msg: err.Error()}
+	}
+	switch v := value.(type) {
+	case int:
+		value = int64(v)
+	case uint64:
+		value = float64(v)
+	case string, bool, int64, float64, nil:
+	default:
+		// 날짜 등은 원문 그대로 문자열로 사용
+		value = y.Value
+	}
+	return &node{value: value, line: y.Line, col: y.Column}, nil
+}
+
+// ---- TOML ----
+
+type tomlBuilder struct {
+	p    *unstable.Parser
+	data []byte
+}
+
+func parseTOML(data []byte) (*node, error) {
+	p := &unstable.Parser{}
+	p.Reset(data)
+	b := &tomlBuilder{p: p, data: data}
+
+	root := newMap(1, 1)
+	current := root
+
+	for p.NextExpression() {
+		expr := p.Expression()
+
+		switch expr.Kind {
+		case unstable.KeyValue:
+			value, err := b.value(expr.Value())
+			if err != nil {
+				return nil, err
+			}
+			if err := b.assign(current, expr.Key(), value); err != nil {
+				return nil, err
+			}
+
+		case unstable.Table:
+			table, err := b.table(root, expr.Key(), false)
+			if err != nil {
+				return nil, err
+			}
+			current = table
+
+		case unstable.ArrayTable:
+			table, err := b.table(root, expr.Key(), true)
+			if err != nil {
+				return nil, err
+			}
+			current = table
+		}
+	}
+
+	if err := p.Error(); err != nil {
+		var perr *unstable.ParserError
+		if errors.As(err, &perr) {
+			line, col := b.highlight(perr.Highlight)
+			return nil, &locatedError{line: line, col: col, msg: perr.Message}
+		}
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// position은 노드의 위치를 반환합니다 (위치 정보가 없으면 0)
+func (b *tomlBuilder) position(n *unstable.Node) (int, int) {
+	if n.Raw.Length == 0 && n.Raw.Offset == 0 {
+		return 0, 0
+	}
+	start := b.p.Shape(n.Raw).Start
+	return start.Line, start.Column
+}
+
+// highlight는 파서 오류가 가리키는 위치를 반환합니다
+func (b *tomlBuilder) highlight(highlight []byte) (int, int) {
+	offset := cap(b.data) - cap(highlight)
+	if highlight == nil || offset < 0 || offset > len(b.data) {
+		return offsetPosition(b.data, len(b.data))
+	}
+	return offsetPosition(b.data, offset)
+}
+
+// keyParts는 (점으로 구분된) 키를 나누고, 마지막 키의 위치를 반환합니다
+func (b *tomlBuilder) keyParts(it unstable.Iterator) ([]string, int, int) {
+	var parts []string
+	line, col := 0, 0
+	for it.Next() {
+		k := it.Node()
+		parts = append(parts, string(k.Data))
+		line, col = b.position(k)
+	}
+	return parts, line, col
+}
+
+// assign은 (점으로 구분된) 키에 값을 넣습니다
+func (b *tomlBuilder) assign(table *node, it unstable.Iterator, value *node) error {
+	parts, line, col := b.keyParts(it)
+	value.line, value.col = line, col
+	fillPositions(value, line, col)
+
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := table.fields[part]
+		if !ok {
+			next = newMap(line, col)
+			table.keys = append(table.keys, part)
+			table.fields[part] = next
+		}
+		if next.kind != nodeMap {
+			return &locatedError{line: line, col: col, msg: fmt.Sprintf("%q는 테이블이 아닙니다", part)}
+		}
+		table = next
+	}
+
+	return table.set(parts[len(parts)-1], value)
+}
+
+// table은 [table] 또는 [[array table]] 헤더가 가리키는 테이블을 찾거나 만듭니다
+func (b *tomlBuilder) table(root *node, it unstable.Iterator, array bool) (*node, error) {
+	parts, line, col := b.keyParts(it)
+
+	table := root
+	for i, part := range parts {
+		last := i == len(parts)-1
+		next, ok := table.fields[part]
+
+		switch {
+		case last && array:
+			if !ok {
+				next = &node{kind: nodeList, line: line, col: col}
+				table.keys = append(table.keys, part)
+				table.fields[part] = next
+			}
+			if next.kind != nodeList {
+				return nil, &locatedError{line: line, col: col, msg: fmt.Sprintf("%q는 테이블 배열이 아닙니다", part)}
+			}
+			item := newMap(line, col)
+			next.items = append(next.items, item)
+			return item, nil
+
+		case !ok:
+			next = newMap(line, col)
+			table.keys = append(table.keys, part)
+			table.fields[part] = next
+		}
+
+		// 테이블 배열 아래의 하위 테이블은 마지막 항목에 속함
+		if next.kind == nodeList && len(next.items) > 0 {
+			next = next.items[len(next.items)-1]
+		}
+		if next.kind != nodeMap {
+			return nil, &locatedError{line: line, col: col, msg: fmt.Sprintf("%q는 테이블이 아닙니다", part)}
+		}
+		table = next
+	}
+
+	return table, nil
+}
+
+// value는 TOML 값 노드를 변환합니다
+func (b *tomlBuilder) value(v *unstable.Node) (*node, error) {
+	line, col := b.position(v)
+	raw := string(v.Data)
+
+	switch v.Kind {
+	case unstable.Array:
+		list := &node{kind: nodeList, line: line, col: col}
+		it := v.Children()
+		for it.Next() {
+			item, err := b.value(it.Node())
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, item)
+		}
+		return list, nil
+
+	case unstable.InlineTable:
+		m := newMap(line, col)
+		it := v.Children()
+		for it.Next() {
+			kv := it.Node()
+			child, err := b.value(kv.Value())
+			if err != nil {
+				return nil, err
+			}
+			if err := b.assign(m, kv.Key(), child); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+
+	case unstable.String:
+		return &node{value: raw, line: line, col: col}, nil
+
+	case unstable.Bool:
+		return &node{value: raw == "true", line: line, col: col}, nil
+
+	case unstable.Integer:
+		i, err := strconv.ParseInt(raw, 0, 64)
+		if err != nil {
+			return nil, &locatedError{line: line, col: col, msg: fmt.Sprintf("잘못된 정수 %s", raw)}
+		}
+		return &node{value: i, line: line, col: col}, nil
+
+	case unstable.Float:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(raw, "_", ""), 64)
+		if err != nil {
+			return nil, &locatedError{line: line, col: col, msg: fmt.Sprintf("잘못된 실수 %s", raw)}
+		}
+		return &node{value: f, line: line, col: col}, nil
+	}
+
+	// 날짜/시각은 문자열로 사용
+	return &node{value: raw, line: line, col: col}, nil
+}
+
+// fillPositions는 위치 정보가 없는 하위 노드(배열 항목 등)에 부모 위치를 채웁니다
+func fillPositions(n *node, line, col int) {
+	if n.line == 0 {
+		n.line, n.col = line, col
+	}
+	for _, item := range n.items {
+		fillPositions(item, n.line, n.col)
+	}
+	for _, child := range n.fields {
+		fillPositions(child, n.line, n.col)
+	}
+}
@@ -0,0 +1,333 @@
+package config
+
+import (
+	"dhlottery/message"
+	"dhlottery/policy"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Issue는 설정 검증 오류 하나입니다
+type Issue struct {
+	Path    string // 설정 경로 (예: accounts[1].policy.rules[0].when)
+	Line    int    // 설정 파일 내 줄 번호 (알 수 없으면 0)
+	Col     int
+	Message string
+}
+
+// String은 "줄:칸: 경로: 메시지" 형식으로 오류를 표시합니다
+func (i Issue) String() string {
+	location := ""
+	if i.Line > 0 {
+		location = fmt.Sprintf("%d:%d: ", i.Line, i.Col)
+	}
+	if i.Path == "" {
+		return location + i.Message
+	}
+	return fmt.Sprintf("%s%s: %s", location, i.Path, i.Message)
+}
+
+// ValidationError는 설정 파일 검증 오류 목록입니다
+type ValidationError struct {
+	File   string
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("설정 검증 실패 (%d건)", len(e.Issues)))
+	for _, issue := range e.Issues {
+		sb.WriteString("\n  ")
+		if e.File != "" {
+			sb.WriteString(e.File + ":")
+		}
+		sb.WriteString(issue.String())
+	}
+	return sb.String()
+}
+
+// issueList는 검증 중 발견한 오류를 모읍니다
+type issueList []Issue
+
+func (l *issueList) add(path, format string, args ...interface{}) {
+	*l = append(*l, Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// addAt은 문서 노드의 위치와 함께 오류를 추가합니다
+func (l *issueList) addAt(n *node, path, format string, args ...interface{}) {
+	*l = append(*l, Issue{Path: path, Line: n.line, Col: n.col, Message: fmt.Sprintf(format, args...)})
+}
+
+// ---- 환경변수 치환 ----
+
+// interpolate는 값 전체가 ${NAME} 또는 ${NAME:-기본값}인 문자열을 환경변수로 치환합니다
+// 다른 글자와 섞인 $는 그대로 두므로 $가 들어간 기존 비밀번호는 바뀌지 않습니다 ($${NAME}은 글자 그대로 ${NAME})
+func interpolate(n *node, path string, issues *issueList) {
+	switch n.kind {
+	case nodeMap:
+		for _, key := range n.keys {
+			interpolate(n.fields[key], joinPath(path, key), issues)
+		}
+	case nodeList:
+		for i, item := range n.items {
+			interpolate(item, fmt.Sprintf("%s[%d]", path, i), issues)
+		}
+	default:
+		s, ok := n.value.(string)
+		if !ok {
+			return
+		}
+		expanded, err := expandEnv(s)
+		if err != nil {
+			issues.addAt(n, path, "%v", err)
+			return
+		}
+		n.value = expanded
+	}
+}
+
+// expandEnv는 환경변수 참조인 값을 치환합니다 (참조가 아니면 그대로 반환)
+func expandEnv(s string) (string, error) {
+	if strings.HasPrefix(s, "$${") && strings.HasSuffix(s, "}") {
+		return s[1:], nil
+	}
+	if !strings.HasPrefix(s, "${") || !strings.HasSuffix(s, "}") || strings.Count(s, "}") != 1 {
+		return s, nil
+	}
+
+	name, fallback, hasDefault := strings.Cut(s[2:len(s)-1], ":-")
+	if name == "" {
+		return "", fmt.Errorf("환경변수 이름이 비어 있습니다")
+	}
+	value, ok := os.LookupEnv(name)
+	switch {
+	case ok && value != "":
+		return value, nil
+	case hasDefault:
+		return fallback, nil
+	default:
+		return "", fmt.Errorf("환경변수 %s가 설정되지 않았습니다", name)
+	}
+}
+
+// ---- 스키마 검증 ----
+
+// checkSchema는 문서 노드가 Go 구조체의 json 태그와 타입에 맞는지 검사합니다
+// 숫자로 적힌 문자열 항목(예: telegramChatId: 12345)은 문자열로 바꿉니다
+func checkSchema(n *node, t reflect.Type, path string, issues *issueList) {
+	if t.Kind() == reflect.Pointer {
+		if n.kind == nodeScalar && n.value == nil {
+			return
+		}
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.kind != nodeMap {
+			issues.addAt(n, path, "객체가 필요합니다 (%s)", n.typeName())
+			return
+		}
+		fields := jsonFields(t)
+		for _, key := range n.keys {
+			child := n.fields[key]
+			field, ok := fields[key]
+			if !ok {
+				issues.addAt(child, joinPath(path, key), "알 수 없는 설정 항목입니다%s", suggest(key, fields))
+				continue
+			}
+			checkSchema(child, field.Type, joinPath(path, key), issues)
+		}
+
+	case reflect.Slice:
+		if n.kind == nodeScalar && n.value == nil {
+			return
+		}
+		if n.kind != nodeList {
+			issues.addAt(n, path, "목록이 필요합니다 (%s)", n.typeName())
+			return
+		}
+		for i, item := range n.items {
+			checkSchema(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), issues)
+		}
+
+	case reflect.String:
+		switch v := n.value.(type) {
+		case string:
+		case int64:
+			n.value = strconv.FormatInt(v, 10)
+		case float64:
+			n.value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			issues.addAt(n, path, "문자열이 필요합니다 (%s)", n.typeName())
+		}
+
+	case reflect.Int:
+		switch v := n.value.(type) {
+		case int64:
+		case float64:
+			if v != float64(int64(v)) {
+				issues.addAt(n, path, "정수가 필요합니다 (%v)", v)
+				return
+			}
+			n.value = int64(v)
+		default:
+			issues.addAt(n, path, "정수가 필요합니다 (%s)", n.typeName())
+		}
+
+	case reflect.Bool:
+		if _, ok := n.value.(bool); !ok {
+			issues.addAt(n, path, "true 또는 false가 필요합니다 (%s)", n.typeName())
+		}
+	}
+}
+
+// jsonFields는 구조체의 json 태그 이름별 필드를 반환합니다
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// suggest는 대소문자만 다른 항목이 있으면 안내 문구를 반환합니다
+func suggest(key string, fields map[string]reflect.StructField) string {
+	for name := range fields {
+		if strings.EqualFold(name, key) {
+			return fmt.Sprintf(" (혹시 %q?)", name)
+		}
+	}
+	return ""
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// ---- 의미 검증 ----
+
+// validate는 설정 값의 의미를 검증합니다 (계정, 비밀번호 출처, 구매 정책, 기간 형식, 일정)
+func (c *Config) validate() issueList {
+	var issues issueList
+
+	if len(c.Accounts) == 0 {
+		issues.add("accounts", "계정 정보가 없습니다")
+	}
+
+	seen := make(map[string]bool, len(c.Accounts))
+	for i, account := range c.Accounts {
+		path := fmt.Sprintf("accounts[%d]", i)
+		if account.UserID == "" {
+			issues.add(path+".userId", "아이디가 필요합니다")
+		} else if seen[account.UserID] {
+			issues.add(path+".userId", "중복된 계정 %q", account.UserID)
+		}
+		seen[account.UserID] = true
+
+		if account.Policy != nil {
+			for j, rule := range account.Policy.Rules {
+				rulePath := fmt.Sprintf("%s.policy.rules[%d]", path, j)
+				if _, err := policy.Parse(rule.When); err != nil {
+					issues.add(rulePath+".when", "조건식 오류: %v", err)
+				}
+				if rule.Games < 0 || rule.Games > 5 {
+					issues.add(rulePath+".games", "0~5 사이여야 합니다 (%d)", rule.Games)
+				}
+			}
+			if games := account.Policy.Default(); games < 0 || games > 5 {
+				issues.add(path+".policy.defaultGames", "0~5 사이여야 합니다 (%d)", games)
+			}
+		}
+
+		if action := account.Approval.DefaultAction; action != "" && action != "buy" && action != "skip" {
+			issues.add(path+".approval.defaultAction", "\"buy\" 또는 \"skip\"이어야 합니다 (%q)", action)
+		}
+		checkDuration(&issues, path+".approval.timeout", account.Approval.Timeout, false)
+	}
+
+	checkURL(&issues, "telegramApi.baseUrl", c.TelegramAPI.BaseURL, "http", "https")
+	checkURL(&issues, "telegramApi.proxy", c.TelegramAPI.Proxy, "http", "https", "socks5")
+	checkDuration(&issues, "telegramApi.timeout", c.TelegramAPI.Timeout, false)
+
+	if c.Language != "" && !message.Supported(c.Language) {
+		issues.add("language", "지원하지 않는 언어 %q (%s 중 하나)", c.Language, strings.Join(message.Languages, ", "))
+	}
+
+	if c.TelegramCommands.Enabled {
+		if c.TelegramBotToken == "" {
+			issues.add("telegramCommands.enabled", "telegramBotToken이 필요합니다")
+		}
+		for i, id := range c.TelegramCommands.Allow {
+			if _, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64); err != nil {
+				issues.add(fmt.Sprintf("telegramCommands.allow[%d]", i), "숫자 ID여야 합니다 (%q)", id)
+			}
+		}
+		if len(c.AllowedIDs()) == 0 {
+			issues.add("telegramCommands.allow", "명령을 허용할 채팅방 또는 사용자 ID가 없습니다")
+		}
+	}
+
+	for i, interval := range c.Retry.Intervals {
+		checkDuration(&issues, fmt.Sprintf("retry.intervals[%d]", i), interval, false)
+	}
+	checkDuration(&issues, "retry.deadlineMargin", c.Retry.DeadlineMargin, true)
+	checkDuration(&issues, "catchUpWindow", c.CatchUpWindow, true)
+
+	issues = append(issues, c.validateSecrets()...)
+	issues = append(issues, c.validateSchedules()...)
+	issues = append(issues, c.validateNotify()...)
+	return issues
+}
+
+// Validate는 설정 값을 검증합니다 (설정 마법사처럼 파일 없이 만든 설정용)
+func (c *Config) Validate() error {
+	if issues := c.validate(); len(issues) > 0 {
+		return &ValidationError{Issues: issues}
+	}
+	c.bind()
+	return nil
+}
+
+// checkURL은 주소가 허용된 스킴의 URL인지 검증합니다 (빈 값은 기본값 사용)
+func checkURL(issues *issueList, path, value string, schemes ...string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || !slices.Contains(schemes, u.Scheme) {
+		issues.add(path, "%s 주소여야 합니다 (%q)", strings.Join(schemes, ", "), value)
+	}
+}
+
+// checkDuration은 기간 문자열(예: "30m")을 검증합니다 (빈 값은 기본값 사용)
+func checkDuration(issues *issueList, path, value string, allowZero bool) {
+	if value == "" {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		issues.add(path, "기간 형식 오류 %q (예: 30m, 2h)", value)
+		return
+	}
+	if d < 0 || d == 0 && !allowZero {
+		issues.add(path, "0보다 커야 합니다 (%s)", value)
+	}
+}
@@ -0,0 +1,232 @@
+package datadir
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 백업 파일 형식 버전
+const backupVersion = 1
+
+// 백업 파일 내부 경로
+const (
+	manifestName = "manifest.json"
+	dataPrefix   = "data/"
+	configPrefix = "config/"
+)
+
+// Manifest는 백업 파일의 내용 목록입니다
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	DataDir   string    `json:"dataDir"` // 백업한 서버의 데이터 디렉토리 (참고용)
+	Files     []string  `json:"files"`
+}
+
+// BackupOptions는 백업 대상 설정입니다
+type BackupOptions struct {
+	IncludeLogs bool     // 실행 로그 포함 여부 (기본 제외)
+	ConfigFiles []string // 함께 보관할 설정 파일, 금고 파일 등 (파일 이름만 보관)
+}
+
+// Backup은 데이터 디렉토리와 설정 파일을 tar.gz 파일 하나로 씁니다
+func Backup(w io.Writer, opts BackupOptions) (*Manifest, error) {
+	type entry struct {
+		name string // 백업 파일 내부 경로
+		src  string
+	}
+	var entries []entry
+
+	for _, sub := range Subdirs {
+		if sub == Logs && !opts.IncludeLogs {
+			continue
+		}
+		root := Path(sub)
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(Dir(), p)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry{name: dataPrefix + filepath.ToSlash(rel), src: p})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("데이터 디렉토리 읽기 실패: %w", err)
+		}
+	}
+
+	for _, file := range opts.ConfigFiles {
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+		entries = append(entries, entry{name: configPrefix + filepath.Base(file), src: file})
+	}
+
+	manifest := &Manifest{Version: backupVersion, CreatedAt: time.Now(), DataDir: Dir()}
+	for _, e := range entries {
+		manifest.Files = append(manifest.Files, e.name)
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("JSON 마샬링 실패: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := writeTarFile(tw, manifestName, manifestData, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(e.src)
+		if err != nil {
+			return nil, fmt.Errorf("파일 읽기 실패: %w", err)
+		}
+		info, err := os.Stat(e.src)
+		if err != nil {
+			return nil, fmt.Errorf("파일 읽기 실패: %w", err)
+		}
+		if err := writeTarFile(tw, e.name, data, info.ModTime()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("백업 파일 쓰기 실패: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("백업 파일 쓰기 실패: %w", err)
+	}
+	return manifest, nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("백업 파일 쓰기 실패: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("백업 파일 쓰기 실패: %w", err)
+	}
+	return nil
+}
+
+// Restore는 백업 파일을 데이터 디렉토리와 configDir에 풉니다
+// force가 false이면 이미 있는 파일을 덮어쓰지 않고 오류를 반환합니다 (아무 파일도 쓰지 않음)
+func Restore(r io.Reader, configDir string, force bool) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("백업 파일 형식 오류: %w", err)
+	}
+	defer gz.Close()
+
+	var manifest *Manifest
+	files := make(map[string][]byte)
+	var order []string
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("백업 파일 읽기 실패: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("백업 파일 읽기 실패: %w", err)
+		}
+		if header.Name == manifestName {
+			manifest = &Manifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, fmt.Errorf("백업 목록 파싱 실패: %w", err)
+			}
+			continue
+		}
+		files[header.Name] = data
+		order = append(order, header.Name)
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("dhlottery 백업 파일이 아닙니다 (%s 없음)", manifestName)
+	}
+	if manifest.Version != backupVersion {
+		return nil, fmt.Errorf("지원하지 않는 백업 파일 버전입니다 (%d)", manifest.Version)
+	}
+
+	// 대상 경로 계산 (경로 조작 방지)
+	targets := make(map[string]string, len(order))
+	var conflicts []string
+	for _, name := range order {
+		target, err := restoreTarget(name, configDir)
+		if err != nil {
+			return nil, err
+		}
+		targets[name] = target
+		if _, err := os.Stat(target); err == nil {
+			conflicts = append(conflicts, target)
+		}
+	}
+	if len(conflicts) > 0 && !force {
+		return nil, fmt.Errorf("이미 있는 파일 %d개를 덮어쓰지 않았습니다 (-force로 덮어쓰기): %s", len(conflicts), strings.Join(conflicts, ", "))
+	}
+
+	for _, name := range order {
+		target := targets[name]
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return nil, fmt.Errorf("디렉토리 생성 실패: %w", err)
+		}
+		if err := os.WriteFile(target, files[name], 0600); err != nil {
+			return nil, fmt.Errorf("파일 복원 실패: %w", err)
+		}
+	}
+	return manifest, nil
+}
+
+// restoreTarget은 백업 파일 내부 경로를 복원할 파일 경로로 바꿉니다
+func restoreTarget(name, configDir string) (string, error) {
+	clean := path.Clean(name)
+	if path.IsAbs(clean) || strings.HasPrefix(clean, "../") || clean == ".." {
+		return "", fmt.Errorf("백업 파일에 잘못된 경로가 있습니다: %s", name)
+	}
+
+	switch {
+	case strings.HasPrefix(clean, dataPrefix):
+		return filepath.Join(Dir(), filepath.FromSlash(strings.TrimPrefix(clean, dataPrefix))), nil
+	case strings.HasPrefix(clean, configPrefix):
+		base := strings.TrimPrefix(clean, configPrefix)
+		if strings.Contains(base, "/") {
+			return "", fmt.Errorf("백업 파일에 잘못된 경로가 있습니다: %s", name)
+		}
+		return filepath.Join(configDir, base), nil
+	}
+	return "", fmt.Errorf("백업 파일에 알 수 없는 항목이 있습니다: %s", name)
+}
@@ -0,0 +1,187 @@
+package datadir
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// 데이터 디렉토리 하위 폴더
+const (
+	Logs      = "logs"      // 실행 로그 (lottery_YYYY-MM-DD.log)
+	History   = "history"   // 구매 내역 (last_purchase.json, round_<회차>.json)
+	State     = "state"     // 스케줄러 실행 기록, 일시정지 상태, 마지막 예치금, 알림 전송 기록
+	Secrets   = "secrets"   // 암호화된 비밀번호 금고 (vault.enc)
+	Receipts  = "receipts"  // 회차별 구매 응답 원본
+	Outbox    = "outbox"    // 전송 대기 중인 알림 (pending, sending, dead)
+	Templates = "templates" // 사용자 메시지 템플릿 (<언어>/<이벤트>.<대상>.tmpl)
+)
+
+// Subdirs는 데이터 디렉토리의 하위 폴더 목록입니다
+var Subdirs = []string{Logs, History, State, Secrets, Receipts, Outbox, Templates}
+
+// EnvVar는 데이터 디렉토리를 지정하는 환경변수입니다
+const EnvVar = "DH_DATA_DIR"
+
+const appName = "dhlottery"
+
+// legacyDir는 데이터 디렉토리 도입 전 현재 디렉토리에 만들던 폴더입니다
+const legacyDir = "logs"
+
+// dir는 Init으로 정한 데이터 디렉토리입니다
+var dir string
+
+// Default는 기본 데이터 디렉토리를 반환합니다
+//
+//	Linux 등: $XDG_DATA_HOME/dhlottery (기본 ~/.local/share/dhlottery)
+//	macOS: ~/Library/Application Support/dhlottery
+//	Windows: %LOCALAPPDATA%\dhlottery
+func Default() string {
+	switch runtime.GOOS {
+	case "windows":
+		if base := os.Getenv("LOCALAPPDATA"); base != "" {
+			return filepath.Join(base, appName)
+		}
+	case "darwin":
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, "Library", "Application Support", appName)
+		}
+	default:
+		if base := os.Getenv("XDG_DATA_HOME"); base != "" {
+			return filepath.Join(base, appName)
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, ".local", "share", appName)
+		}
+	}
+	// 홈 디렉토리를 알 수 없으면 현재 디렉토리
+	return appName + "-data"
+}
+
+// Resolve는 사용할 데이터 디렉토리를 정합니다 (플래그 → DH_DATA_DIR → 설정 파일 → 기본값)
+func Resolve(flagValue, configValue string) string {
+	for _, value := range []string{flagValue, os.Getenv(EnvVar), configValue} {
+		if value != "" {
+			return value
+		}
+	}
+	return Default()
+}
+
+// Init은 데이터 디렉토리와 하위 폴더를 만듭니다
+// 새로 만든 경우 현재 디렉토리의 이전 logs 폴더에 있던 구매 내역과 상태 파일을 복사합니다
+func Init(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("데이터 디렉토리 경로 오류: %w", err)
+	}
+
+	_, statErr := os.Stat(abs)
+	created := os.IsNotExist(statErr)
+
+	for _, sub := range Subdirs {
+		if err := os.MkdirAll(filepath.Join(abs, sub), 0700); err != nil {
+			return fmt.Errorf("데이터 디렉토리 생성 실패: %w", err)
+		}
+	}
+	dir = abs
+
+	if created {
+		migrateLegacy()
+	}
+	return nil
+}
+
+// Dir은 데이터 디렉토리를 반환합니다 (Init 전이면 기본값)
+func Dir() string {
+	if dir == "" {
+		return Default()
+	}
+	return dir
+}
+
+// Path는 데이터 디렉토리 하위 폴더의 경로를 반환합니다 (예: Path(History, "last_purchase.json"))
+func Path(sub string, elem ...string) string {
+	return filepath.Join(append([]string{Dir(), sub}, elem...)...)
+}
+
+// legacyFiles는 이전 logs 폴더의 파일별 새 위치입니다
+var legacyFiles = map[string]string{
+	"last_purchase.json":   History,
+	"dryrun_purchase.json": History,
+	"scheduler_state.json": State,
+	"pause_state.json":     State,
+}
+
+// migrateLegacy는 현재 디렉토리의 logs 폴더에서 구매 내역과 상태 파일을 복사합니다 (원본은 그대로 둠)
+func migrateLegacy() {
+	if abs, err := filepath.Abs(legacyDir); err != nil || abs == filepath.Join(dir, Logs) {
+		return
+	}
+
+	copied := 0
+	for name, sub := range legacyFiles {
+		if copyIfExists(filepath.Join(legacyDir, name), Path(sub, name)) {
+			copied++
+		}
+	}
+	rounds, _ := filepath.Glob(filepath.Join(legacyDir, "history", "round_*.json"))
+	for _, src := range rounds {
+		if copyIfExists(src, Path(History, filepath.Base(src))) {
+			copied++
+		}
+	}
+
+	if copied > 0 {
+		log.Printf("📦 이전 위치(./%s)의 구매 내역과 상태 파일 %d개를 데이터 디렉토리로 복사했습니다: %s\n", legacyDir, copied, dir)
+	}
+}
+
+func copyIfExists(src, dst string) bool {
+	in, err := os.Open(src)
+	if err != nil {
+		return false
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return false
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err == nil
+}
+
+// Contains는 path가 데이터 디렉토리 안에 있는지 확인합니다 (백업에 이미 포함되는 파일인지 판단)
+func Contains(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(Dir(), abs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// WriteFile은 데이터 디렉토리의 파일을 임시 파일에 쓴 뒤 이름을 바꿔 저장합니다 (권한 0600)
+// 쓰는 중에 중단되어도 기존 파일이 깨지지 않으며, 상위 폴더가 없으면 만듭니다
+func WriteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
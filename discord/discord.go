@@ -0,0 +1,140 @@
+// Package discord는 디스코드 웹훅으로 임베드 메시지를 보냅니다
+//
+// 웹훅은 채널 설정의 연동 > 웹후크에서 만들며 (https://discord.com/api/webhooks/...),
+// 사진은 올리지 않고 텍스트와 임베드만 보냅니다.
+package discord
+
+import (
+	"dhlottery/webhook"
+	"time"
+)
+
+// Channel은 알림 채널 이름입니다 (보관함, 로그 표시용)
+const Channel = "discord"
+
+// 임베드 길이 제한 (넘으면 잘라서 보냄)
+const (
+	MaxContentLength     = 2000 // 메시지 본문
+	MaxTitleLength       = 256  // 임베드 제목
+	MaxDescriptionLength = 4096 // 임베드 설명
+	MaxFields            = 25   // 임베드 항목 수
+	MaxFieldNameLength   = 256  // 항목 이름
+	MaxFieldValueLength  = 1024 // 항목 값
+	MaxFooterLength      = 2048 // 꼬리말
+)
+
+// 임베드 색 (왼쪽 막대)
+const (
+	ColorInfo     = 0x2ECC71 // 초록
+	ColorWarning  = 0xE67E22 // 주황
+	ColorError    = 0xE74C3C // 빨강
+	ColorCritical = 0xF1C40F // 금색 (고액 당첨)
+)
+
+// FlagSuppressNotifications는 알림 소리 없이 보내는 메시지 플래그입니다 (@silent)
+const FlagSuppressNotifications = 1 << 12
+
+// Profile은 웹훅 메시지를 보내는 사람 표시입니다 (비우면 웹훅에 설정한 이름과 아이콘)
+type Profile struct {
+	Username  string
+	AvatarURL string
+}
+
+// Payload는 웹훅으로 보내는 메시지입니다
+type Payload struct {
+	Content   string  `json:"content,omitempty"`
+	Username  string  `json:"username,omitempty"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Embeds    []Embed `json:"embeds,omitempty"`
+	Flags     int     `json:"flags,omitempty"`
+}
+
+// Embed는 제목, 설명, 항목, 색이 있는 카드입니다
+type Embed struct {
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Color       int     `json:"color,omitempty"`
+	Fields      []Field `json:"fields,omitempty"`
+	Footer      *Footer `json:"footer,omitempty"`
+	Timestamp   string  `json:"timestamp,omitempty"` // RFC 3339
+}
+
+// Field는 임베드 항목입니다 (Inline이면 한 줄에 여러 개)
+type Field struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// Footer는 임베드 꼬리말입니다
+type Footer struct {
+	Text string `json:"text"`
+}
+
+// Webhook은 디스코드 웹훅입니다
+type Webhook struct {
+	hook    *webhook.Hook
+	profile Profile
+}
+
+// New는 웹훅 주소로 디스코드 웹훅을 생성합니다 (queue가 nil이면 바로 전송)
+func New(url string, profile Profile, queue webhook.Queue) *Webhook {
+	return &Webhook{hook: webhook.New(Channel, url, queue), profile: profile}
+}
+
+// URL은 웹훅 주소입니다
+func (w *Webhook) URL() string {
+	return w.hook.URL
+}
+
+// Send는 메시지를 바로 보냅니다
+func (w *Webhook) Send(p Payload) error {
+	return w.hook.Send(w.prepare(p))
+}
+
+// SendSafe는 메시지를 보관함을 거쳐 보내고 에러를 로그로 출력합니다 (notBefore 이후에 전송)
+func (w *Webhook) SendSafe(p Payload, notBefore time.Time) {
+	w.hook.SendSafe(w.prepare(p), notBefore)
+}
+
+// prepare는 보내는 사람 표시를 채우고 길이 제한을 넘는 부분을 자릅니다
+func (w *Webhook) prepare(p Payload) Payload {
+	if p.Username == "" {
+		p.Username = w.profile.Username
+	}
+	if p.AvatarURL == "" {
+		p.AvatarURL = w.profile.AvatarURL
+	}
+	p.Content = truncate(p.Content, MaxContentLength)
+
+	embeds := make([]Embed, len(p.Embeds))
+	for i, e := range p.Embeds {
+		e.Title = truncate(e.Title, MaxTitleLength)
+		e.Description = truncate(e.Description, MaxDescriptionLength)
+		if len(e.Fields) > MaxFields {
+			e.Fields = e.Fields[:MaxFields]
+		}
+		fields := make([]Field, len(e.Fields))
+		for j, f := range e.Fields {
+			f.Name = truncate(f.Name, MaxFieldNameLength)
+			f.Value = truncate(f.Value, MaxFieldValueLength)
+			fields[j] = f
+		}
+		e.Fields = fields
+		if e.Footer != nil {
+			e.Footer = &Footer{Text: truncate(e.Footer.Text, MaxFooterLength)}
+		}
+		embeds[i] = e
+	}
+	p.Embeds = embeds
+	return p
+}
+
+// truncate는 문자열을 최대 n글자로 자릅니다
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -4,8 +4,15 @@ go 1.24.0
 
 require (
 	github.com/PuerkitoBio/goquery v1.10.1
+	github.com/pelletier/go-toml/v2 v2.4.3
 	github.com/robfig/cron/v3 v3.0.1
+	golang.org/x/crypto v0.36.0
 	golang.org/x/net v0.33.0
+	golang.org/x/term v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require github.com/andybalholm/cascadia v1.3.3 // indirect
+require (
+	github.com/andybalholm/cascadia v1.3.3 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+)
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bufio"
+	"dhlottery/config"
+	"dhlottery/lottery"
+	"dhlottery/telegram"
+	"dhlottery/vault"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// 텔레그램 채팅방 자동 감지 대기 시간
+const chatDetectTimeout = 2 * time.Minute
+
+// runInitCommand는 설정 마법사를 실행합니다 (설정 로드 전에 실행)
+// 계정을 추가하고 로그인과 예치금 조회로 확인한 뒤, 텔레그램 알림을 설정하고 설정 파일을 저장합니다
+//
+//	dhlottery [-config 경로] init [-skip-verify]
+func runInitCommand(configPath string, args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	skipVerify := fs.Bool("skip-verify", false, "계정 로그인 확인 생략")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	file := config.FindFile(configPath)
+	if file == "" {
+		file = "config.yaml"
+	}
+	format, err := config.FormatOf(file)
+	if err != nil {
+		return err
+	}
+
+	var cfg config.Config
+	_, statErr := os.Stat(file)
+	exists := statErr == nil
+	if exists {
+		if cfg, err = config.ReadFile(file); err != nil {
+			return fmt.Errorf("기존 설정 파일을 읽을 수 없습니다 (dhlottery config validate로 확인): %w", err)
+		}
+	}
+
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	log.Println("🧙 설정 마법사")
+	if exists {
+		log.Printf("   기존 설정 파일에 계정을 추가합니다: %s (등록된 계정 %d개)\n", file, len(cfg.Accounts))
+	} else {
+		log.Printf("   새 설정 파일을 만듭니다: %s\n", file)
+	}
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	in := newPrompter()
+
+	// 1. 계정
+	passwords := addAccounts(in, &cfg, !*skipVerify)
+	if len(cfg.Accounts) == 0 {
+		return fmt.Errorf("등록된 계정이 없어 설정을 저장하지 않았습니다")
+	}
+
+	// 2. 비밀번호 저장 방식
+	if len(passwords) > 0 {
+		if err := storePasswords(in, &cfg, passwords); err != nil {
+			return err
+		}
+	}
+
+	// 3. 텔레그램 알림
+	if in.confirm("\n텔레그램 알림을 설정할까요?", cfg.TelegramBotToken == "") {
+		setupTelegram(in, &cfg)
+	}
+
+	// 4. 검증 후 저장
+	if err := cfg.Validate(); err != nil {
+		var verr *config.ValidationError
+		if errors.As(err, &verr) {
+			for _, issue := range verr.Issues {
+				log.Printf("❌ %s\n", issue)
+			}
+		}
+		return fmt.Errorf("설정 검증에 실패하여 저장하지 않았습니다")
+	}
+
+	data, err := cfg.Marshal(format)
+	if err != nil {
+		return err
+	}
+	if exists {
+		backup := file + ".bak"
+		if err := copyFile(file, backup); err != nil {
+			return fmt.Errorf("기존 설정 파일 백업 실패: %w", err)
+		}
+		log.Printf("💾 기존 설정 파일 백업: %s\n", backup)
+	}
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return fmt.Errorf("설정 파일 저장 실패: %w", err)
+	}
+
+	log.Println()
+	log.Printf("✅ 설정 파일을 저장했습니다: %s (계정 %d개)\n", file, len(cfg.Accounts))
+	log.Println("   dhlottery config show -redacted 로 내용을 확인할 수 있습니다")
+	return nil
+}
+
+// addAccounts는 계정을 입력받아 설정에 추가하고, 새로 입력한 비밀번호를 아이디별로 반환합니다
+func addAccounts(in *prompter, cfg *config.Config, verify bool) map[string]string {
+	passwords := make(map[string]string)
+
+	for {
+		log.Println()
+		userID := in.ask("동행복권 아이디 (입력을 마치려면 Enter)", "")
+		if userID == "" {
+			return passwords
+		}
+
+		index := -1
+		for i, account := range cfg.Accounts {
+			if account.UserID == userID {
+				index = i
+			}
+		}
+		if index >= 0 && !in.confirm(fmt.Sprintf("%s는 이미 등록된 계정입니다. 비밀번호를 바꿀까요?", userID), false) {
+			continue
+		}
+
+		password := in.secret("비밀번호: ")
+		if password == "" {
+			log.Println("⚠️  비밀번호가 비어 있어 건너뜁니다")
+			continue
+		}
+
+		if verify {
+			log.Printf("🔑 %s 로그인 확인 중...\n", userID)
+			balance, err := verifyAccount(userID, password)
+			if err != nil {
+				log.Printf("❌ 계정 확인 실패: %v\n", err)
+				if !in.confirm("그래도 이 계정을 저장할까요?", false) {
+					continue
+				}
+			} else {
+				log.Printf("✅ 로그인 성공 (예치금 %s원)\n", lottery.FormatMoney(balance))
+			}
+		}
+
+		if index < 0 {
+			cfg.Accounts = append(cfg.Accounts, config.Account{UserID: userID})
+			log.Printf("➕ 계정 추가: %s\n", userID)
+		}
+		passwords[userID] = password
+	}
+}
+
+// verifyAccount는 로그인과 예치금 조회로 계정을 확인합니다
+func verifyAccount(userID, password string) (int, error) {
+	client, err := lottery.NewClient(userID, password)
+	if err != nil {
+		return 0, fmt.Errorf("클라이언트 생성 실패: %w", err)
+	}
+	if err := client.Login(); err != nil {
+		return 0, fmt.Errorf("로그인 실패: %w", err)
+	}
+	balance, err := client.CheckBalance()
+	if err != nil {
+		return 0, fmt.Errorf("예치금 조회 실패: %w", err)
+	}
+	return balance, nil
+}
+
+// storePasswords는 새로 입력한 비밀번호를 금고 또는 설정 파일에 저장합니다
+func storePasswords(in *prompter, cfg *config.Config, passwords map[string]string) error {
+	log.Println()
+	choice := in.choose("비밀번호 저장 방식", []string{
+		"암호화된 금고에 저장 (권장)",
+		"설정 파일에 평문으로 저장",
+	}, 0)
+
+	if choice == 1 {
+		for i, account := range cfg.Accounts {
+			if password, ok := passwords[account.UserID]; ok {
+				cfg.Accounts[i].Password = password
+				cfg.Accounts[i].PasswordFile, cfg.Accounts[i].PasswordCommand = "", ""
+			}
+		}
+		return nil
+	}
+
+	path := cfg.Vault.FilePath()
+	var v *vault.Vault
+	var err error
+	if vault.Exists(path) {
+		log.Printf("🔐 기존 금고를 엽니다: %s\n", path)
+		v, err = cfg.Vault.OpenVault()
+	} else {
+		if cfg.Vault.KeyFile == "" && in.choose("금고 잠금 방식", []string{
+			"암호 (실행할 때 DH_VAULT_PASSPHRASE 환경변수 또는 입력 필요)",
+			"키 파일 (vault.key, 무인 실행에 편리)",
+		}, 0) == 1 {
+			cfg.Vault.KeyFile = "vault.key"
+		}
+		var key vault.Key
+		if key, err = newVaultKey(cfg.Vault.KeyFile); err == nil {
+			v, err = vault.Create(path, key)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, account := range cfg.Accounts {
+		password, ok := passwords[account.UserID]
+		if !ok {
+			continue
+		}
+		v.Set(account.UserID, password)
+		// 금고에서 찾도록 다른 비밀번호 출처는 비움
+		cfg.Accounts[i].Password, cfg.Accounts[i].PasswordFile, cfg.Accounts[i].PasswordCommand = "", "", ""
+	}
+	if err := v.Save(); err != nil {
+		return err
+	}
+	log.Printf("✅ 비밀번호 %d개를 금고에 저장했습니다 (%s)\n", len(passwords), path)
+	return nil
+}
+
+// setupTelegram은 봇 토큰을 확인하고, 봇에게 온 메시지로 채팅 ID를 찾아 테스트 메시지를 보냅니다
+func setupTelegram(in *prompter, cfg *config.Config) {
+	var bot *telegram.Bot
+	var botName string
+	for {
+		token := in.secret("봇 토큰 (@BotFather에서 발급, 건너뛰려면 Enter): ")
+		if token == "" {
+			return
+		}
+
+		bot = telegram.New(token, "")
+		name, err := bot.GetMe()
+		if err == nil {
+			botName = name
+			log.Printf("✅ 봇 확인: @%s\n", botName)
+			break
+		}
+		log.Printf("❌ 봇 토큰 확인 실패: %v\n", err)
+		if !in.confirm("다시 입력할까요?", true) {
+			return
+		}
+	}
+
+	log.Printf("👉 텔레그램에서 @%s 에게 아무 메시지나 보내세요 (그룹으로 받으려면 봇을 초대한 뒤 그룹에 메시지 전송)\n", botName)
+	log.Printf("   %v 동안 기다립니다...\n", chatDetectTimeout)
+
+	chatID := ""
+	chat, ok, err := bot.WaitForChat(chatDetectTimeout)
+	switch {
+	case err != nil:
+		log.Printf("⚠️  채팅방 찾기 실패: %v\n", err)
+	case !ok:
+		log.Println("⏰ 메시지가 오지 않았습니다")
+	case in.confirm(fmt.Sprintf("채팅방 %q (%s, ID %s)으로 알림을 보낼까요?", chat.Name(), chat.Type, chat.IDString()), true):
+		chatID = chat.IDString()
+	}
+	if chatID == "" {
+		if chatID = in.ask("채팅 ID를 직접 입력하세요 (건너뛰려면 Enter)", cfg.TelegramChatID); chatID == "" {
+			return
+		}
+	}
+
+	bot = telegram.New(bot.Token, chatID)
+	err = bot.SendMessage("✅ <b>동행복권 자동 구매 알림 설정 완료</b>\n\n이 채팅방으로 구매 결과와 당첨 결과가 전송됩니다.")
+	if err != nil {
+		log.Printf("❌ 테스트 메시지 전송 실패: %v\n", err)
+		if !in.confirm("그래도 이 설정을 저장할까요?", false) {
+			return
+		}
+	} else if !in.confirm("테스트 메시지를 받았나요?", true) {
+		return
+	}
+
+	cfg.TelegramBotToken, cfg.TelegramChatID = bot.Token, chatID
+	log.Println("✅ 텔레그램 알림 설정 완료 (토큰은 설정 파일에서 ${TELEGRAM_BOT_TOKEN}으로 바꿔 환경변수로 관리할 수 있습니다)")
+}
+
+// copyFile은 파일을 복사합니다 (권한 0600)
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0600)
+}
+
+// prompter는 설정 마법사의 입력을 처리합니다 (표준 입력을 하나의 버퍼로 읽음)
+type prompter struct {
+	reader   *bufio.Reader
+	terminal bool
+}
+
+func newPrompter() *prompter {
+	return &prompter{
+		reader:   bufio.NewReader(os.Stdin),
+		terminal: term.IsTerminal(int(os.Stdin.Fd())),
+	}
+}
+
+// readLine은 한 줄을 읽습니다 (입력이 끝났으면 빈 문자열)
+func (p *prompter) readLine() string {
+	line, err := p.reader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		fmt.Println()
+	}
+	return strings.TrimSpace(line)
+}
+
+// ask는 값을 입력받습니다 (빈 입력이면 기본값)
+func (p *prompter) ask(label, def string) string {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", label, def)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+	if value := p.readLine(); value != "" {
+		return value
+	}
+	return def
+}
+
+// secret은 화면에 표시하지 않고 비밀 값을 입력받습니다
+func (p *prompter) secret(label string) string {
+	if !p.terminal {
+		fmt.Print(label)
+		return p.readLine()
+	}
+	value, err := config.ReadSecret(label)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+// confirm은 예/아니오를 입력받습니다
+func (p *prompter) confirm(label string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	fmt.Printf("%s (%s): ", label, hint)
+
+	switch strings.ToLower(p.readLine()) {
+	case "y", "yes", "예", "ㅇ":
+		return true
+	case "n", "no", "아니오", "ㄴ":
+		return false
+	default:
+		return def
+	}
+}
+
+// choose는 선택지 중 하나를 입력받아 인덱스를 반환합니다 (잘못된 입력이면 기본값)
+func (p *prompter) choose(label string, options []string, def int) int {
+	fmt.Printf("%s:\n", label)
+	for i, option := range options {
+		fmt.Printf("  %d) %s\n", i+1, option)
+	}
+	fmt.Printf("선택 [%d]: ", def+1)
+
+	n, err := strconv.Atoi(p.readLine())
+	if err != nil || n < 1 || n > len(options) {
+		return def
+	}
+	return n - 1
+}
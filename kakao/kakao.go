@@ -0,0 +1,387 @@
+// Package kakao는 카카오톡 "나에게 보내기"(메시지 API의 나에게 보내기)로 알림을 보냅니다
+//
+// 카카오 디벨로퍼스에서 앱을 만들고 카카오 로그인과 동의 항목 talk_message를 켠 뒤,
+// 받을 사람마다 한 번 로그인해 받은 리프레시 토큰을 설정합니다 (dhlottery kakao login).
+// 액세스 토큰은 몇 시간마다 만료되므로 리프레시 토큰으로 새로 받고, 갱신하면서 리프레시 토큰이
+// 바뀌면(회전) 상태 디렉토리의 kakao_tokens.json에 저장해 다음 실행부터 새 토큰을 씁니다.
+// 보관함(outbox)과 로그에는 토큰 대신 Key만 남깁니다.
+package kakao
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Channel은 알림 채널 이름입니다 (보관함, 로그 표시용)
+const Channel = "kakao"
+
+// 기본 API 주소 (테스트할 때는 설정의 apiUrl, authUrl로 바꿈)
+const (
+	DefaultAPIURL  = "https://kapi.kakao.com"
+	DefaultAuthURL = "https://kauth.kakao.com"
+)
+
+// 요청 설정
+const (
+	memoPath       = "/v2/api/talk/memo/default/send"
+	tokenPath      = "/oauth/token"
+	authorizePath  = "/oauth/authorize"
+	requestTimeout = 15 * time.Second
+	refreshMargin  = 5 * time.Minute // 만료까지 이보다 적게 남은 액세스 토큰은 미리 갱신
+	maxErrorBody   = 200             // 오류 메시지에 넣을 응답 본문 길이
+)
+
+// Scope는 나에게 보내기에 필요한 동의 항목입니다
+const Scope = "talk_message"
+
+// client는 카카오 API 요청에 쓰는 HTTP 클라이언트입니다 (프록시는 HTTPS_PROXY 환경변수)
+var client = &http.Client{Timeout: requestTimeout}
+
+// Queue는 카카오톡 알림을 바로 보내지 않고 맡아 두었다가 전달하는 보관함입니다 (outbox)
+// target에는 리프레시 토큰 대신 Key를 넣습니다
+type Queue interface {
+	EnqueueChannel(channel, target string, payload []byte, notBefore time.Time) error
+}
+
+// Options는 카카오 앱 설정입니다
+type Options struct {
+	ClientID     string // REST API 키
+	ClientSecret string // 보안 설정에서 Client Secret을 켰을 때만
+	APIURL       string // 비우면 DefaultAPIURL
+	AuthURL      string // 비우면 DefaultAuthURL
+}
+
+// APIError는 카카오 API가 실패 응답을 보낸 요청입니다
+type APIError struct {
+	Status  int    // HTTP 상태 코드
+	Code    string // 카카오 오류 코드 (-401, KOE322 등)
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("카카오 API 오류 (%d): %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("카카오 API 오류 (%d, %s): %s", e.Status, e.Code, e.Message)
+}
+
+// Permanent는 다시 보내도 성공할 수 없는 응답인지 반환합니다 (잘못된 템플릿, 만료된 리프레시 토큰 등, 429는 재시도)
+func (e *APIError) Permanent() bool {
+	return e.Status >= 400 && e.Status < 500 && e.Status != http.StatusTooManyRequests
+}
+
+// Client는 카카오 앱 하나로 여러 사람의 토큰을 관리하며 메시지를 보냅니다
+type Client struct {
+	opts  Options
+	mu    sync.Mutex
+	seeds map[string]string // Key → 설정 파일의 리프레시 토큰
+}
+
+// New는 클라이언트를 생성하고 설정 파일의 리프레시 토큰을 등록합니다
+func New(opts Options, refreshTokens []string) *Client {
+	if opts.APIURL == "" {
+		opts.APIURL = DefaultAPIURL
+	}
+	if opts.AuthURL == "" {
+		opts.AuthURL = DefaultAuthURL
+	}
+	c := &Client{opts: opts, seeds: map[string]string{}}
+	for _, token := range refreshTokens {
+		c.seeds[Key(token)] = token
+	}
+	return c
+}
+
+// Key는 리프레시 토큰을 가리키는 이름입니다 (보관함, 전송 제한 기록에 토큰 대신 저장)
+// 토큰이 회전해도 설정 파일의 값으로 만들므로 바뀌지 않습니다
+func Key(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return Channel + ":" + hex.EncodeToString(sum[:6])
+}
+
+// Post는 템플릿 JSON을 key의 사용자에게 보냅니다
+// 액세스 토큰이 만료됐거나 곧 만료되면 먼저 갱신하고, 401 응답을 받으면 한 번 갱신해 다시 보냅니다
+func (c *Client) Post(key string, payload []byte) error {
+	token, err := c.accessToken(key, false)
+	if err != nil {
+		return err
+	}
+	err = c.send(token, payload)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusUnauthorized {
+		log.Printf("🔑 카카오 액세스 토큰이 거부되어 다시 발급받습니다 (%s)\n", key)
+		if token, err = c.accessToken(key, true); err != nil {
+			return err
+		}
+		err = c.send(token, payload)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ 카카오톡 메시지 전송 완료\n")
+	return nil
+}
+
+// send는 나에게 보내기 요청을 한 번 보냅니다
+func (c *Client) send(accessToken string, payload []byte) error {
+	form := url.Values{"template_object": {string(payload)}}
+	status, body, err := c.postForm(c.opts.APIURL+memoPath, form, accessToken)
+	if err != nil {
+		return fmt.Errorf("카카오톡 메시지 전송 실패: %w", err)
+	}
+	if status/100 != 2 {
+		return apiError(status, body)
+	}
+	return nil
+}
+
+// accessToken은 key의 쓸 수 있는 액세스 토큰을 반환합니다 (force면 남은 시간과 관계없이 갱신)
+func (c *Client) accessToken(key string, force bool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seed, ok := c.seeds[key]
+	if !ok {
+		return "", fmt.Errorf("설정에 없는 카카오 토큰입니다 (%s)", key)
+	}
+	tokens, err := loadTokens()
+	if err != nil {
+		return "", err
+	}
+
+	token, ok := tokens[key]
+	if !ok {
+		token = Token{RefreshToken: seed}
+	}
+	now := time.Now()
+	if !force && token.valid(now, refreshMargin) {
+		return token.AccessToken, nil
+	}
+
+	refreshed, err := c.refresh(token.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+	token.AccessToken = refreshed.AccessToken
+	token.ExpiresAt = now.Add(time.Duration(refreshed.ExpiresIn) * time.Second)
+	if refreshed.RefreshToken != "" {
+		log.Printf("🔑 카카오 리프레시 토큰이 갱신되어 저장합니다 (%s)\n", key)
+		token.RefreshToken = refreshed.RefreshToken
+	}
+	if refreshed.RefreshTokenExpiresIn > 0 {
+		token.RefreshExpiresAt = now.Add(time.Duration(refreshed.RefreshTokenExpiresIn) * time.Second)
+	}
+
+	tokens[key] = token
+	if err := saveTokens(tokens); err != nil {
+		// 액세스 토큰은 이번 전송에 쓸 수 있지만, 회전한 리프레시 토큰을 잃으면 다시 로그인해야 함
+		log.Printf("⚠️  %v\n", err)
+	}
+	return token.AccessToken, nil
+}
+
+// tokenResponse는 토큰 발급 응답입니다 (리프레시 토큰은 새로 발급될 때만 포함)
+type tokenResponse struct {
+	AccessToken           string `json:"access_token"`
+	ExpiresIn             int    `json:"expires_in"`
+	RefreshToken          string `json:"refresh_token"`
+	RefreshTokenExpiresIn int    `json:"refresh_token_expires_in"`
+}
+
+// refresh는 리프레시 토큰으로 액세스 토큰을 새로 받습니다
+func (c *Client) refresh(refreshToken string) (tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+	token, err := c.token(form)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("카카오 토큰 갱신 실패: %w", err)
+	}
+	return token, nil
+}
+
+// AuthorizeURL은 사용자가 브라우저에서 열어 동의할 카카오 로그인 주소입니다
+func (c *Client) AuthorizeURL(redirectURI string) string {
+	query := url.Values{
+		"client_id":     {c.opts.ClientID},
+		"redirect_uri":  {redirectURI},
+		"response_type": {"code"},
+		"scope":         {Scope},
+	}
+	return c.opts.AuthURL + authorizePath + "?" + query.Encode()
+}
+
+// Login은 카카오 로그인으로 받은 인가 코드를 토큰으로 바꾸고 저장한 뒤 리프레시 토큰을 반환합니다
+// 반환한 리프레시 토큰을 설정 파일에 넣으면 저장된 토큰을 이어서 씁니다
+func (c *Client) Login(code, redirectURI string) (string, error) {
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"redirect_uri": {redirectURI},
+		"code":         {code},
+	}
+	resp, err := c.token(form)
+	if err != nil {
+		return "", fmt.Errorf("카카오 토큰 발급 실패: %w", err)
+	}
+	if resp.RefreshToken == "" {
+		return "", fmt.Errorf("카카오 토큰 발급 실패: 응답에 리프레시 토큰이 없습니다")
+	}
+
+	now := time.Now()
+	token := Token{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		ExpiresAt:    now.Add(time.Duration(resp.ExpiresIn) * time.Second),
+	}
+	if resp.RefreshTokenExpiresIn > 0 {
+		token.RefreshExpiresAt = now.Add(time.Duration(resp.RefreshTokenExpiresIn) * time.Second)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tokens, err := loadTokens()
+	if err != nil {
+		return "", err
+	}
+	key := Key(resp.RefreshToken)
+	tokens[key] = token
+	c.seeds[key] = resp.RefreshToken
+	if err := saveTokens(tokens); err != nil {
+		return "", err
+	}
+	return resp.RefreshToken, nil
+}
+
+// token은 토큰 발급 요청을 보냅니다 (앱 키는 여기서 채움)
+func (c *Client) token(form url.Values) (tokenResponse, error) {
+	form.Set("client_id", c.opts.ClientID)
+	if c.opts.ClientSecret != "" {
+		form.Set("client_secret", c.opts.ClientSecret)
+	}
+
+	status, body, err := c.postForm(c.opts.AuthURL+tokenPath, form, "")
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	if status/100 != 2 {
+		return tokenResponse{}, apiError(status, body)
+	}
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return tokenResponse{}, fmt.Errorf("응답 파싱 실패: %w", err)
+	}
+	if token.AccessToken == "" {
+		return tokenResponse{}, fmt.Errorf("응답에 액세스 토큰이 없습니다")
+	}
+	return token, nil
+}
+
+// postForm은 폼 요청을 보내고 상태 코드와 응답 본문을 반환합니다 (accessToken이 있으면 Bearer 인증)
+func (c *Client) postForm(endpoint string, form url.Values, accessToken string) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, nil, fmt.Errorf("요청 생성 실패: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("카카오 API 호출 실패: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return resp.StatusCode, body, nil
+}
+
+// apiError는 실패 응답 본문을 APIError로 바꿉니다
+// API 서버는 {"msg", "code"}, 인증 서버는 {"error", "error_description", "error_code"} 형식입니다
+func apiError(status int, body []byte) *APIError {
+	var resp struct {
+		Msg              string      `json:"msg"`
+		Code             json.Number `json:"code"`
+		Error            string      `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+		ErrorCode        string      `json:"error_code"`
+	}
+	e := &APIError{Status: status}
+	if json.Unmarshal(body, &resp) == nil {
+		switch {
+		case resp.Msg != "":
+			e.Code, e.Message = resp.Code.String(), resp.Msg
+		case resp.Error != "":
+			e.Code, e.Message = resp.ErrorCode, resp.Error
+			if resp.ErrorDescription != "" {
+				e.Message += ": " + resp.ErrorDescription
+			}
+		}
+	}
+	if e.Message == "" {
+		e.Message = truncate(strings.TrimSpace(string(body)), maxErrorBody)
+	}
+	return e
+}
+
+// Memo는 한 사람에게 나에게 보내기로 메시지를 보냅니다
+type Memo struct {
+	client *Client
+	key    string
+	queue  Queue // 설정되어 있으면 SendSafe는 보관함을 거쳐 전송
+}
+
+// Memo는 설정 파일의 리프레시 토큰으로 받는 사람을 만듭니다 (queue가 nil이면 바로 전송)
+func (c *Client) Memo(refreshToken string, queue Queue) *Memo {
+	return &Memo{client: c, key: Key(refreshToken), queue: queue}
+}
+
+// Key는 받는 사람의 Key입니다
+func (m *Memo) Key() string {
+	return m.key
+}
+
+// Send는 메시지를 바로 보냅니다
+func (m *Memo) Send(t Template) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("JSON 마샬링 실패: %w", err)
+	}
+	return m.client.Post(m.key, data)
+}
+
+// SendSafe는 메시지를 보관함에 넣거나 (보관함이 없으면) 바로 보내고 에러를 로그로 출력합니다
+// notBefore는 보관함이 있을 때만 적용됩니다 (조용한 시간)
+func (m *Memo) SendSafe(t Template, notBefore time.Time) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		log.Printf("⚠️  %s 알림 생성 실패: %v\n", Channel, err)
+		return
+	}
+	if m.queue != nil {
+		err := m.queue.EnqueueChannel(Channel, m.key, data, notBefore)
+		if err == nil {
+			return
+		}
+		log.Printf("⚠️  알림 보관함 저장 실패, 바로 전송합니다: %v\n", err)
+	}
+	if err := m.client.Post(m.key, data); err != nil {
+		log.Printf("⚠️  %v\n", err)
+	}
+}
@@ -0,0 +1,211 @@
+package kakao
+
+import (
+	"dhlottery/datadir"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeKakao는 토큰 발급과 나에게 보내기만 흉내 내는 카카오 API입니다
+type fakeKakao struct {
+	t *testing.T
+
+	mu        sync.Mutex
+	rotate    string            // 비어 있지 않으면 다음 갱신 응답에 새 리프레시 토큰으로 넣음
+	refreshes []string          // 갱신 요청에 쓰인 리프레시 토큰
+	issued    int               // 발급한 액세스 토큰 수
+	valid     map[string]bool   // 쓸 수 있는 액세스 토큰
+	memos     []Template        // 받은 메시지
+	tokenErr  map[string]string // 리프레시 토큰별 오류 코드 (KOE322 등)
+}
+
+func newFakeKakao(t *testing.T) (*fakeKakao, *Client) {
+	t.Helper()
+	if err := datadir.Init(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeKakao{t: t, valid: map[string]bool{}, tokenErr: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc(tokenPath, f.token)
+	mux.HandleFunc(memoPath, f.memo)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	c := New(Options{ClientID: "rest-api-key", APIURL: server.URL, AuthURL: server.URL}, []string{"seed-refresh"})
+	return f, c
+}
+
+func (f *fakeKakao) token(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.FormValue("client_id") != "rest-api-key" || r.FormValue("grant_type") != "refresh_token" {
+		f.t.Errorf("잘못된 토큰 요청: %v", r.PostForm)
+	}
+	refresh := r.FormValue("refresh_token")
+	f.refreshes = append(f.refreshes, refresh)
+	if code, ok := f.tokenErr[refresh]; ok {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error":"invalid_grant","error_description":"expired or invalid refresh token","error_code":%q}`, code)
+		return
+	}
+
+	f.issued++
+	access := fmt.Sprintf("access-%d", f.issued)
+	f.valid[access] = true
+	resp := tokenResponse{AccessToken: access, ExpiresIn: 21599}
+	if f.rotate != "" {
+		resp.RefreshToken, resp.RefreshTokenExpiresIn = f.rotate, 5184000
+		f.rotate = ""
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (f *fakeKakao) memo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	access := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !f.valid[access] {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"msg":"this access token does not exist","code":-401}`)
+		return
+	}
+	var t Template
+	if err := json.Unmarshal([]byte(r.FormValue("template_object")), &t); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"msg":"invalid template_object","code":-2}`)
+		return
+	}
+	f.memos = append(f.memos, t)
+	fmt.Fprint(w, `{"result_code":0}`)
+}
+
+// expireAll은 발급한 액세스 토큰을 모두 무효로 만듭니다 (카카오 쪽에서 만료된 상황)
+func (f *fakeKakao) expireAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.valid = map[string]bool{}
+}
+
+func TestMemoSendFeed(t *testing.T) {
+	f, c := newFakeKakao(t)
+	memo := c.Memo("seed-refresh", nil)
+
+	feed := Feed(
+		Content{Title: "1247회 구매 완료", Description: "자동 5게임", Link: WebLink("https://dhlottery.co.kr")},
+		[]Item{{Item: "A", ItemOp: "1 2 3 4 5 6"}, {Item: "B", ItemOp: "7 8 9 10 11 12"}},
+	)
+	if err := memo.Send(feed); err != nil {
+		t.Fatal(err)
+	}
+	if err := memo.Send(feed); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.refreshes) != 1 || f.refreshes[0] != "seed-refresh" {
+		t.Errorf("액세스 토큰은 한 번만 발급받아야 함: %v", f.refreshes)
+	}
+	if len(f.memos) != 2 {
+		t.Fatalf("메시지 2개를 받아야 함: %d", len(f.memos))
+	}
+	got := f.memos[0]
+	if got.ObjectType != "feed" || got.Content == nil || got.Content.Title != "1247회 구매 완료" {
+		t.Errorf("피드 본문이 다름: %+v", got)
+	}
+	if got.ItemContent == nil || len(got.ItemContent.Items) != 2 || got.ItemContent.Items[1].ItemOp != "7 8 9 10 11 12" {
+		t.Errorf("아이템 목록이 다름: %+v", got.ItemContent)
+	}
+}
+
+func TestMemoSendList(t *testing.T) {
+	f, c := newFakeKakao(t)
+
+	contents := []Content{
+		{Title: "1등", Description: "0게임", Link: WebLink("https://dhlottery.co.kr")},
+		{Title: "5등", Description: "2게임", Link: WebLink("https://dhlottery.co.kr")},
+		{Title: "낙첨", Description: "3게임", Link: WebLink("https://dhlottery.co.kr")},
+		{Title: "넘침", Description: "버려짐", Link: WebLink("https://dhlottery.co.kr")},
+	}
+	if err := c.Memo("seed-refresh", nil).Send(List("1247회 당첨 확인", WebLink("https://dhlottery.co.kr"), contents)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.memos) != 1 {
+		t.Fatalf("메시지 1개를 받아야 함: %d", len(f.memos))
+	}
+	got := f.memos[0]
+	if got.ObjectType != "list" || got.HeaderTitle != "1247회 당첨 확인" || got.HeaderLink == nil {
+		t.Errorf("리스트 머리글이 다름: %+v", got)
+	}
+	if len(got.Contents) != MaxListContents || got.Contents[2].Title != "낙첨" {
+		t.Errorf("리스트 항목은 %d개까지: %+v", MaxListContents, got.Contents)
+	}
+}
+
+func TestRotatedRefreshTokenSaved(t *testing.T) {
+	f, c := newFakeKakao(t)
+	f.rotate = "rotated-refresh"
+	memo := c.Memo("seed-refresh", nil)
+
+	if err := memo.Send(Text("첫 메시지", WebLink("https://dhlottery.co.kr"))); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := loadTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, ok := tokens[Key("seed-refresh")]
+	if !ok {
+		t.Fatalf("설정 파일 토큰의 Key로 저장해야 함: %v", tokens)
+	}
+	if saved.RefreshToken != "rotated-refresh" || saved.RefreshExpiresAt.IsZero() {
+		t.Errorf("회전한 리프레시 토큰을 저장해야 함: %+v", saved)
+	}
+
+	// 액세스 토큰이 거부되면 회전한 리프레시 토큰으로 다시 받아 재전송
+	f.expireAll()
+	if err := memo.Send(Text("두 번째 메시지", WebLink("https://dhlottery.co.kr"))); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"seed-refresh", "rotated-refresh"}; strings.Join(f.refreshes, ",") != strings.Join(want, ",") {
+		t.Errorf("갱신에 쓴 리프레시 토큰: %v, want %v", f.refreshes, want)
+	}
+	if len(f.memos) != 2 || f.memos[1].Text != "두 번째 메시지" {
+		t.Errorf("401 뒤 다시 보내야 함: %+v", f.memos)
+	}
+
+	// 새 클라이언트(다음 실행)도 저장된 토큰을 이어서 씀
+	c2 := New(c.opts, []string{"seed-refresh"})
+	if err := c2.Memo("seed-refresh", nil).Send(Text("다음 실행", WebLink("https://dhlottery.co.kr"))); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.refreshes) != 2 {
+		t.Errorf("저장된 액세스 토큰을 써야 함: %v", f.refreshes)
+	}
+}
+
+func TestExpiredRefreshTokenPermanent(t *testing.T) {
+	f, c := newFakeKakao(t)
+	f.tokenErr["seed-refresh"] = "KOE322"
+
+	err := c.Memo("seed-refresh", nil).Send(Text("보내지 못함", WebLink("https://dhlottery.co.kr")))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("APIError여야 함: %v", err)
+	}
+	if apiErr.Code != "KOE322" || !apiErr.Permanent() {
+		t.Errorf("만료된 리프레시 토큰은 영구 실패: %+v", apiErr)
+	}
+	if len(f.memos) != 0 {
+		t.Errorf("메시지를 보내면 안 됨: %+v", f.memos)
+	}
+}
@@ -0,0 +1,96 @@
+package kakao
+
+// 메시지 템플릿 제한 (넘으면 잘라서 보냄)
+const (
+	MaxTextLength   = 200 // 텍스트 템플릿 본문
+	MaxItems        = 5   // 피드 템플릿 아이템 목록
+	MinListContents = 2   // 리스트 템플릿 항목 (최소)
+	MaxListContents = 3   // 리스트 템플릿 항목 (최대)
+)
+
+// Template은 기본 메시지 템플릿 객체입니다 (template_object, object_type은 text, feed, list)
+type Template struct {
+	ObjectType string `json:"object_type"`
+
+	// 텍스트 템플릿
+	Text        string `json:"text,omitempty"`
+	Link        *Link  `json:"link,omitempty"`
+	ButtonTitle string `json:"button_title,omitempty"`
+
+	// 피드 템플릿
+	Content     *Content     `json:"content,omitempty"`
+	ItemContent *ItemContent `json:"item_content,omitempty"`
+
+	// 리스트 템플릿
+	HeaderTitle string    `json:"header_title,omitempty"`
+	HeaderLink  *Link     `json:"header_link,omitempty"`
+	Contents    []Content `json:"contents,omitempty"`
+}
+
+// Link는 메시지를 눌렀을 때 여는 주소입니다 (카카오 앱 설정의 플랫폼에 등록한 도메인만 열림)
+type Link struct {
+	WebURL       string `json:"web_url,omitempty"`
+	MobileWebURL string `json:"mobile_web_url,omitempty"`
+}
+
+// WebLink는 PC와 모바일에서 같은 주소를 여는 링크입니다
+func WebLink(url string) Link {
+	return Link{WebURL: url, MobileWebURL: url}
+}
+
+// Content는 피드 템플릿의 본문이나 리스트 템플릿의 항목 하나입니다
+type Content struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	Link        Link   `json:"link"`
+}
+
+// ItemContent는 피드 템플릿의 아이템 목록입니다 (이름과 값을 두 열로 표시)
+type ItemContent struct {
+	ProfileText string `json:"profile_text,omitempty"`
+	Items       []Item `json:"items,omitempty"`
+	Sum         string `json:"sum,omitempty"`
+	SumOp       string `json:"sum_op,omitempty"`
+}
+
+// Item은 아이템 목록의 한 줄입니다
+type Item struct {
+	Item   string `json:"item"`
+	ItemOp string `json:"item_op"`
+}
+
+// Text는 텍스트 템플릿입니다 (MaxTextLength자까지)
+func Text(text string, link Link) Template {
+	return Template{ObjectType: "text", Text: truncate(text, MaxTextLength), Link: &link}
+}
+
+// Feed는 제목, 설명과 아이템 목록(MaxItems개까지)이 있는 피드 템플릿입니다
+func Feed(content Content, items []Item) Template {
+	t := Template{ObjectType: "feed", Content: &content}
+	if len(items) > MaxItems {
+		items = items[:MaxItems]
+	}
+	if len(items) > 0 {
+		t.ItemContent = &ItemContent{Items: items}
+	}
+	return t
+}
+
+// List는 머리글과 항목(MinListContents~MaxListContents개)이 있는 리스트 템플릿입니다
+// 넘치는 항목은 버리며, 항목이 모자라면 호출하는 쪽에서 다른 템플릿을 써야 합니다
+func List(title string, link Link, contents []Content) Template {
+	if len(contents) > MaxListContents {
+		contents = contents[:MaxListContents]
+	}
+	return Template{ObjectType: "list", HeaderTitle: title, HeaderLink: &link, Contents: contents}
+}
+
+// truncate는 문자열을 최대 n글자로 자릅니다
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
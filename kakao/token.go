@@ -0,0 +1,64 @@
+package kakao
+
+import (
+	"dhlottery/datadir"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// TokenFileName은 갱신한 토큰을 저장하는 파일 이름입니다 (상태 디렉토리)
+const TokenFileName = "kakao_tokens.json"
+
+// Token은 사용자 토큰 한 쌍입니다
+// 리프레시 토큰은 만료가 한 달 안으로 남으면 갱신할 때 새 값으로 바뀌므로 (회전) 파일에 저장해 두고 씁니다
+type Token struct {
+	AccessToken      string    `json:"accessToken"`
+	RefreshToken     string    `json:"refreshToken"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt,omitempty"`
+}
+
+// valid는 액세스 토큰을 margin 이상 더 쓸 수 있는지 반환합니다
+func (t Token) valid(now time.Time, margin time.Duration) bool {
+	return t.AccessToken != "" && now.Add(margin).Before(t.ExpiresAt)
+}
+
+// loadTokens는 저장된 토큰을 읽습니다 (키: 설정 파일의 리프레시 토큰으로 만든 Key, 파일이 없으면 빈 맵)
+func loadTokens() (map[string]Token, error) {
+	tokens := map[string]Token{}
+	data, err := os.ReadFile(datadir.Path(datadir.State, TokenFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return tokens, nil
+		}
+		return nil, fmt.Errorf("카카오 토큰 파일 읽기 실패: %w", err)
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("카카오 토큰 파일 파싱 실패: %w", err)
+	}
+	return tokens, nil
+}
+
+// saveTokens는 토큰을 저장합니다 (임시 파일에 쓴 뒤 이름을 바꿔 중간에 끊겨도 기존 파일 유지)
+func saveTokens(tokens map[string]Token) error {
+	if err := os.MkdirAll(datadir.Path(datadir.State), 0700); err != nil {
+		return fmt.Errorf("상태 디렉토리 생성 실패: %w", err)
+	}
+
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("카카오 토큰 직렬화 실패: %w", err)
+	}
+	path := datadir.Path(datadir.State, TokenFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("카카오 토큰 파일 쓰기 실패: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("카카오 토큰 파일 쓰기 실패: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"dhlottery/datadir"
 	"fmt"
 	"io"
 	"log"
@@ -13,15 +14,14 @@ var (
 	logFile *os.File
 )
 
-// Init는 로거를 초기화하고 로그 파일을 생성합니다
+// Init는 로거를 초기화하고 데이터 디렉토리의 logs 폴더에 로그 파일을 생성합니다
 func Init() error {
-	// logs 디렉토리 생성
-	logsDir := "logs"
-	if err := os.MkdirAll(logsDir, 0755); err != nil {
+	logsDir := datadir.Path(datadir.Logs)
+	if err := os.MkdirAll(logsDir, 0700); err != nil {
 		return fmt.Errorf("로그 디렉토리 생성 실패: %w", err)
 	}
 
-	// 로그 파일명: logs/lottery_2026-01-13.log
+	// 로그 파일명: <데이터 디렉토리>/logs/lottery_2026-01-13.log
 	logFileName := fmt.Sprintf("lottery_%s.log", time.Now().Format("2006-01-02"))
 	logFilePath := filepath.Join(logsDir, logFileName)
 
@@ -32,8 +32,9 @@ func Init() error {
 		return fmt.Errorf("로그 파일 생성 실패: %w", err)
 	}
 
-	// 로그를 콘솔과 파일 둘 다에 출력
-	multiWriter := io.MultiWriter(os.Stdout, logFile)
+	// 로그를 콘솔(표준 에러)과 파일 둘 다에 출력
+	// 표준 출력은 --output 결과(json, ndjson, table) 전용으로 남겨둠
+	multiWriter := io.MultiWriter(os.Stderr, logFile)
 	log.SetOutput(multiWriter)
 	log.SetFlags(log.Ldate | log.Ltime)
 
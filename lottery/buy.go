@@ -1,6 +1,7 @@
 package lottery
 
 import (
+	"dhlottery/message"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,12 +15,81 @@ import (
 
 // BuyLottoAutoWithResult는 로또를 자동으로 구매하고 텔레그램용 메시지를 반환합니다
 func (c *Client) BuyLottoAutoWithResult(userID string, quantity int) (map[string]interface{}, string, error) {
+	gameInfo, directIP, err := c.prepareBuy()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 5단계: 실제 구매 요청
+	log.Println("5단계: 로또 구매 요청 중...")
+	log.Printf("   💰 구매 금액: %d원\n", quantity*1000)
+
+	result, err := c.executeBuy(buildBuyForm(gameInfo, directIP, quantity))
+	if err != nil {
+		return nil, "", fmt.Errorf("구매 실패: %w", err)
+	}
+
+	// 6단계: 텔레그램용 메시지 생성
+	telegramMsg := c.formatTelegramMessage(result, quantity)
+
+	// 7단계: 구매 내역 저장
+	if err := SavePurchaseHistory(userID, gameInfo.CurRound, gameInfo.RoundDrawDate, result); err != nil {
+		log.Printf("⚠️  구매 내역 저장 실패: %v\n", err)
+		// 저장 실패는 치명적이지 않으므로 계속 진행
+	} else {
+		log.Printf("✅ 구매 내역 저장 완료: %s\n", historyFilePath())
+	}
+
+	return result, telegramMsg, nil
+}
+
+// prepareBuy는 구매 페이지에서 회차 정보를 추출하고 대기열과 세션을 확인합니다 (구매 요청 직전 단계까지)
+func (c *Client) prepareBuy() (LottoGameInfo, string, error) {
+	buyPageURL := "https://ol.dhlottery.co.kr/olotto/game/game645.do"
+
+	gameInfo, err := c.GetGameInfo()
+	if err != nil {
+		return LottoGameInfo{}, "", err
+	}
+
+	// 3단계: 대기열 체크
+	log.Println("3단계: 구매 대기열 확인 중...")
+
+	directIP, err := c.checkReadySocket()
+	if err != nil {
+		return LottoGameInfo{}, "", fmt.Errorf("대기열 확인 실패: %w", err)
+	}
+
+	if directIP != "" {
+		log.Printf("   → 대기열 없음, 즉시 구매 가능 (IP: %s)\n", directIP)
+	}
+
+	// 4단계: 구매 직전 세션 확인을 위해 구매 페이지 재방문
+	log.Println("4단계: 구매 전 세션 확인 중...")
+
+	sessionCheckReq, err := http.NewRequest("GET", buyPageURL, nil)
+	if err == nil {
+		sessionCheckReq.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+		sessionCheckReq.Header.Set("Referer", "https://el.dhlottery.co.kr/game/TotalGame.jsp?LottoId=LO40")
+		sessionCheckResp, err := c.httpClient.Do(sessionCheckReq)
+		if err == nil {
+			defer sessionCheckResp.Body.Close()
+			io.ReadAll(sessionCheckResp.Body)
+			log.Println("   → 세션 갱신 완료")
+		}
+	}
+
+	return gameInfo, directIP, nil
+}
+
+// GetGameInfo는 구매 페이지(game645.do)에서 현재 회차 정보를 추출합니다
+func (c *Client) GetGameInfo() (LottoGameInfo, error) {
 	// 실제 로또 구매 페이지 접근
 	buyPageURL := "https://ol.dhlottery.co.kr/olotto/game/game645.do"
 
 	req, err := http.NewRequest("GET", buyPageURL, nil)
 	if err != nil {
-		return nil, "", fmt.Errorf("구매 페이지 요청 생성 실패: %w", err)
+		return LottoGameInfo{}, fmt.Errorf("구매 페이지 요청 생성 실패: %w", err)
 	}
 
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
@@ -28,7 +98,7 @@ func (c *Client) BuyLottoAutoWithResult(userID string, quantity int) (map[string
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, "", fmt.Errorf("구매 페이지 접속 실패: %w", err)
+		return LottoGameInfo{}, fmt.Errorf("구매 페이지 접속 실패: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -42,7 +112,7 @@ func (c *Client) BuyLottoAutoWithResult(userID string, quantity int) (map[string
 
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(bodyStr))
 	if err != nil {
-		return nil, "", fmt.Errorf("HTML 파싱 실패: %w", err)
+		return LottoGameInfo{}, fmt.Errorf("HTML 파싱 실패: %w", err)
 	}
 
 	gameInfo := LottoGameInfo{}
@@ -69,61 +139,14 @@ func (c *Client) BuyLottoAutoWithResult(userID string, quantity int) (map[string
 
 	// 필수 정보 검증
 	if gameInfo.CurRound == "" || gameInfo.RoundDrawDate == "" {
-		return nil, "", fmt.Errorf("구매 정보 추출 실패: 회차 또는 추첨일 정보가 없습니다")
+		return LottoGameInfo{}, fmt.Errorf("구매 정보 추출 실패: 회차 또는 추첨일 정보가 없습니다")
 	}
 
 	log.Printf("   → 현재 회차: %s회\n", gameInfo.CurRound)
 	log.Printf("   → 추첨일: %s\n", gameInfo.RoundDrawDate)
 	log.Printf("   → 예치금: %s원\n", gameInfo.MoneyBalance)
 
-	// 3단계: 대기열 체크
-	log.Println("3단계: 구매 대기열 확인 중...")
-
-	directIP, err := c.checkReadySocket()
-	if err != nil {
-		return nil, "", fmt.Errorf("대기열 확인 실패: %w", err)
-	}
-
-	if directIP != "" {
-		log.Printf("   → 대기열 없음, 즉시 구매 가능 (IP: %s)\n", directIP)
-	}
-
-	// 4단계: 구매 직전 세션 확인을 위해 구매 페이지 재방문
-	log.Println("4단계: 구매 전 세션 확인 중...")
-
-	sessionCheckReq, err := http.NewRequest("GET", buyPageURL, nil)
-	if err == nil {
-		sessionCheckReq.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-		sessionCheckReq.Header.Set("Referer", "https://el.dhlottery.co.kr/game/TotalGame.jsp?LottoId=LO40")
-		sessionCheckResp, err := c.httpClient.Do(sessionCheckReq)
-		if err == nil {
-			defer sessionCheckResp.Body.Close()
-			io.ReadAll(sessionCheckResp.Body)
-			log.Println("   → 세션 갱신 완료")
-		}
-	}
-
-	// 5단계: 실제 구매 요청
-	log.Println("5단계: 로또 구매 요청 중...")
-	log.Printf("   💰 구매 금액: %d원\n", quantity*1000)
-
-	result, err := c.executeBuy(gameInfo, directIP, quantity)
-	if err != nil {
-		return nil, "", fmt.Errorf("구매 실패: %w", err)
-	}
-
-	// 6단계: 텔레그램용 메시지 생성
-	telegramMsg := c.formatTelegramMessage(userID, result, quantity)
-
-	// 7단계: 구매 내역 저장
-	if err := SavePurchaseHistory(userID, gameInfo.CurRound, gameInfo.RoundDrawDate, result); err != nil {
-		log.Printf("⚠️  구매 내역 저장 실패: %v\n", err)
-		// 저장 실패는 치명적이지 않으므로 계속 진행
-	} else {
-		log.Printf("✅ 구매 내역 저장 완료: logs/last_purchase.json\n")
-	}
-
-	return result, telegramMsg, nil
+	return gameInfo, nil
 }
 
 // checkReadySocket은 구매 대기열을 확인합니다
@@ -170,10 +193,8 @@ func (c *Client) checkReadySocket() (string, error) {
 	return "", nil
 }
 
-// executeBuy는 실제 구매를 실행합니다
-func (c *Client) executeBuy(gameInfo LottoGameInfo, directIP string, quantity int) (map[string]interface{}, error) {
-	buyURL := "https://ol.dhlottery.co.kr/olotto/game/execBuy.do"
-
+// buildBuyForm은 execBuy.do로 전송할 자동 구매 폼 데이터를 생성합니다
+func buildBuyForm(gameInfo LottoGameInfo, directIP string, quantity int) url.Values {
 	// 자동 구매 파라미터 생성
 	alpabet := []string{"A", "B", "C", "D", "E"}
 	param := make([]map[string]interface{}, quantity)
@@ -199,6 +220,13 @@ func (c *Client) executeBuy(gameInfo LottoGameInfo, directIP string, quantity in
 	formData.Set("gameCnt", fmt.Sprintf("%d", quantity))
 	formData.Set("saleMdaDcd", "10") // 판매 매체 구분 코드
 
+	return formData
+}
+
+// executeBuy는 실제 구매를 실행합니다
+func (c *Client) executeBuy(formData url.Values) (map[string]interface{}, error) {
+	buyURL := "https://ol.dhlottery.co.kr/olotto/game/execBuy.do"
+
 	req, err := http.NewRequest("POST", buyURL, strings.NewReader(formData.Encode()))
 	if err != nil {
 		return nil, err
@@ -237,224 +265,152 @@ func (c *Client) executeBuy(gameInfo LottoGameInfo, directIP string, quantity in
 }
 
 // formatTelegramMessage는 구매 결과를 텔레그램 메시지로 포맷합니다
-func (c *Client) formatTelegramMessage(userID string, result map[string]interface{}, quantity int) string {
-	// 로그인 체크
+func (c *Client) formatTelegramMessage(result map[string]interface{}, quantity int) string {
+	return message.Text(message.EventPurchase, message.Telegram, PurchaseMessage(c.DisplayName(), result, quantity))
+}
+
+// PurchaseMessage는 구매 응답을 메시지 템플릿 데이터로 바꿉니다 (quantity가 0이면 구매한 게임 수, name은 표시할 계정 이름)
+func PurchaseMessage(name string, result map[string]interface{}, quantity int) message.Purchase {
+	data := message.Purchase{Account: name, Failure: "unknown"}
+
+	// 로그인, 기기 제한, 판매시간 체크
 	if loginYn, ok := result["loginYn"].(string); ok && loginYn == "N" {
-		return fmt.Sprintf("(%s) ❌ <b>로그인 세션 만료</b>\n\n다시 로그인해주세요.", userID)
+		data.Failure = "session"
+		return data
 	}
-
-	// 기기 제한 체크
 	if isAllowed, ok := result["isAllowed"].(string); ok && isAllowed == "N" {
-		return fmt.Sprintf("(%s) ❌ <b>구매 실패</b>\n\n모바일에서는 구매할 수 없습니다.", userID)
+		data.Failure = "device"
+		return data
 	}
-
-	// 판매시간 체크
 	if checkTime, ok := result["checkOltSaleTime"].(bool); ok && !checkTime {
-		return fmt.Sprintf("(%s) ❌ <b>구매 실패</b>\n\n현재 판매 시간이 아닙니다.", userID)
+		data.Failure = "time"
+		return data
 	}
 
-	// 결과 확인
-	if resultData, ok := result["result"].(map[string]interface{}); ok {
-		resultCode := resultData["resultCode"].(string)
-
-		if resultCode == "100" {
-			// 구매 성공
-			msg := fmt.Sprintf("(%s) ✅ <b>로또 구매 성공!</b>\n\n", userID)
-			msg += fmt.Sprintf("💰 구매 금액: <b>%s원</b>\n", FormatMoney(quantity*1000))
-			msg += fmt.Sprintf("🎱 구매 게임: <b>%d게임</b>\n\n", quantity)
-
-			// 번호 출력
-			if arrGameChoiceNum, ok := resultData["arrGameChoiceNum"].([]interface{}); ok {
-				alpabet := []string{"A", "B", "C", "D", "E"}
-
-				for i, numData := range arrGameChoiceNum {
-					numStr := numData.(string)
-					genType := numStr[len(numStr)-1:]
-					numStr = numStr[:len(numStr)-1]
-
-					numbers := strings.Split(numStr, "|")
-
-					typeLabel := ""
-					if genType == "3" {
-						typeLabel = " (자동)"
-					}
-
-					msg += fmt.Sprintf("[%s%s] ", alpabet[i], typeLabel)
-					for j, num := range numbers {
-						if j > 0 {
-							msg += " - "
-						}
-						msg += strings.TrimSpace(num)
-					}
-					msg += "\n"
-				}
-			}
-
-			msg += "\n"
-
-			// 추첨일
-			if drawDate, ok := resultData["drawDate"].(string); ok {
-				msg += fmt.Sprintf("📅 추첨일: %s\n", drawDate)
-			}
-
-			msg += "\n💡 행운을 빕니다!"
-
-			return msg
-
-		} else {
-			// 구매 실패
-			resultMsg := ""
-			if msg, ok := resultData["resultMsg"].(string); ok {
-				resultMsg = msg
-			}
+	resultData, ok := result["result"].(map[string]interface{})
+	if !ok {
+		return data
+	}
 
-			msg := fmt.Sprintf("(%s) ❌ <b>구매 실패</b>\n\n", userID)
-			msg += fmt.Sprintf("사유: %s\n\n", resultMsg)
+	if resultCode, _ := resultData["resultCode"].(string); resultCode != "100" {
+		// 구매 실패
+		data.Failure = "rejected"
+		data.Reason, _ = resultData["resultMsg"].(string)
+		switch {
+		case IsPurchaseLimitReached(result):
+			data.Hint = "limit"
+		case strings.Contains(data.Reason, "예치금") || strings.Contains(data.Reason, "잔액"):
+			data.Hint = "balance"
+		case strings.Contains(data.Reason, "시간"):
+			data.Hint = "time"
+		}
+		return data
+	}
 
-			if strings.Contains(resultMsg, "한도") || strings.Contains(resultMsg, "5000") {
-				msg += "💡 이번 회차에 이미 최대 한도(5,000원)를 구매하셨습니다."
-			} else if strings.Contains(resultMsg, "예치금") || strings.Contains(resultMsg, "잔액") {
-				msg += "💡 예치금이 부족합니다. 충전 후 다시 시도해주세요."
+	// 구매 성공
+	data.Success, data.Failure = true, ""
+	if arrGameChoiceNum, ok := resultData["arrGameChoiceNum"].([]interface{}); ok {
+		for _, gameData := range arrGameChoiceNum {
+			gameStr, _ := gameData.(string)
+			game := parseGameNumbers(gameStr)
+			if game == nil {
+				continue
 			}
+			data.Games = append(data.Games, message.Game{Label: game.Type, Numbers: game.Numbers, Mode: gameMode(gameStr)})
+		}
+	}
 
-			return msg
+	data.Quantity = quantity
+	if data.Quantity == 0 {
+		data.Quantity = len(data.Games)
+	}
+	data.Amount = data.Quantity * 1000
+	data.Round, data.DrawDate = PurchaseDrawInfo(result)
+	data.PayLimitDate, _ = resultData["payLimitDate"].(string)
+	if barCode, ok := resultData["barCode"].([]interface{}); ok {
+		for _, code := range barCode {
+			data.Barcodes = append(data.Barcodes, fmt.Sprint(code))
 		}
 	}
+	return data
+}
 
-	return fmt.Sprintf("(%s) ❌ 구매 결과를 확인할 수 없습니다.", userID)
+// gameMode는 게임 문자열 끝의 선택 방식(genType)을 반환합니다 (1 = 수동, 2 = 반자동, 3 = 자동)
+func gameMode(gameStr string) string {
+	switch {
+	case strings.HasSuffix(gameStr, "1"):
+		return "manual"
+	case strings.HasSuffix(gameStr, "2"):
+		return "semi"
+	case strings.HasSuffix(gameStr, "3"):
+		return "auto"
+	}
+	return ""
 }
 
-// PrintBuyResult는 구매 결과를 출력합니다
-func (c *Client) PrintBuyResult(result map[string]interface{}) {
-	log.Println()
-	log.Println("╔════════════════════════════════════════╗")
-	log.Println("║          로또 6/45 구매 결과           ║")
-	log.Println("╚════════════════════════════════════════╝")
-	log.Println()
+// IsBuySuccess는 구매 응답이 성공(resultCode 100)인지 확인합니다
+func IsBuySuccess(result map[string]interface{}) bool {
+	resultData, ok := result["result"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	resultCode, _ := resultData["resultCode"].(string)
+	return resultCode == "100"
+}
+
+// PurchaseDrawInfo는 구매 응답의 회차와 추첨일을 반환합니다 (없으면 빈 문자열)
+func PurchaseDrawInfo(result map[string]interface{}) (round, drawDate string) {
+	resultData, ok := result["result"].(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+	if value, ok := resultData["buyRound"]; ok && value != nil {
+		round = fmt.Sprint(value)
+	}
+	drawDate, _ = resultData["drawDate"].(string)
+	return round, drawDate
+}
 
-	// 로그인 체크
+// BuyFailureReason은 구매 실패 응답에서 실패 사유를 추출합니다
+func BuyFailureReason(result map[string]interface{}) string {
 	if loginYn, ok := result["loginYn"].(string); ok && loginYn == "N" {
-		log.Println("❌ 로그인 세션이 만료되었습니다.")
-		log.Println("   다시 로그인해주세요.")
-		return
+		return "로그인 세션 만료"
 	}
 
-	// 기기 제한 체크
 	if isAllowed, ok := result["isAllowed"].(string); ok && isAllowed == "N" {
-		log.Println("❌ 모바일에서는 구매할 수 없습니다.")
-		log.Println("   PC 환경에서 시도해주세요.")
-		return
+		return "모바일에서는 구매할 수 없습니다"
 	}
 
-	// 판매시간 체크
 	if checkTime, ok := result["checkOltSaleTime"].(bool); ok && !checkTime {
-		log.Println("❌ 현재 판매 시간이 아닙니다.")
-		log.Println("   판매 시간을 확인해주세요.")
-		return
+		return "현재 판매 시간이 아닙니다"
 	}
 
-	// 결과 확인
 	if resultData, ok := result["result"].(map[string]interface{}); ok {
-		resultCode := resultData["resultCode"].(string)
-
-		if resultCode == "100" {
-			// 구매 성공
-			log.Println("✅ 구매가 성공적으로 완료되었습니다!")
-			log.Println()
-
-			// 구매 번호 출력
-			if arrGameChoiceNum, ok := resultData["arrGameChoiceNum"].([]interface{}); ok {
-				log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-				log.Printf("    구매 게임 수: %d 게임 (총 %s원)\n", len(arrGameChoiceNum), FormatMoney(len(arrGameChoiceNum)*1000))
-				log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-				log.Println()
-
-				alpabet := []string{"A", "B", "C", "D", "E"}
-
-				for i, numData := range arrGameChoiceNum {
-					numStr := numData.(string)
-					genType := numStr[len(numStr)-1:]
-					numStr = numStr[:len(numStr)-1]
-
-					numbers := strings.Split(numStr, "|")
-
-					gameLabel := alpabet[i]
-					typeLabel := ""
-					if genType == "3" {
-						typeLabel = " (자동)"
-					} else if genType == "1" {
-						typeLabel = " (수동)"
-					} else if genType == "2" {
-						typeLabel = " (반자동)"
-					}
-
-					log.Printf("  🎱 [%s 게임%s]  ", gameLabel, typeLabel)
-
-					for j, num := range numbers {
-						if j > 0 {
-							log.Printf(" - ")
-						}
-						log.Printf("%s", strings.TrimSpace(num))
-					}
-					log.Println()
-				}
-			}
-
-			log.Println()
-			log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-
-			// 당첨금 수령 정보
-			if drawDate, ok := resultData["drawDate"].(string); ok {
-				log.Printf("    추첨일: %s\n", drawDate)
-			}
-
-			if payLimitDate, ok := resultData["payLimitDate"].(string); ok {
-				log.Printf("    당첨금 지급기한: %s\n", payLimitDate)
-			}
-
-			// 바코드 정보
-			if barCode, ok := resultData["barCode"].([]interface{}); ok && len(barCode) > 0 {
-				log.Println()
-				log.Print("    바코드: ")
-				for i, code := range barCode {
-					if i > 0 {
-						log.Printf(" ")
-					}
-					log.Printf("%v", code)
-				}
-				log.Println()
-			}
-
-			log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-			log.Println()
-			log.Println("💡 구매가 완료되었습니다. 행운을 빕니다!")
+		if msg, ok := resultData["resultMsg"].(string); ok && msg != "" {
+			return msg
+		}
+	}
 
-		} else {
-			// 구매 실패
-			resultMsg := ""
-			if msg, ok := resultData["resultMsg"].(string); ok {
-				resultMsg = msg
-			}
+	return "구매 결과를 확인할 수 없습니다"
+}
 
-			log.Println("❌ 구매 실패")
-			log.Println()
-			log.Printf("   사유: %s\n", resultMsg)
-			log.Println()
-
-			if strings.Contains(resultMsg, "한도") || strings.Contains(resultMsg, "5000") {
-				log.Println("   💡 이번 회차에 이미 최대 한도(5,000원)를 구매하셨습니다.")
-				log.Println("      온라인으로는 1회차당 최대 5게임까지만 구매 가능합니다.")
-			} else if strings.Contains(resultMsg, "예치금") || strings.Contains(resultMsg, "잔액") {
-				log.Println("   💡 예치금이 부족합니다.")
-				log.Println("      예치금을 충전한 후 다시 시도해주세요.")
-			} else if strings.Contains(resultMsg, "시간") {
-				log.Println("   💡 현재 구매 가능한 시간이 아닙니다.")
-				log.Println("      판매 시간을 확인해주세요.")
-			}
-		}
+// IsPurchaseLimitReached는 구매 응답이 회차당 구매 한도 초과로 거부된 것인지 확인합니다
+// 결과 코드로 거부된 응답인지 먼저 확인하므로, 다른 응답의 메시지에 금액(5000 등)이 들어 있어도 한도 초과로 보지 않습니다
+func IsPurchaseLimitReached(result map[string]interface{}) bool {
+	resultData, ok := result["result"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if resultCode, _ := resultData["resultCode"].(string); resultCode == "" || resultCode == "100" {
+		return false
 	}
+	reason, _ := resultData["resultMsg"].(string)
+	return strings.Contains(reason, "한도")
+}
 
-	log.Println()
+// PrintBuyResult는 구매 결과를 출력합니다
+func (c *Client) PrintBuyResult(result map[string]interface{}) {
+	message.Log(message.EventPurchase, PurchaseMessage(c.DisplayName(), result, 0))
 }
 
 // GetLoginStatus는 현재 로그인 상태를 반환합니다
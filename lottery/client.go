@@ -1,6 +1,7 @@
 package lottery
 
 import (
+	"dhlottery/message"
 	"fmt"
 	"net/http"
 	"net/http/cookiejar"
@@ -14,6 +15,7 @@ type Client struct {
 	httpClient *http.Client
 	UserID     string
 	Password   string
+	Alias      string // 메시지에 아이디 대신 표시할 이름 (비우면 아이디)
 }
 
 // NewClient는 새로운 동행복권 클라이언트를 생성합니다
@@ -43,6 +45,14 @@ func NewClient(userID, password string) (*Client, error) {
 	}, nil
 }
 
+// DisplayName은 메시지에 표시할 계정 이름입니다 (별칭이 없으면 아이디)
+func (c *Client) DisplayName() string {
+	if c.Alias != "" {
+		return c.Alias
+	}
+	return c.UserID
+}
+
 // GetHTTPClient는 HTTP 클라이언트를 반환합니다
 func (c *Client) GetHTTPClient() *http.Client {
 	return c.httpClient
@@ -50,19 +60,7 @@ func (c *Client) GetHTTPClient() *http.Client {
 
 // FormatMoney는 숫자를 천 단위 구분자가 있는 문자열로 변환합니다
 func FormatMoney(amount int) string {
-	if amount < 1000 {
-		return fmt.Sprintf("%d", amount)
-	}
-
-	str := fmt.Sprintf("%d", amount)
-	result := ""
-	for i, char := range str {
-		if i > 0 && (len(str)-i)%3 == 0 {
-			result += ","
-		}
-		result += string(char)
-	}
-	return result
+	return message.FormatMoney(amount)
 }
 
 // min 함수 (Go 1.21 미만 호환성)
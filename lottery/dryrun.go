@@ -0,0 +1,123 @@
+package lottery
+
+import (
+	"dhlottery/message"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DryRunPrefix는 테스트 모드 메시지 앞에 붙는 표시입니다 (구매 결과 메시지는 템플릿에서 붙임)
+const DryRunPrefix = "[DRY RUN] "
+
+// DryRunBuy는 실제 구매 요청(execBuy.do POST) 직전까지 모든 단계를 수행하고,
+// 전송될 폼 데이터를 출력한 뒤 가상의 구매 결과로 내역 저장과 메시지 생성을 진행합니다
+func (c *Client) DryRunBuy(userID string, quantity int) (map[string]interface{}, string, error) {
+	gameInfo, directIP, err := c.prepareBuy()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 5단계: 구매 요청 대신 폼 데이터 출력
+	log.Println("5단계: 구매 요청 폼 데이터 (전송 안 함)")
+	log.Printf("   💰 구매 금액: %d원\n", quantity*1000)
+	printBuyForm(buildBuyForm(gameInfo, directIP, quantity))
+
+	result := synthesizeBuyResult(gameInfo, quantity)
+
+	// 6단계: 텔레그램용 메시지 생성
+	data := PurchaseMessage(c.DisplayName(), result, quantity)
+	data.DryRun = true
+	telegramMsg := message.Text(message.EventPurchase, message.Telegram, data)
+
+	// 7단계: 구매 내역 저장 (실제 내역과 분리된 임시 파일)
+	if _, err := savePurchaseHistoryTo(dryRunHistoryFilePath(), userID, gameInfo.CurRound, gameInfo.RoundDrawDate, result); err != nil {
+		return nil, "", fmt.Errorf("테스트 구매 내역 저장 실패: %w", err)
+	}
+	log.Printf("✅ 테스트 구매 내역 저장 완료: %s\n", dryRunHistoryFilePath())
+
+	return result, telegramMsg, nil
+}
+
+// printBuyForm은 execBuy.do 폼 데이터를 출력합니다 (param은 JSON을 풀어서 출력)
+func printBuyForm(formData url.Values) {
+	keys := make([]string, 0, len(formData))
+	for key := range formData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	log.Println("   ┌─ POST https://ol.dhlottery.co.kr/olotto/game/execBuy.do")
+	for _, key := range keys {
+		value := formData.Get(key)
+		if key != "param" {
+			log.Printf("   │ %s = %s\n", key, value)
+			continue
+		}
+
+		var param []map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &param); err != nil {
+			log.Printf("   │ %s = %s (JSON 디코딩 실패: %v)\n", key, value, err)
+			continue
+		}
+		log.Printf("   │ %s = (%d개 게임)\n", key, len(param))
+		for _, game := range param {
+			decoded, _ := json.Marshal(game)
+			log.Printf("   │   %s\n", decoded)
+		}
+	}
+	log.Println("   └─")
+}
+
+// synthesizeBuyResult는 execBuy.do 성공 응답과 같은 형식의 가상 구매 결과를 만듭니다
+func synthesizeBuyResult(gameInfo LottoGameInfo, quantity int) map[string]interface{} {
+	alpabet := []string{"A", "B", "C", "D", "E"}
+
+	games := make([]interface{}, quantity)
+	for i := 0; i < quantity; i++ {
+		numbers := randomNumbers()
+		parts := make([]string, len(numbers))
+		for j, num := range numbers {
+			parts[j] = fmt.Sprintf("%02d", num)
+		}
+		// 형식: "A|01|02|03|04|05|063" (마지막 숫자 뒤 genType 3 = 자동)
+		games[i] = fmt.Sprintf("%s|%s3", alpabet[i], strings.Join(parts, "|"))
+	}
+
+	barCode := make([]interface{}, 6)
+	for i := range barCode {
+		barCode[i] = fmt.Sprintf("%05d", rand.IntN(100000))
+	}
+
+	return map[string]interface{}{
+		"loginYn":          "Y",
+		"isAllowed":        "Y",
+		"checkOltSaleTime": true,
+		"result": map[string]interface{}{
+			"resultCode":       "100",
+			"resultMsg":        "SUCCESS",
+			"buyRound":         gameInfo.CurRound,
+			"arrGameChoiceNum": games,
+			"drawDate":         gameInfo.RoundDrawDate,
+			"payLimitDate":     gameInfo.WamtPayTlmtEndDt,
+			"issueDay":         time.Now().Format("2006/01/02"),
+			"barCode":          barCode,
+			"nBuyAmount":       quantity * 1000,
+		},
+	}
+}
+
+// randomNumbers는 1~45 중 중복 없는 번호 6개를 오름차순으로 반환합니다
+func randomNumbers() []int {
+	numbers := rand.Perm(45)[:6]
+	for i := range numbers {
+		numbers[i]++
+	}
+	sort.Ints(numbers)
+	return numbers
+}
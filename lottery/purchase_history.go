@@ -1,9 +1,12 @@
 package lottery
 
 import (
+	"dhlottery/datadir"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -17,23 +20,86 @@ type PurchaseHistory struct {
 
 // UserPurchase는 사용자별 구매 정보
 type UserPurchase struct {
-	Success bool           `json:"success"` // 구매 성공 여부
-	Games   []GamePurchase `json:"games"`   // 게임별 번호
+	Success  bool           `json:"success"`            // 구매 성공 여부
+	Games    []GamePurchase `json:"games"`              // 게임별 번호
+	Approval string         `json:"approval,omitempty"` // 구매 승인 결정 (승인 모드 사용 시)
+}
+
+// 구매 승인 결정 값
+const (
+	ApprovalApproved       = "approved"        // 사용자가 구매 승인
+	ApprovalSkipped        = "skipped"         // 사용자가 건너뛰기 선택
+	ApprovalTimeoutBuy     = "timeout-buy"     // 응답 없음, 기본 동작으로 구매
+	ApprovalTimeoutSkipped = "timeout-skipped" // 응답 없음, 기본 동작으로 건너뜀
+)
+
+// IsApprovalSkip은 승인 결정이 구매하지 않는 결정인지 확인합니다
+func IsApprovalSkip(decision string) bool {
+	return decision == ApprovalSkipped || decision == ApprovalTimeoutSkipped
 }
 
 // GamePurchase는 게임별 구매 번호
 type GamePurchase struct {
-	Type    string `json:"type"`    // A, B, C, D, E
-	Numbers []int  `json:"numbers"` // 선택된 번호들
+	Type    string `json:"type"`           // A, B, C, D, E
+	Numbers []int  `json:"numbers"`        // 선택된 번호들
+	Auto    bool   `json:"auto,omitempty"` // 자동 선택 (genType 3)
 }
 
-const historyFilePath = "logs/last_purchase.json"
+// 구매 내역 파일 (데이터 디렉토리의 history 폴더, 회차별 내역은 round_<회차>.json)
+const (
+	historyFileName       = "last_purchase.json"
+	dryRunHistoryFileName = "dryrun_purchase.json" // 테스트 모드에서 사용하는 임시 구매 내역
+)
+
+// historyFilePath는 마지막 구매 내역 파일 경로입니다
+func historyFilePath() string {
+	return datadir.Path(datadir.History, historyFileName)
+}
+
+// dryRunHistoryFilePath는 테스트 모드 구매 내역 파일 경로입니다
+func dryRunHistoryFilePath() string {
+	return datadir.Path(datadir.History, dryRunHistoryFileName)
+}
+
+// roundHistoryPath는 회차별 구매 내역 파일 경로입니다
+func roundHistoryPath(round string) string {
+	return datadir.Path(datadir.History, fmt.Sprintf("round_%s.json", round))
+}
 
-// SavePurchaseHistory는 구매 내역을 저장합니다
+// SavePurchaseHistory는 구매 내역을 저장하고, 구매 응답 원본을 영수증으로 보관합니다
 func SavePurchaseHistory(userID string, round string, purchaseDate string, result map[string]interface{}) error {
-	// logs 디렉토리 생성
-	if err := os.MkdirAll("logs", 0755); err != nil {
-		return fmt.Errorf("logs 디렉토리 생성 실패: %w", err)
+	history, err := savePurchaseHistoryTo(historyFilePath(), userID, round, purchaseDate, result)
+	if err != nil {
+		return err
+	}
+	if err := archivePurchaseHistory(history); err != nil {
+		return err
+	}
+	return saveReceipt(userID, round, result)
+}
+
+// saveReceipt는 구매 응답 원본을 receipts 폴더에 저장합니다 (round_<회차>_<아이디>.json)
+func saveReceipt(userID string, round string, result map[string]interface{}) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON 마샬링 실패: %w", err)
+	}
+
+	if err := os.MkdirAll(datadir.Path(datadir.Receipts), 0700); err != nil {
+		return fmt.Errorf("영수증 디렉토리 생성 실패: %w", err)
+	}
+	path := datadir.Path(datadir.Receipts, fmt.Sprintf("round_%s_%s.json", round, userID))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("영수증 저장 실패: %w", err)
+	}
+	return nil
+}
+
+// savePurchaseHistoryTo는 구매 내역을 지정한 파일에 저장합니다
+func savePurchaseHistoryTo(path string, userID string, round string, purchaseDate string, result map[string]interface{}) (*PurchaseHistory, error) {
+	// 구매 내역 디렉토리 생성
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("구매 내역 디렉토리 생성 실패: %w", err)
 	}
 
 	// 기존 파일 읽기
@@ -44,7 +110,7 @@ func SavePurchaseHistory(userID string, round string, purchaseDate string, resul
 	}
 
 	// 기존 파일이 있으면 읽기
-	if data, err := os.ReadFile(historyFilePath); err == nil {
+	if data, err := os.ReadFile(path); err == nil {
 		var existingHistory PurchaseHistory
 		if json.Unmarshal(data, &existingHistory) == nil {
 			// 같은 회차면 기존 데이터 유지
@@ -61,57 +127,176 @@ func SavePurchaseHistory(userID string, round string, purchaseDate string, resul
 		Games:   []GamePurchase{},
 	}
 
-	if resultData, ok := result["result"].(map[string]interface{}); ok {
-		resultCode, _ := resultData["resultCode"].(string)
+	if games, ok := PurchasedGames(result); ok {
+		userPurchase.Success = true
+		userPurchase.Games = games
+	}
+
+	// 사용자 데이터 추가/업데이트 (기존 승인 결정은 유지)
+	userPurchase.Approval = history.Users[userID].Approval
+	history.Users[userID] = userPurchase
 
-		if resultCode == "100" {
-			// 구매 성공
-			userPurchase.Success = true
+	if err := writePurchaseHistory(path, history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
 
-			// 번호 추출
-			if arrGameChoiceNum, ok := resultData["arrGameChoiceNum"].([]interface{}); ok {
-				for _, gameData := range arrGameChoiceNum {
-					gameStr, _ := gameData.(string)
-					game := parseGameNumbers(gameStr)
-					if game != nil {
-						userPurchase.Games = append(userPurchase.Games, *game)
-					}
-				}
-			}
+// RecordApproval은 구매 승인 결정을 구매 내역에 기록합니다
+func RecordApproval(userID string, round string, purchaseDate string, decision string) error {
+	if err := os.MkdirAll(datadir.Path(datadir.History), 0700); err != nil {
+		return fmt.Errorf("구매 내역 디렉토리 생성 실패: %w", err)
+	}
+
+	history, err := readPurchaseHistory(historyFilePath())
+	if err != nil || history == nil || history.Round != round {
+		history = &PurchaseHistory{
+			Round:        round,
+			PurchaseDate: purchaseDate,
+			Users:        make(map[string]UserPurchase),
 		}
 	}
 
-	// 사용자 데이터 추가/업데이트
+	userPurchase, exists := history.Users[userID]
+	if !exists {
+		userPurchase = UserPurchase{Games: []GamePurchase{}}
+	}
+	userPurchase.Approval = decision
 	history.Users[userID] = userPurchase
 
-	// 파일에 저장
+	if err := writePurchaseHistory(historyFilePath(), history); err != nil {
+		return err
+	}
+	return archivePurchaseHistory(history)
+}
+
+// archivePurchaseHistory는 회차별 구매 내역 파일에 구매 내역을 복사합니다
+func archivePurchaseHistory(history *PurchaseHistory) error {
+	if err := os.MkdirAll(datadir.Path(datadir.History), 0700); err != nil {
+		return fmt.Errorf("구매 내역 디렉토리 생성 실패: %w", err)
+	}
+	return writePurchaseHistory(roundHistoryPath(history.Round), history)
+}
+
+// LoadPurchaseHistory는 특정 회차의 구매 내역을 읽어옵니다 (없으면 nil)
+func LoadPurchaseHistory(round string) (*PurchaseHistory, error) {
+	history, err := readPurchaseHistory(roundHistoryPath(round))
+	if err != nil || history != nil {
+		return history, err
+	}
+
+	// 보관 기능 이전의 내역은 마지막 구매 내역에서 확인
+	last, err := GetLastPurchaseHistory()
+	if err != nil || last == nil || last.Round != round {
+		return nil, err
+	}
+	return last, nil
+}
+
+// ListPurchaseHistory는 보관된 구매 내역을 최신 회차 순으로 반환합니다
+func ListPurchaseHistory() ([]*PurchaseHistory, error) {
+	paths, err := filepath.Glob(datadir.Path(datadir.History, "round_*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("구매 내역 목록 조회 실패: %w", err)
+	}
+
+	histories := make([]*PurchaseHistory, 0, len(paths))
+	for _, path := range paths {
+		history, err := readPurchaseHistory(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		if history != nil {
+			histories = append(histories, history)
+		}
+	}
+
+	sort.Slice(histories, func(i, j int) bool {
+		a, _ := strconv.Atoi(histories[i].Round)
+		b, _ := strconv.Atoi(histories[j].Round)
+		return a > b
+	})
+
+	return histories, nil
+}
+
+// RecentSpend는 최근 rounds개 회차 동안 사용자가 구매한 금액 합계를 반환합니다
+func RecentSpend(userID string, rounds int) (int, error) {
+	histories, err := ListPurchaseHistory()
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for i, history := range histories {
+		if i >= rounds {
+			break
+		}
+		if purchase, ok := history.Users[userID]; ok && purchase.Success {
+			total += len(purchase.Games) * 1000
+		}
+	}
+	return total, nil
+}
+
+// writePurchaseHistory는 구매 내역을 파일에 저장합니다
+func writePurchaseHistory(path string, history *PurchaseHistory) error {
 	data, err := json.MarshalIndent(history, "", "  ")
 	if err != nil {
 		return fmt.Errorf("JSON 마샬링 실패: %w", err)
 	}
 
-	if err := os.WriteFile(historyFilePath, data, 0644); err != nil {
+	if err := os.WriteFile(path, data, 0600); err != nil {
 		return fmt.Errorf("파일 저장 실패: %w", err)
 	}
 
 	return nil
 }
 
+// PurchasedGames는 구매 결과에서 구매한 게임 번호를 추출합니다 (구매 성공이 아니면 false)
+func PurchasedGames(result map[string]interface{}) ([]GamePurchase, bool) {
+	resultData, ok := result["result"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	if resultCode, _ := resultData["resultCode"].(string); resultCode != "100" {
+		return nil, false
+	}
+
+	games := []GamePurchase{}
+	if arrGameChoiceNum, ok := resultData["arrGameChoiceNum"].([]interface{}); ok {
+		for _, gameData := range arrGameChoiceNum {
+			gameStr, _ := gameData.(string)
+			if game := parseGameNumbers(gameStr); game != nil {
+				games = append(games, *game)
+			}
+		}
+	}
+	return games, true
+}
+
 // parseGameNumbers는 "A|20|21|27|29|30|383" 형식의 문자열을 파싱합니다
+// 마지막 칸은 여섯 번째 번호 뒤에 선택 방식(genType, 3 = 자동) 한 자리가 붙어 있습니다
 func parseGameNumbers(gameStr string) *GamePurchase {
 	parts := strings.Split(gameStr, "|")
 	if len(parts) < 7 {
 		return nil
 	}
 
+	last := parts[6]
+	if len(last) < 2 {
+		return nil
+	}
+	parts[6], last = last[:len(last)-1], last[len(last)-1:]
+
 	game := &GamePurchase{
 		Type:    parts[0],
 		Numbers: make([]int, 0, 6),
+		Auto:    last == "3",
 	}
 
-	// 번호 6개 추출 (마지막은 genType이므로 제외)
 	for i := 1; i <= 6; i++ {
-		if num, err := strconv.Atoi(parts[i]); err == nil {
+		if num, err := strconv.Atoi(strings.TrimSpace(parts[i])); err == nil {
 			game.Numbers = append(game.Numbers, num)
 		}
 	}
@@ -121,7 +306,12 @@ func parseGameNumbers(gameStr string) *GamePurchase {
 
 // GetLastPurchaseHistory는 마지막 구매 내역을 읽어옵니다
 func GetLastPurchaseHistory() (*PurchaseHistory, error) {
-	data, err := os.ReadFile(historyFilePath)
+	return readPurchaseHistory(historyFilePath())
+}
+
+// readPurchaseHistory는 구매 내역 파일을 읽어옵니다 (파일이 없으면 nil)
+func readPurchaseHistory(path string) (*PurchaseHistory, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil // 파일이 없으면 nil 반환
@@ -133,6 +323,21 @@ func GetLastPurchaseHistory() (*PurchaseHistory, error) {
 	if err := json.Unmarshal(data, &history); err != nil {
 		return nil, fmt.Errorf("JSON 파싱 실패: %w", err)
 	}
+	repairGameNumbers(&history)
 
 	return &history, nil
 }
+
+// repairGameNumbers는 선택 방식 자리를 떼지 않고 저장된 이전 내역의 마지막 번호를 고칩니다 (383 → 38)
+func repairGameNumbers(history *PurchaseHistory) {
+	for userID, purchase := range history.Users {
+		for i, game := range purchase.Games {
+			if n := len(game.Numbers); n > 0 && game.Numbers[n-1] > 45 {
+				game.Auto = game.Numbers[n-1]%10 == 3
+				game.Numbers[n-1] /= 10
+				purchase.Games[i] = game
+			}
+		}
+		history.Users[userID] = purchase
+	}
+}
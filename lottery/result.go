@@ -1,6 +1,7 @@
 package lottery
 
 import (
+	"dhlottery/message"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,16 +12,78 @@ import (
 
 // LottoResult는 당첨 결과 정보
 type LottoResult struct {
-	Round       string // 회차 (예: "1206")
-	DrawDate    string // 추첨일 (예: "2026-01-10")
-	Numbers     []int  // 당첨번호 6개
-	BonusNumber int    // 보너스번호
+	Round           string // 회차 (예: "1206")
+	DrawDate        string // 추첨일 (예: "2026-01-10")
+	Numbers         []int  // 당첨번호 6개
+	BonusNumber     int    // 보너스번호
+	FirstPrize      int64  // 1등 1인당 당첨금
+	FirstPrizeTotal int64  // 1등 총 당첨금
+	FirstWinners    int    // 1등 당첨자 수 (0이면 이월)
+	SecondPrize     int64  // 2등 1인당 당첨금
+	ThirdPrize      int64  // 3등 1인당 당첨금
 }
 
+// 4, 5등은 고정 당첨금
+const (
+	fourthPrize = 50000
+	fifthPrize  = 5000
+)
+
+// Prize는 등수별 1인당 당첨금입니다 (알 수 없으면 0)
+func (r *LottoResult) Prize(rank int) int64 {
+	switch rank {
+	case 1:
+		return r.FirstPrize
+	case 2:
+		return r.SecondPrize
+	case 3:
+		return r.ThirdPrize
+	case 4:
+		return fourthPrize
+	case 5:
+		return fifthPrize
+	}
+	return 0
+}
+
+// resultURL은 당첨번호 조회 API 주소입니다
+const resultURL = "https://www.dhlottery.co.kr/lt645/selectPstLt645Info.do"
+
 // GetLatestResult는 최근 당첨번호를 가져옵니다
 func GetLatestResult() (*LottoResult, error) {
-	url := "https://www.dhlottery.co.kr/lt645/selectPstLt645Info.do"
+	results, err := fetchResults(resultURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("당첨 정보가 없습니다")
+	}
+
+	result := results[0]
+	log.Printf("✅ 당첨번호 조회 완료: %s회 (%s)\n", result.Round, result.DrawDate)
+	log.Printf("   당첨번호: %v, 보너스: %d\n", result.Numbers, result.BonusNumber)
 
+	return result, nil
+}
+
+// GetResult는 지정한 회차의 당첨번호를 가져옵니다
+func GetResult(round int) (*LottoResult, error) {
+	results, err := fetchResults(fmt.Sprintf("%s?srchLtEpsd=%d", resultURL, round))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if result.Round == strconv.Itoa(round) {
+			log.Printf("✅ 당첨번호 조회 완료: %s회 (%s)\n", result.Round, result.DrawDate)
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("%d회 당첨 정보가 없습니다 (아직 추첨 전이거나 없는 회차)", round)
+}
+
+// fetchResults는 당첨번호 조회 API를 호출하여 응답에 담긴 회차별 결과를 반환합니다
+func fetchResults(url string) ([]*LottoResult, error) {
 	resp, err := http.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("API 호출 실패: %w", err)
@@ -45,6 +108,12 @@ func GetLatestResult() (*LottoResult, error) {
 				Tm5WnNo  int    `json:"tm5WnNo"`  // 당첨번호 5
 				Tm6WnNo  int    `json:"tm6WnNo"`  // 당첨번호 6
 				BnsWnNo  int    `json:"bnsWnNo"`  // 보너스번호
+
+				Rnk1WnAmt    int64 `json:"rnk1WnAmt"`    // 1등 1인당 당첨금
+				Rnk1SumWnAmt int64 `json:"rnk1SumWnAmt"` // 1등 총 당첨금
+				Rnk1WnNope   int   `json:"rnk1WnNope"`   // 1등 당첨자 수
+				Rnk2WnAmt    int64 `json:"rnk2WnAmt"`    // 2등 1인당 당첨금
+				Rnk3WnAmt    int64 `json:"rnk3WnAmt"`    // 3등 1인당 당첨금
 			} `json:"list"`
 		} `json:"data"`
 	}
@@ -53,29 +122,28 @@ func GetLatestResult() (*LottoResult, error) {
 		return nil, fmt.Errorf("JSON 파싱 실패: %w", err)
 	}
 
-	if len(apiResponse.Data.List) == 0 {
-		return nil, fmt.Errorf("당첨 정보가 없습니다")
-	}
-
-	data := apiResponse.Data.List[0]
-
-	// 날짜 포맷 변환 (YYYYMMDD -> YYYY-MM-DD)
-	dateStr := data.LtRflYmd
-	if len(dateStr) == 8 {
-		dateStr = fmt.Sprintf("%s-%s-%s", dateStr[0:4], dateStr[4:6], dateStr[6:8])
-	}
+	results := make([]*LottoResult, 0, len(apiResponse.Data.List))
+	for _, data := range apiResponse.Data.List {
+		// 날짜 포맷 변환 (YYYYMMDD -> YYYY-MM-DD)
+		dateStr := data.LtRflYmd
+		if len(dateStr) == 8 {
+			dateStr = fmt.Sprintf("%s-%s-%s", dateStr[0:4], dateStr[4:6], dateStr[6:8])
+		}
 
-	result := &LottoResult{
-		Round:       strconv.Itoa(data.LtEpsd),
-		DrawDate:    dateStr,
-		Numbers:     []int{data.Tm1WnNo, data.Tm2WnNo, data.Tm3WnNo, data.Tm4WnNo, data.Tm5WnNo, data.Tm6WnNo},
-		BonusNumber: data.BnsWnNo,
+		results = append(results, &LottoResult{
+			Round:           strconv.Itoa(data.LtEpsd),
+			DrawDate:        dateStr,
+			Numbers:         []int{data.Tm1WnNo, data.Tm2WnNo, data.Tm3WnNo, data.Tm4WnNo, data.Tm5WnNo, data.Tm6WnNo},
+			BonusNumber:     data.BnsWnNo,
+			FirstPrize:      data.Rnk1WnAmt,
+			FirstPrizeTotal: data.Rnk1SumWnAmt,
+			FirstWinners:    data.Rnk1WnNope,
+			SecondPrize:     data.Rnk2WnAmt,
+			ThirdPrize:      data.Rnk3WnAmt,
+		})
 	}
 
-	log.Printf("✅ 당첨번호 조회 완료: %s회 (%s)\n", result.Round, result.DrawDate)
-	log.Printf("   당첨번호: %v, 보너스: %d\n", result.Numbers, result.BonusNumber)
-
-	return result, nil
+	return results, nil
 }
 
 // CheckWinning은 구매 번호와 당첨번호를 비교하여 등수를 판정합니다
@@ -122,96 +190,90 @@ func CheckWinning(purchaseNumbers []int, result *LottoResult) (rank int, matchCo
 	return rank, matchCount, hasBonus
 }
 
+// BestRank는 사용자 구매 게임 중 가장 높은 등수를 반환합니다 (0 = 낙첨)
+func BestRank(purchase UserPurchase, result *LottoResult) int {
+	bestRank := 0
+	for _, game := range purchase.Games {
+		rank, _, _ := CheckWinning(game.Numbers, result)
+		if rank > 0 && (bestRank == 0 || rank < bestRank) {
+			bestRank = rank
+		}
+	}
+	return bestRank
+}
+
 // FormatWinningMessage는 당첨 결과 메시지를 포맷합니다
 func FormatWinningMessage(userID string, result *LottoResult, history *PurchaseHistory) string {
+	return message.Text(message.EventWinning, message.Telegram, WinningMessage(userID, result, history))
+}
+
+// WinningMessage는 계정의 당첨 확인 결과를 메시지 템플릿 데이터로 만듭니다 (history가 nil이면 구매 내역 없음)
+func WinningMessage(userID string, result *LottoResult, history *PurchaseHistory) message.Winning {
+	data := message.Winning{
+		Account:  userID,
+		Round:    result.Round,
+		DrawDate: result.DrawDate,
+		Numbers:  result.Numbers,
+		Bonus:    result.BonusNumber,
+	}
+
 	if history == nil {
-		return fmt.Sprintf("(%s) ℹ️ <b>당첨 확인 불가</b>\n\n저장된 구매 내역이 없습니다.", userID)
+		data.Status = message.WinningNoHistory
+		return data
 	}
 
 	// 회차 확인
+	data.PurchaseRound = history.Round
 	if history.Round != result.Round {
-		return fmt.Sprintf("(%s) ℹ️ <b>당첨 확인 불가</b>\n\n구매 회차(%s회)와 추첨 회차(%s회)가 다릅니다.",
-			userID, history.Round, result.Round)
+		data.Status = message.WinningRoundMismatch
+		return data
 	}
 
 	userPurchase, exists := history.Users[userID]
-	if !exists {
-		return fmt.Sprintf("(%s) ℹ️ <b>당첨 확인 불가</b>\n\n%s회 구매 내역이 없습니다.", userID, result.Round)
-	}
-
-	if !userPurchase.Success || len(userPurchase.Games) == 0 {
-		return fmt.Sprintf("(%s) ℹ️ <b>당첨 확인 불가</b>\n\n%s회 구매가 실패했습니다.", userID, result.Round)
+	switch {
+	case !exists:
+		data.Status = message.WinningNoPurchase
+		return data
+	case IsApprovalSkip(userPurchase.Approval):
+		data.Status = message.WinningSkipped
+		return data
+	case !userPurchase.Success || len(userPurchase.Games) == 0:
+		data.Status = message.WinningFailed
+		return data
 	}
 
 	// 당첨 확인
-	msg := fmt.Sprintf("(%s) 🎰 <b>로또 %s회 당첨 결과</b>\n\n", userID, result.Round)
-	msg += fmt.Sprintf("🗓 추첨일: %s\n", result.DrawDate)
-	msg += "🎱 당첨번호: "
-	for i, num := range result.Numbers {
-		if i > 0 {
-			msg += ", "
-		}
-		msg += fmt.Sprintf("<b>%02d</b>", num)
-	}
-	msg += fmt.Sprintf("\n➕ 보너스: <b>%02d</b>\n\n", result.BonusNumber)
-	msg += "━━━━━━━━━━━━━━━━━━━━\n\n"
-
-	bestRank := 0
-	totalWinnings := 0
-
+	data.Status = message.WinningChecked
 	for _, game := range userPurchase.Games {
 		rank, matchCount, hasBonus := CheckWinning(game.Numbers, result)
 
-		// 게임 정보
-		msg += fmt.Sprintf("🎲 [%s 게임]\n", game.Type)
-		msg += "   번호: "
+		// 일치하는 번호 표시
+		hits := make([]bool, len(game.Numbers))
 		for i, num := range game.Numbers {
-			if i > 0 {
-				msg += ", "
-			}
-			// 일치하는 번호는 강조
-			isMatch := false
 			for _, wNum := range result.Numbers {
 				if num == wNum {
-					isMatch = true
+					hits[i] = true
 					break
 				}
 			}
-			if isMatch {
-				msg += fmt.Sprintf("✅<b>%02d</b>", num)
-			} else {
-				msg += fmt.Sprintf("%02d", num)
-			}
 		}
-		msg += "\n"
 
-		if rank > 0 {
-			msg += fmt.Sprintf("   🎉 <b>%d등 당첨!</b> (%d개 일치", rank, matchCount)
-			if hasBonus && rank == 2 {
-				msg += " + 보너스"
-			}
-			msg += ")\n"
+		data.Games = append(data.Games, message.Game{
+			Label:   game.Type,
+			Numbers: game.Numbers,
+			Rank:    rank,
+			Matches: matchCount,
+			Bonus:   hasBonus,
+			Hits:    hits,
+		})
 
-			if bestRank == 0 || rank < bestRank {
-				bestRank = rank
+		if rank > 0 {
+			if data.BestRank == 0 || rank < data.BestRank {
+				data.BestRank = rank
 			}
-			totalWinnings++
-		} else {
-			msg += fmt.Sprintf("   ❌ 낙첨 (%d개 일치)\n", matchCount)
-		}
-		msg += "\n"
-	}
-
-	msg += "━━━━━━━━━━━━━━━━━━━━\n"
-
-	if totalWinnings > 0 {
-		msg += fmt.Sprintf("\n🎊 <b>총 %d게임 당첨!</b>\n", totalWinnings)
-		if bestRank <= 3 {
-			msg += "💰 <b>고액 당첨! 축하합니다!</b> 🎉\n"
+			data.Wins++
 		}
-	} else {
-		msg += "\n아쉽지만 다음 기회에! 😊\n"
 	}
 
-	return msg
+	return data
 }
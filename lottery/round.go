@@ -0,0 +1,52 @@
+package lottery
+
+import (
+	"time"
+)
+
+// 판매 마감: 매주 토요일 오후 8시 (추첨일)
+const (
+	saleCloseWeekday = time.Saturday
+	saleCloseHour    = 20
+)
+
+// kst는 한국 표준시입니다 (시간대 데이터가 없으면 고정 오프셋 사용)
+var kst = loadKST()
+
+func loadKST() *time.Location {
+	location, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		return time.FixedZone("KST", 9*60*60)
+	}
+	return location
+}
+
+// firstDrawDate는 1회차 추첨일입니다 (이후 매주 토요일 1회차씩)
+var firstDrawDate = time.Date(2002, time.December, 7, 0, 0, 0, 0, kst)
+
+// Location은 판매 일정 기준 시간대(한국 표준시)를 반환합니다
+func Location() *time.Location {
+	return kst
+}
+
+// SaleDeadline은 now 기준으로 이번 회차의 판매 마감 시각(토요일 20:00)을 반환합니다
+func SaleDeadline(now time.Time) time.Time {
+	now = now.In(kst)
+
+	days := (int(saleCloseWeekday) - int(now.Weekday()) + 7) % 7
+	deadline := time.Date(now.Year(), now.Month(), now.Day()+days, saleCloseHour, 0, 0, 0, kst)
+
+	// 토요일 20시 이후라면 다음 회차 마감
+	if !deadline.After(now) {
+		deadline = deadline.AddDate(0, 0, 7)
+	}
+
+	return deadline
+}
+
+// RoundAt은 now 기준으로 판매 중인 회차 번호를 반환합니다 (SaleDeadline의 추첨일 기준)
+func RoundAt(now time.Time) int {
+	deadline := SaleDeadline(now)
+	drawDate := time.Date(deadline.Year(), deadline.Month(), deadline.Day(), 0, 0, 0, 0, kst)
+	return int(drawDate.Sub(firstDrawDate).Hours()/24)/7 + 1
+}
@@ -2,18 +2,48 @@ package main
 
 import (
 	"dhlottery/config"
+	"dhlottery/datadir"
+	"dhlottery/kakao"
 	"dhlottery/logger"
+	"dhlottery/lottery"
+	"dhlottery/message"
+	"dhlottery/notify"
+	"dhlottery/outbox"
 	"dhlottery/scheduler"
 	"dhlottery/tasks"
 	"dhlottery/telegram"
 	"flag"
+	"fmt"
+	"html"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 )
 
 func main() {
+	// 커맨드 라인 플래그 파싱
+	checkBalance := flag.Bool("check", false, "예치금 확인만 수행 (balance 명령과 같음, 이전 방식)")
+	once := flag.Bool("once", false, "예치금 확인 없이 즉시 1회 구매 (buy -skip-balance와 같음, 이전 방식)")
+	dryRun := flag.Bool("dryrun", false, "테스트 모드 (dryrun 명령과 같음, 이전 방식)")
+	serviceMode := flag.Bool("service", false, "스케줄러 모드 (serve 명령과 같음, 이전 방식)")
+	configPath := flag.String("config", "", "설정 파일 경로 (.json, .yaml, .toml / 기본: config.json, config.yaml, config.toml 순서로 찾음)")
+	dataDir := flag.String("data-dir", "", "로그, 구매 내역, 상태 파일을 저장할 디렉토리 (기본: DH_DATA_DIR 또는 설정 파일의 dataDir, 없으면 XDG 데이터 디렉토리)")
+
+	flag.Usage = printUsage
+	flag.Parse()
+
+	// 데이터 디렉토리 준비 (로그 파일보다 먼저)
+	configDataDir, err := config.DataDirSetting(*configPath)
+	if err != nil {
+		log.Printf("⚠️  설정 파일의 dataDir을 읽지 못해 기본 위치를 사용합니다: %v\n", err)
+	}
+	if err := datadir.Init(datadir.Resolve(*dataDir, configDataDir)); err != nil {
+		log.Fatalf("데이터 디렉토리 초기화 실패: %v", err)
+	}
+
 	// 로그 파일 초기화
 	if err := logger.Init(); err != nil {
 		log.Fatalf("로그 초기화 실패: %v", err)
@@ -24,124 +54,410 @@ func main() {
 	log.Println("║    동행복권 로또 6/45 자동 구매 프로그램    ║")
 	log.Println("╚════════════════════════════════════════╝")
 	log.Println()
+	log.Printf("📁 데이터 디렉토리: %s\n", datadir.Dir())
 
-	// 커맨드 라인 플래그 파싱
-	checkBalance := flag.Bool("check", false, "예치금 확인만 수행")
-	once := flag.Bool("once", false, "즉시 1회 구매 (기본값: 예치금 확인 후 구매)")
-	dryRun := flag.Bool("dryrun", false, "테스트 모드 (실제 구매 안함)")
-	serviceMode := flag.Bool("service", false, "스케줄러 모드 (매주 토요일 6시 구매)")
+	// 명령이 없으면 이전 방식의 플래그를 같은 명령으로 바꿔 실행 (플래그도 없으면 예치금 확인 후 구매)
+	args := flag.Args()
+	if len(args) == 0 {
+		args = legacyCommand(*checkBalance, *once, *dryRun, *serviceMode)
+	}
 
-	flag.Parse()
+	// 설정 파일, 비밀번호 금고, 데이터 백업을 다루는 명령은 설정 로드 전에 실행 (예: dhlottery init, dhlottery config validate)
+	if run, ok := setupCommands[args[0]]; ok {
+		if err := run(*configPath, args[1:]); err != nil {
+			log.Fatalf("❌ %v\n", err)
+		}
+		return
+	}
 
 	// 설정 로드
-	cfg, err := config.Load()
+	cfg, err := config.Load(*configPath)
 	if err != nil {
 		log.Fatalf("❌ 설정 로드 실패: %v\n", err)
 	}
+	if err := message.SetLanguage(cfg.Language); err != nil {
+		log.Fatalf("❌ %v\n", err)
+	}
 
 	// 설정 정보 출력
 	cfg.Print()
 	log.Println()
 
-	// 텔레그램 봇 초기화
-	var bot *telegram.Bot
-	if cfg.TelegramBotToken != "" && cfg.TelegramChatID != "" {
-		bot = telegram.New(cfg.TelegramBotToken, cfg.TelegramChatID)
+	// 알림 채널 초기화 (텔레그램, 슬랙, 디스코드, 카카오톡)
+	hub, applyHub := newHub(cfg)
+	applyHub()
+	switch {
+	case hub == nil:
+		log.Println("⚠️  알림 설정(텔레그램, 슬랙, 디스코드, 카카오톡)이 없습니다. 알림은 전송되지 않습니다.")
+	case hub.Bot() == nil:
+		log.Println("✅ 웹훅, 카카오톡 알림 초기화 완료 (텔레그램 설정 없음)")
+	default:
 		log.Println("✅ 텔레그램 봇 초기화 완료")
-	} else {
-		log.Println("⚠️  텔레그램 설정이 없습니다. 알림은 전송되지 않습니다.")
 	}
 
 	log.Println()
 
-	// 플래그에 따라 실행
+	// 알림 보관함의 발송기 시작 (outbox 명령은 보관함을 조회만 하므로 제외)
+	stopSender := func() {}
+	if notifications != nil && args[0] != "outbox" {
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			notifications.Run(stop)
+			close(done)
+		}()
+		stopSender = func() {
+			close(stop)
+			<-done
+		}
+	}
+
+	// 명령 실행 (예: dhlottery buy --account id1)
+	err = runCommand(*configPath, cfg, hub, args)
+
+	// 종료 전에 남은 알림 전송
+	stopSender()
+	if notifications != nil && args[0] != "outbox" {
+		flushNotifications()
+	}
+
+	if err != nil {
+		log.Fatalf("❌ %v\n", err)
+	}
+}
+
+// notifications는 알림 보관함입니다 (알림 설정이 없거나 열지 못하면 nil)
+var notifications *outbox.Outbox
+
+// flushTimeout은 종료 전에 남은 알림을 전송하는 최대 시간입니다
+const flushTimeout = 30 * time.Second
+
+// flushNotifications는 보낼 수 있는 알림을 전송하고, 남은 알림이 있으면 안내합니다
+func flushNotifications() {
+	if left := notifications.Flush(flushTimeout); left > 0 {
+		log.Printf("📮 전송하지 못한 알림 %d개가 보관함에 남아 있습니다 (다음 실행 때 다시 전송)\n", left)
+		log.Println("   dhlottery outbox list 로 확인할 수 있습니다")
+	}
+}
+
+// setupCommands는 설정을 로드하지 않고 실행하는 명령입니다
+var setupCommands = map[string]func(configPath string, args []string) error{
+	"init":    runInitCommand,
+	"config":  runConfigCommand,
+	"vault":   runVaultCommand,
+	"backup":  runBackupCommand,
+	"restore": runRestoreCommand,
+	"result":  runResultCommand,
+	"help": func(string, []string) error {
+		printUsage()
+		return nil
+	},
+}
+
+// legacyCommand는 이전 방식의 플래그를 명령으로 바꿉니다
+func legacyCommand(checkBalance, once, dryRun, serviceMode bool) []string {
+	var args []string
+	var flagName string
 	switch {
-	case *serviceMode:
-		// 스케줄러 모드만 (즉시 실행 없음)
-		runScheduler(cfg, bot)
+	case serviceMode:
+		args, flagName = []string{"serve"}, "-service"
+	case checkBalance:
+		args, flagName = []string{"balance"}, "-check"
+	case dryRun:
+		args, flagName = []string{"dryrun"}, "-dryrun"
+	case once:
+		args, flagName = []string{"buy", "-skip-balance"}, "-once"
+	default:
+		log.Println("🎯 기본 모드: 예치금 확인 후 1회 구매 실행 (dhlottery buy와 같음)")
+		return []string{"buy"}
+	}
 
-	case *checkBalance:
-		// 예치금 확인만
-		tasks.CheckBalance(cfg, bot)
+	log.Printf("ℹ️  %s 플래그 대신 \"dhlottery %s\" 명령을 사용하세요\n", flagName, strings.Join(args, " "))
+	return args
+}
 
-	case *dryRun:
-		// 테스트 모드
-		tasks.DryRun(cfg, bot)
+// newHub는 설정한 알림 채널(텔레그램 봇, 슬랙·디스코드 웹훅, 카카오톡)로 알림 Hub를 생성합니다 (하나도 없으면 nil)
+// 알림은 데이터 디렉토리의 보관함에 먼저 저장한 뒤 발송기가 전송합니다 (네트워크 장애나 재시작에도 유실되지 않음)
+// 보관함이 쓰는 전송 채널은 바꾸지 않으므로, 이 설정으로 전송하려면 반환한 apply를 호출해야 합니다 (다시 읽은 설정이 거부되면 호출하지 않음)
+func newHub(cfg config.Config) (hub *notify.Hub, apply func()) {
+	var bot *telegram.Bot
+	if cfg.TelegramBotToken != "" && cfg.TelegramChatID != "" {
+		bot = telegramBot(cfg, cfg.TelegramChatID)
+	}
+	talk := kakaoClient(cfg)
 
-	case *once:
-		// 즉시 1회 구매 (예치금 확인 없이)
-		tasks.BuyLotto(cfg, bot)
+	apply = func() {
+		if notifications == nil {
+			return
+		}
+		notifications.SetBot(bot)
+		if talk != nil {
+			notifications.SetDeliverer(kakao.Channel, talk.Post)
+		} else {
+			notifications.SetDeliverer(kakao.Channel, nil)
+		}
+	}
+
+	if bot == nil && talk == nil && !cfg.HasWebhooks() {
+		return nil, apply
+	}
+
+	if notifications == nil {
+		box, err := outbox.Open(datadir.Path(datadir.Outbox), nil)
+		if err != nil {
+			log.Printf("⚠️  %v (알림을 바로 전송합니다)\n", err)
+			return notify.NewHub(cfg, bot, talk, nil), apply
+		}
+		notifications = box
+	}
+	if bot != nil {
+		bot.SetQueue(notifications)
+	}
+	return notify.NewHub(cfg, bot, talk, notifications), apply
+}
+
+// kakaoClient는 설정의 카카오 앱과 리프레시 토큰으로 카카오톡 클라이언트를 생성합니다 (토큰이 없으면 nil)
+func kakaoClient(cfg config.Config) *kakao.Client {
+	tokens := cfg.KakaoTokens()
+	if len(tokens) == 0 {
+		return nil
+	}
+	return kakao.New(kakaoOptions(cfg), tokens)
+}
+
+// kakaoOptions는 설정의 카카오 앱 정보입니다
+func kakaoOptions(cfg config.Config) kakao.Options {
+	return kakao.Options{
+		ClientID:     cfg.Kakao.RestAPIKey,
+		ClientSecret: cfg.Kakao.ClientSecret,
+		APIURL:       cfg.Kakao.APIURL,
+		AuthURL:      cfg.Kakao.AuthURL,
+	}
+}
+
+// telegramBot은 설정의 API 연결 설정(주소, 프록시, 시간 제한)으로 봇을 생성합니다
+func telegramBot(cfg config.Config, chatID string) *telegram.Bot {
+	bot, err := telegram.NewWithOptions(cfg.TelegramBotToken, chatID, telegram.Options{
+		BaseURL: cfg.TelegramAPI.BaseURL,
+		Proxy:   cfg.TelegramAPI.Proxy,
+		Timeout: cfg.TelegramAPI.TimeoutDuration(),
+		Silent:  cfg.TelegramAPI.Silent,
+		Images:  cfg.TelegramAPI.Images,
+	})
+	if err != nil {
+		log.Printf("⚠️  %v (프록시 없이 연결)\n", err)
+	}
+	return bot
+}
 
+// jobFunc는 일정의 작업 종류에 맞는 실행 함수를 반환합니다
+// 실행 시점에 일시정지된 계정과, 구매 작업은 이번 회차를 이미 구매한 계정도 제외됩니다
+func jobFunc(entry config.Schedule, cfg config.Config, hub *notify.Hub, sched *scheduler.Scheduler) func() error {
+	var run func(cfg config.Config) error
+	switch entry.Job {
+	case config.JobBuy:
+		run = func(cfg config.Config) error {
+			cfg, ok := tasks.SkipPurchased(cfg)
+			if !ok {
+				log.Printf("⏭ %s: 모든 계정이 이번 회차를 이미 구매했습니다\n", entry.Label())
+				return nil
+			}
+			return tasks.CheckBalanceAndBuyWithRetry(cfg, hub, sched)
+		}
+	case config.JobBalance:
+		run = func(cfg config.Config) error {
+			_, err := tasks.CheckBalance(cfg, hub)
+			return err
+		}
 	default:
-		// 기본값: 즉시 예치금 확인 후 구매 (1회만 실행 후 종료)
-		log.Println("🎯 기본 모드: 예치금 확인 후 1회 구매 실행")
-		tasks.CheckBalance(cfg, bot)
-		tasks.BuyLotto(cfg, bot)
+		run = func(cfg config.Config) error { return tasks.CheckWinning(cfg, hub) }
+	}
+
+	return func() error {
+		active, ok := tasks.SkipPaused(cfg, hub, entry.Job, entry.Label())
+		if !ok {
+			log.Printf("⏸  %s: 모든 계정이 일시정지 중이라 실행하지 않습니다\n", entry.Label())
+			return nil
+		}
+		return run(active)
+	}
+}
+
+// registerSchedules는 설정된 일정을 스케줄러에 등록합니다
+func registerSchedules(cfg config.Config, hub *notify.Hub, sched *scheduler.Scheduler) error {
+	for _, entry := range cfg.ScheduleList() {
+		if !entry.IsEnabled() {
+			log.Printf("⏸  비활성화된 일정: %s (%s)\n", entry.JobName(), entry.Label())
+			continue
+		}
+
+		spec, err := entry.Spec()
+		if err != nil {
+			return fmt.Errorf("%s 스케줄 등록 실패: %w", entry.Label(), err)
+		}
+
+		description := entry.Label()
+		if len(entry.Accounts) > 0 {
+			description += fmt.Sprintf(" [%s]", strings.Join(entry.Accounts, ", "))
+		}
+
+		// 같은 종류의 작업(일정이 여러 개인 구매, 구매 재시도 등)은 겹쳐 실행되지 않도록 같은 묶음으로 등록
+		if err := sched.AddGroupJob(entry.JobName(), entry.Job, description, spec, jobFunc(entry, cfg.WithAccounts(entry.Accounts), hub, sched)); err != nil {
+			return fmt.Errorf("%s 스케줄 등록 실패: %w", entry.Label(), err)
+		}
+	}
+
+	return nil
+}
+
+// crashReporter는 작업 패닉을 공통 수신자에게 알리는 핸들러를 반환합니다
+func crashReporter(hub *notify.Hub) scheduler.PanicHandler {
+	return func(name string, recovered interface{}, stack []byte) {
+		if hub == nil {
+			return
+		}
+
+		trace := string(stack)
+		if len(trace) > 3000 {
+			trace = trace[:3000] + "\n..."
+		}
+
+		hub.Broadcast(fmt.Sprintf(
+			"💥 <b>작업 중 오류 발생</b>\n\n"+
+				"작업: %s\n"+
+				"오류: %s\n\n"+
+				"<pre>%s</pre>\n\n"+
+				"서비스는 계속 실행됩니다.",
+			html.EscapeString(name),
+			html.EscapeString(fmt.Sprint(recovered)),
+			html.EscapeString(trace),
+		), notify.Options{})
 	}
 }
 
 // runScheduler는 스케줄러를 실행합니다
-func runScheduler(cfg config.Config, bot *telegram.Bot) {
+// SIGHUP을 받으면 configPath의 설정을 다시 읽어 일정을 다시 등록합니다
+// filter가 있으면 고른 계정만 대상으로 하며, 다시 읽을 때도 같은 필터를 적용합니다
+func runScheduler(configPath string, cfg config.Config, hub *notify.Hub, filter *accountFilter) {
 	log.Println("🔄 스케줄러 모드 시작")
 	log.Println()
 
-	// 시작 시 즉시 1회 실행
-	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	log.Println("    시작 시 즉시 예치금 확인 및 구매 실행")
-	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	log.Println()
-	tasks.CheckBalanceAndBuy(cfg, bot)
-	log.Println()
+	sched := scheduler.New()
+	sched.SetPanicHandler(crashReporter(hub))
+
+	// 설정된 일정 등록
+	if err := registerSchedules(cfg, hub, sched); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	// 실제 등록된 일정 출력
+	printJobs(sched)
 
-	// 스케줄 등록
+	// 재시작 전에 예약해 둔 구매 재시도 다시 예약
+	tasks.ResumeBuyRetries(cfg, hub, sched)
+
+	// 일시정지 상태 출력
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	log.Println("    예약된 스케줄:")
-	log.Println("    - 당첨 확인: 매주 월요일 오후 12시 50분")
-	log.Println("    - 예치금 확인: 매주 월요일 오후 1시")
-	log.Println("    - 로또 구매: 매주 월요일 오후 7시")
+	log.Println("    일시정지 상태:")
+	tasks.PrintPauseStatus(cfg)
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println()
 
-	sched := scheduler.New()
-
-	// 예치금 확인: 매주 월요일 오후 1시 (13:00)
-	if err := sched.AddFunc("0 13 * * 1", func() {
-		tasks.CheckBalance(cfg, bot)
-	}); err != nil {
-		log.Fatalf("❌ 예치금 확인 스케줄 등록 실패: %v", err)
-	}
+	// 서비스가 꺼져 있는 동안 놓친 이번 회차 작업만 실행
+	window := cfg.CatchUpWindowDuration()
+	if window > 0 {
+		roundStart := lottery.SaleDeadline(time.Now()).AddDate(0, 0, -7)
 
-	// 로또 구매: 매주 월요일 오후 7시 (19:00)
-	if err := sched.AddFunc("0 19 * * 1", func() {
-		tasks.CheckBalanceAndBuy(cfg, bot)
-	}); err != nil {
-		log.Fatalf("❌ 로또 구매 스케줄 등록 실패: %v", err)
-	}
-
-	// 당첨 확인: 매주 월요일 오후 12시 50분 (12:50)
-	if err := sched.AddFunc("50 12 * * 1", func() {
-		tasks.CheckWinning(cfg, bot)
-	}); err != nil {
-		log.Fatalf("❌ 당첨 확인 스케줄 등록 실패: %v", err)
+		log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		log.Printf("    놓친 작업 확인 (최대 %v 이내)\n", window)
+		log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		if missed := sched.RunMissed(window, roundStart); len(missed) == 0 {
+			log.Println("✅ 놓친 작업 없음")
+		}
+		log.Println()
 	}
 
 	sched.Start()
+	commands := startBotCommands(cfg, hub, sched)
 
 	log.Println("✅ 스케줄러 시작 완료")
 	log.Println("   프로그램이 백그라운드에서 실행됩니다.")
 	log.Println("   종료하려면 Ctrl+C를 누르세요.")
 	log.Println()
 
-	// 시그널 대기
+	if configPath != "" {
+		log.Printf("   설정 파일(%s)을 수정한 뒤 SIGHUP을 보내면 다시 읽습니다.\n", configPath)
+		log.Println()
+	}
+
+	// 시그널 대기 (SIGHUP은 설정 다시 읽기)
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	<-sigChan
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := range sigChan {
+		if sig != syscall.SIGHUP {
+			break
+		}
+		hub = reloadConfig(configPath, sched, hub, filter, commands)
+	}
 
 	log.Println()
 	log.Println("⚠️  종료 신호를 받았습니다.")
 	log.Println("   스케줄러를 중지합니다...")
 
+	commands.stop()
 	sched.Stop()
 
 	log.Println("✅ 프로그램 종료")
 }
+
+// printJobs는 등록된 일정과 다음 실행 시각을 출력합니다
+func printJobs(sched *scheduler.Scheduler) {
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	log.Println("    예약된 스케줄:")
+	for _, info := range sched.Jobs() {
+		log.Printf("    - %s: %s (다음 실행 %s)\n", info.Description, scheduler.DescribeSpec(info.Spec), info.Next.Format("01/02 15:04"))
+	}
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	log.Println()
+}
+
+// reloadConfig는 설정 파일을 다시 읽어 일정을 다시 등록하고, 새 설정의 알림 Hub를 반환합니다
+// 실행 중인 작업과 예약된 재시도는 그대로 유지되며, 설정에 오류가 있으면 기존 설정을 계속 사용합니다
+// 텔레그램 명령 수신기(commands)에도 새 설정을 넘깁니다
+func reloadConfig(configPath string, sched *scheduler.Scheduler, hub *notify.Hub, filter *accountFilter, commands *botCommands) *notify.Hub {
+	log.Println()
+	log.Println("🔄 설정 다시 읽기 (SIGHUP)")
+
+	if configPath == "" {
+		log.Println("⚠️  설정 파일 없이 실행 중이라 다시 읽을 파일이 없습니다")
+		return hub
+	}
+
+	cfg, err := config.LoadFromFile(configPath)
+	if err == nil {
+		cfg, err = filter.apply(cfg)
+	}
+	if err != nil {
+		log.Printf("❌ 설정 다시 읽기 실패, 기존 설정 유지: %v\n", err)
+		hub.Broadcast(fmt.Sprintf("❌ <b>설정 다시 읽기 실패</b>\n\n기존 설정으로 계속 실행합니다.\n\n<pre>%s</pre>", html.EscapeString(err.Error())), notify.Options{})
+		return hub
+	}
+
+	newHub, applyHub := newHub(cfg)
+	if err := sched.Reload(func() error { return registerSchedules(cfg, newHub, sched) }); err != nil {
+		log.Printf("❌ 일정 다시 등록 실패, 기존 일정 유지: %v\n", err)
+		return hub
+	}
+	applyHub()
+	sched.SetPanicHandler(crashReporter(newHub))
+	commands.setConfig(cfg, newHub)
+	if err := message.SetLanguage(cfg.Language); err != nil {
+		log.Printf("⚠️  %v\n", err)
+	}
+
+	cfg.Print()
+	printJobs(sched)
+	log.Println("✅ 설정 다시 읽기 완료")
+
+	return newHub
+}
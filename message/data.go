@@ -0,0 +1,223 @@
+package message
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 이벤트 (템플릿 파일 이름)
+const (
+	EventPurchase   = "purchase"    // 구매 결과 (Purchase)
+	EventWinning    = "winning"     // 당첨 확인 결과 (Winning)
+	EventBalance    = "balance"     // 예치금 알림 (Balance)
+	EventFailure    = "failure"     // 작업 단계 실패 (Failure)
+	EventPolicySkip = "policy-skip" // 구매 정책에 따라 구매 안 함 (PolicySkip)
+	EventDigest     = "digest"      // 여러 계정의 작업 결과 요약 (Digest)
+	EventWeekly     = "weekly"      // 당첨 확인 후 주간 요약 (Weekly)
+)
+
+// Purchase는 구매 결과 템플릿 데이터입니다
+type Purchase struct {
+	Account string
+	DryRun  bool // 테스트 모드 (실제 구매 안 함)
+	Success bool
+
+	// 실패 종류 (session: 세션 만료, device: 기기 제한, time: 판매 시간 아님, rejected: 구매 거부, unknown: 응답 확인 불가)
+	Failure string
+	Reason  string // 구매 거부 사유 (rejected)
+	Hint    string // 거부 사유 분류 (limit: 구매 한도, balance: 예치금 부족, time: 판매 시간, 없으면 빈 문자열)
+
+	Games        []Game
+	Quantity     int // 구매 게임 수
+	Amount       int // 구매 금액 (원)
+	Round        string
+	DrawDate     string
+	PayLimitDate string
+	Barcodes     []string
+}
+
+// Game은 한 게임의 번호입니다 (당첨 확인 시 Rank, Matches, Bonus, Hits 사용)
+type Game struct {
+	Label   string // A, B, C, D, E
+	Numbers []int
+	Mode    string // auto, manual, semi (구매 결과에서만, 알 수 없으면 빈 문자열)
+
+	Rank    int    // 당첨 등수 (0이면 낙첨)
+	Matches int    // 일치한 번호 수
+	Bonus   bool   // 보너스 번호 일치
+	Hits    []bool // 번호별 당첨번호 일치 여부 (Numbers와 같은 순서)
+}
+
+// Winning 상태
+const (
+	WinningChecked       = "checked"        // 당첨 확인 완료
+	WinningNoHistory     = "no-history"     // 저장된 구매 내역 없음
+	WinningRoundMismatch = "round-mismatch" // 구매 회차와 추첨 회차가 다름
+	WinningNoPurchase    = "no-purchase"    // 계정의 구매 내역 없음
+	WinningSkipped       = "skipped"        // 구매를 건너뛴 회차
+	WinningFailed        = "failed"         // 구매 실패한 회차
+)
+
+// Winning은 당첨 확인 결과 템플릿 데이터입니다 (Account가 비어 있으면 전체 계정 대상 메시지)
+type Winning struct {
+	Account       string
+	Status        string
+	Round         string // 추첨 회차
+	PurchaseRound string // 저장된 구매 내역의 회차
+	DrawDate      string
+	Numbers       []int
+	Bonus         int
+	Games         []Game
+	Wins          int // 당첨된 게임 수
+	BestRank      int // 가장 높은 당첨 등수 (0이면 낙첨)
+}
+
+// Balance 종류
+const (
+	BalanceLow          = "low"          // 예치금 확인 결과 기준 금액 미만
+	BalanceInsufficient = "insufficient" // 구매 금액보다 부족
+	BalanceNotice       = "notice"       // 구매는 가능하지만 기준 금액 미만
+)
+
+// Balance는 예치금 알림 템플릿 데이터입니다
+type Balance struct {
+	Account   string
+	Kind      string
+	Balance   int
+	Required  int // 구매에 필요한 금액 (insufficient)
+	Threshold int // 알림 기준 금액 (low, notice)
+}
+
+// Failure 종류 (어떤 작업이 실패했는지)
+const (
+	FailureBalance = "balance" // 예치금 확인
+	FailureLogin   = "login"   // 로그인
+	FailureBuy     = "buy"     // 로또 구매
+	FailureJob     = "job"     // 작업 전체
+	FailureRound   = "round"   // 회차 정보 조회
+	FailurePolicy  = "policy"  // 구매 정책 평가
+	FailureResult  = "result"  // 당첨번호 조회
+	FailureHistory = "history" // 구매 내역 조회
+)
+
+// Failure 단계 (작업 안에서 어느 단계에서 실패했는지, 없으면 빈 문자열)
+const (
+	StageClient = "client" // 클라이언트 생성
+	StageLogin  = "login"  // 로그인
+	StagePage   = "page"   // 구매 페이지 접근
+)
+
+// Failure는 작업 실패 템플릿 데이터입니다 (Account가 비어 있으면 전체 계정 대상 메시지)
+type Failure struct {
+	Account string
+	Kind    string
+	Stage   string
+	Detail  string // 오류 내용
+}
+
+// PolicySkip은 구매 정책에 따라 구매하지 않은 계정의 템플릿 데이터입니다
+type PolicySkip struct {
+	Account string
+	Reason  string // 정책 평가 결과 설명
+}
+
+// Digest 작업
+const (
+	DigestBalance  = "balance"  // 예치금 확인
+	DigestPurchase = "purchase" // 로또 구매 (예치금 확인 포함)
+)
+
+// Digest는 한 번의 작업에서 여러 계정의 결과를 모은 요약 템플릿 데이터입니다
+type Digest struct {
+	Task      string
+	Rows      []DigestRow
+	Games     int // 전체 구매 게임 수
+	Amount    int // 전체 구매 금액 (원)
+	Failures  int // 실패한 계정 수
+	Low       int // 예치금이 기준 금액 미만인 계정 수
+	Threshold int // 예치금 부족 기준 금액
+}
+
+// DigestRow는 요약의 계정별 결과입니다
+type DigestRow struct {
+	Account    string
+	HasBalance bool // 예치금을 확인함
+	Balance    int
+	Low        bool   // 예치금이 기준 금액 미만
+	Status     string // purchased, skipped, failed (예치금 확인 작업은 실패일 때만 failed)
+	Games      int
+	Amount     int
+	Reason     string // 건너뛴 이유 또는 실패 이유
+}
+
+// Weekly는 당첨 확인 후 보내는 회차 요약 템플릿 데이터입니다
+type Weekly struct {
+	Round        string
+	DrawDate     string
+	Numbers      []int
+	Bonus        int
+	Rows         []WeeklyRow
+	Spent        int  // 구매 금액 합계 (원)
+	Won          int  // 당첨금 합계 (원, 알려진 당첨금만)
+	PrizeUnknown bool // 당첨금을 알 수 없는 당첨이 있음
+	Wins         int  // 당첨된 게임 수
+	BestRank     int  // 가장 높은 당첨 등수 (0이면 낙첨)
+	LowBalances  []Balance
+	Threshold    int // 예치금 부족 기준 금액
+}
+
+// WeeklyRow는 회차 요약의 계정별 결과입니다
+type WeeklyRow struct {
+	Account  string
+	Status   string // Winning 상태 (checked, no-purchase 등)
+	Games    int
+	Spent    int
+	Won      int
+	Wins     int
+	BestRank int
+}
+
+// Events는 알림 라우팅에 쓸 수 있는 이벤트입니다 (세부 종류는 "failure.login"처럼 점 뒤에 붙임)
+var Events = []string{EventPurchase, EventWinning, EventBalance, EventFailure, EventPolicySkip, EventDigest, EventWeekly}
+
+// MatchEvent는 알림 이벤트가 규칙의 이벤트와 일치하는지 확인합니다 ("failure"는 "failure.login" 등 모든 세부 종류와 일치)
+func MatchEvent(pattern, event string) bool {
+	return pattern == event || strings.HasPrefix(event, pattern+".")
+}
+
+// Severity는 알림 중요도입니다 (라우팅 규칙의 minSeverity와 비교)
+type Severity int
+
+const (
+	SeverityInfo     Severity = iota // 구매 완료, 낙첨 등 일상 알림
+	SeverityWarning                  // 예치금 부족 등 확인이 필요한 알림
+	SeverityError                    // 로그인, 구매 등 작업 실패
+	SeverityCritical                 // 고액 당첨 (1~3등)
+)
+
+var severityNames = []string{"info", "warning", "error", "critical"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity는 중요도 이름(info, warning, error, critical)을 해석합니다
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if n == name {
+			return Severity(i), nil
+		}
+	}
+	return 0, fmt.Errorf("알 수 없는 중요도 %q (%s 중 하나)", name, strings.Join(severityNames, ", "))
+}
+
+// WinningSeverity는 당첨 확인 결과의 중요도입니다 (1~3등이면 critical)
+func WinningSeverity(bestRank int) Severity {
+	if bestRank >= 1 && bestRank <= 3 {
+		return SeverityCritical
+	}
+	return SeverityInfo
+}
@@ -0,0 +1,204 @@
+package message
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// safeFuncs는 결과를 직접 이스케이프하는 함수입니다 (자동 이스케이프를 덧붙이지 않음)
+var safeFuncs = map[string]bool{"esc": true, "b": true, "i": true, "code": true, "pre": true, "raw": true, "field": true}
+
+// parseTemplate은 템플릿을 읽고, 출력하는 모든 값이 대상 형식에 맞게 이스케이프되도록 고칩니다
+// ({{.Reason}}은 {{.Reason | esc}}가 되고, {{b .Round}}처럼 직접 이스케이프하는 함수는 그대로)
+func parseTemplate(name, text string, target Target) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs(target)).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			autoEscape(t.Tree.Root)
+		}
+	}
+	return tmpl, nil
+}
+
+func autoEscape(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			autoEscape(child)
+		}
+	case *parse.IfNode:
+		autoEscape(n.List)
+		autoEscape(n.ElseList)
+	case *parse.RangeNode:
+		autoEscape(n.List)
+		autoEscape(n.ElseList)
+	case *parse.WithNode:
+		autoEscape(n.List)
+		autoEscape(n.ElseList)
+	case *parse.ActionNode:
+		// 변수 선언은 출력하지 않음
+		if len(n.Pipe.Decl) > 0 || len(n.Pipe.Cmds) == 0 {
+			return
+		}
+		last := n.Pipe.Cmds[len(n.Pipe.Cmds)-1]
+		if id, ok := last.Args[0].(*parse.IdentifierNode); ok && safeFuncs[id.Ident] {
+			return
+		}
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      last.Pos,
+			Args:     []parse.Node{parse.NewIdentifier("esc").SetPos(last.Pos)},
+		})
+	}
+}
+
+// funcs는 템플릿에서 쓰는 함수입니다
+//
+//	esc 값        대상 형식에 맞게 이스케이프 (출력하는 값에는 자동으로 적용)
+//	b, i 값       굵게, 기울임
+//	code, pre 값  고정폭 (한 줄, 여러 줄)
+//	raw 값        이스케이프하지 않음 (사용자 템플릿에서 주의)
+//	money 금액    천 단위 구분 (5,000)
+//	num 번호      두 자리 번호 (07)
+//	nums 번호 구분자   번호 목록 (07 - 13 - 25)
+//	join 목록 구분자   문자열 목록 연결
+//	field 이름 값      카드 항목 (카드 템플릿의 "fields" 정의 안에서)
+func funcs(target Target) template.FuncMap {
+	esc := escaper(target)
+	wrap := func(open, close string) func(interface{}) string {
+		return func(v interface{}) string { return open + esc(v) + close }
+	}
+
+	m := template.FuncMap{
+		"esc":    esc,
+		"raw":    func(v interface{}) string { return fmt.Sprint(v) },
+		"money":  FormatMoney,
+		"num":    func(n int) string { return fmt.Sprintf("%02d", n) },
+		"nums":   formatNumbers,
+		"join":   strings.Join,
+		"target": func() string { return string(target) },
+		"field":  func(name string, value interface{}) string { return esc(name) + fieldSeparator + esc(value) + "\n" },
+	}
+	switch target {
+	case Telegram:
+		m["b"], m["i"], m["code"], m["pre"] = wrap("<b>", "</b>"), wrap("<i>", "</i>"), wrap("<code>", "</code>"), wrap("<pre>", "</pre>")
+	case Slack:
+		m["b"], m["i"], m["code"], m["pre"] = wrap("*", "*"), wrap("_", "_"), wrap("`", "`"), wrap("```\n", "\n```")
+	case Markdown, Discord:
+		m["b"], m["i"], m["code"], m["pre"] = wrap("**", "**"), wrap("_", "_"), wrap("`", "`"), wrap("```\n", "\n```")
+	default:
+		m["b"], m["i"], m["code"], m["pre"] = esc, esc, esc, esc
+	}
+	return m
+}
+
+// escaper는 대상 형식의 이스케이프 함수입니다
+func escaper(target Target) func(interface{}) string {
+	switch target {
+	case Telegram:
+		return func(v interface{}) string { return html.EscapeString(fmt.Sprint(v)) }
+	case Slack:
+		return func(v interface{}) string { return slackEscaper.Replace(fmt.Sprint(v)) }
+	case Markdown, Discord:
+		return func(v interface{}) string { return markdownEscaper.Replace(fmt.Sprint(v)) }
+	default:
+		return func(v interface{}) string { return fmt.Sprint(v) }
+	}
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "~", `\~`, "|", `\|`, "[", `\[`, "]", `\]`)
+
+// slackEscaper는 슬랙 mrkdwn의 제어 문자를 이스케이프합니다 (강조 기호는 이스케이프할 방법이 없음)
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// fieldSeparator는 field 함수가 항목 이름과 값 사이에 넣는 구분 문자입니다 (RenderCard에서 나눔)
+const fieldSeparator = "\x1f"
+
+// parseFields는 "fields" 정의의 출력을 카드 항목으로 나눕니다 (값이 빈 항목은 버림)
+func parseFields(text string) []Field {
+	var fields []Field
+	for _, line := range strings.Split(text, "\n") {
+		name, value, ok := strings.Cut(line, fieldSeparator)
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			continue
+		}
+		fields = append(fields, Field{Name: name, Value: value})
+	}
+	return fields
+}
+
+// htmlTagPattern은 텔레그램 HTML 메시지의 태그입니다
+var htmlTagPattern = regexp.MustCompile(`<(/?)([a-zA-Z]+)[^>]*>`)
+
+// Convert는 텔레그램 HTML 메시지(재시도, 일시정지 안내처럼 템플릿 없이 만든 문구)를 다른 대상 형식으로 바꿉니다
+// 굵게, 기울임, 고정폭 태그는 대상의 강조로 바꾸고, 다른 태그는 지웁니다
+func Convert(text string, target Target) string {
+	if target == Telegram {
+		return text
+	}
+	m := funcs(target)
+	esc := escaper(target)
+
+	// 대상의 강조 기호 (빈 문자열을 감싸 여는 기호와 닫는 기호를 얻음)
+	marks := make(map[string][2]string)
+	for tag, fn := range map[string]string{"b": "b", "strong": "b", "i": "i", "em": "i", "code": "code", "pre": "pre"} {
+		wrapped := m[fn].(func(interface{}) string)("\x00")
+		open, close, _ := strings.Cut(wrapped, "\x00")
+		marks[tag] = [2]string{open, close}
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, loc := range htmlTagPattern.FindAllStringSubmatchIndex(text, -1) {
+		sb.WriteString(esc(html.UnescapeString(text[last:loc[0]])))
+		last = loc[1]
+
+		closing, tag := text[loc[2]:loc[3]] == "/", strings.ToLower(text[loc[4]:loc[5]])
+		if mark, ok := marks[tag]; ok {
+			if closing {
+				sb.WriteString(mark[1])
+			} else {
+				sb.WriteString(mark[0])
+			}
+		}
+	}
+	sb.WriteString(esc(html.UnescapeString(text[last:])))
+	return sb.String()
+}
+
+// FormatMoney는 금액을 천 단위 구분자가 있는 문자열로 변환합니다
+func FormatMoney(amount int) string {
+	str := fmt.Sprintf("%d", amount)
+	if amount < 0 {
+		return "-" + FormatMoney(-amount)
+	}
+
+	var sb strings.Builder
+	for i, ch := range str {
+		if i > 0 && (len(str)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(ch)
+	}
+	return sb.String()
+}
+
+// formatNumbers는 번호를 두 자리로 맞춰 구분자로 연결합니다
+func formatNumbers(numbers []int, sep string) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = fmt.Sprintf("%02d", n)
+	}
+	return strings.Join(parts, sep)
+}
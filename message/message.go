@@ -0,0 +1,268 @@
+// Package message는 알림과 콘솔 출력 문구를 text/template 템플릿으로 만듭니다
+//
+// 템플릿은 이벤트(purchase, winning 등)와 출력 대상(console, telegram, markdown, plain, slack, discord, kakao)별로
+// <언어>/<이벤트>.<대상>.tmpl 파일에서 찾고, 없으면 대상 공통 템플릿 <언어>/<이벤트>.tmpl을 사용합니다.
+// 슬랙, 디스코드, 카카오톡은 그 사이에 카드 템플릿 <언어>/<이벤트>.card.tmpl을 찾습니다 (RenderCard 참고).
+// 데이터 디렉토리의 templates/<언어>/ 폴더에 같은 이름의 파일을 두면 내장 템플릿 대신 사용합니다.
+package message
+
+import (
+	"dhlottery/datadir"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Target은 문구를 출력할 대상입니다 (대상마다 강조, 이스케이프 방식이 다름)
+type Target string
+
+const (
+	Console  Target = "console"  // 콘솔 로그 (꾸밈 없음)
+	Telegram Target = "telegram" // 텔레그램 HTML
+	Markdown Target = "markdown" // 마크다운
+	Plain    Target = "plain"    // 꾸밈 없는 텍스트
+	Slack    Target = "slack"    // 슬랙 mrkdwn
+	Discord  Target = "discord"  // 디스코드 마크다운
+	Kakao    Target = "kakao"    // 카카오톡 (꾸밈 없는 텍스트)
+)
+
+// cardTemplate은 슬랙, 디스코드, 카카오톡이 함께 쓰는 카드 템플릿 이름입니다 (<이벤트>.card.tmpl)
+const cardTemplate = "card"
+
+// cardTargets는 카드 템플릿을 찾는 대상입니다
+var cardTargets = []Target{Slack, Discord, Kakao}
+
+// DefaultLanguage는 기본 언어이며, 다른 언어에 없는 템플릿도 이 언어에서 찾습니다
+const DefaultLanguage = "ko"
+
+// Languages는 내장 템플릿이 있는 언어입니다
+var Languages = []string{"ko", "en"}
+
+//go:embed templates
+var bundled embed.FS
+
+var (
+	mu       sync.Mutex
+	language = DefaultLanguage
+	cache    = make(map[string]*template.Template)
+)
+
+// Supported는 내장 템플릿이 있는 언어인지 확인합니다
+func Supported(lang string) bool {
+	for _, l := range Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// SetLanguage는 사용할 언어를 정하고, 읽어 둔 템플릿을 비웁니다 (설정 다시 읽기 시 사용자 템플릿도 다시 읽음)
+func SetLanguage(lang string) error {
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+	if !Supported(lang) {
+		return fmt.Errorf("지원하지 않는 언어입니다: %q (%s)", lang, strings.Join(Languages, ", "))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	language = lang
+	cache = make(map[string]*template.Template)
+	return nil
+}
+
+// Language는 현재 언어를 반환합니다
+func Language() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return language
+}
+
+// Render는 이벤트 문구를 대상 형식으로 만듭니다
+// 사용자 템플릿이 잘못되었으면 경고를 남기고 내장 템플릿을 사용합니다
+func Render(event string, target Target, data interface{}) (string, error) {
+	card, err := RenderCard(event, target, data)
+	return card.Body, err
+}
+
+// Card는 제목과 항목이 있는 알림입니다 (슬랙 Block Kit, 디스코드 임베드, 카카오톡 피드·리스트)
+type Card struct {
+	Title  string  // 제목 (템플릿의 "title" 정의, 없으면 빈 문자열)
+	Fields []Field // 항목 (템플릿의 "fields" 정의에서 field로 출력한 값, 값이 빈 항목은 빠짐)
+	Body   string  // 본문 (템플릿 전체)
+	Footer string  // 꼬리말 (템플릿의 "footer" 정의)
+}
+
+// Field는 카드 항목입니다
+type Field struct {
+	Name  string
+	Value string
+}
+
+// RenderCard는 이벤트 문구를 카드로 만듭니다
+// 템플릿에 {{define "title"}}, {{define "fields"}}, {{define "footer"}}가 있으면 카드의 각 부분이 되고,
+// 없으면 본문만 채웁니다 (공통 템플릿을 쓰는 이벤트)
+func RenderCard(event string, target Target, data interface{}) (Card, error) {
+	var errs []error
+	for _, source := range candidates(Language(), event, target) {
+		tmpl, err := load(source, target)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		card, err := executeCard(tmpl, data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source, err))
+			continue
+		}
+		for _, err := range errs {
+			log.Printf("⚠️  메시지 템플릿 오류, 다른 템플릿을 사용합니다: %v\n", err)
+		}
+		return card, nil
+	}
+
+	if len(errs) == 0 {
+		return Card{}, fmt.Errorf("%s 메시지 템플릿이 없습니다", event)
+	}
+	return Card{}, errors.Join(errs...)
+}
+
+// executeCard는 템플릿 본문과 카드 부분 정의를 실행합니다
+func executeCard(tmpl *template.Template, data interface{}) (Card, error) {
+	execute := func(t *template.Template) (string, error) {
+		var sb strings.Builder
+		if err := t.Execute(&sb, data); err != nil {
+			return "", err
+		}
+		// 파일 끝 줄바꿈은 메시지에 포함하지 않음
+		return strings.TrimRight(sb.String(), "\n"), nil
+	}
+
+	var card Card
+	var err error
+	if card.Body, err = execute(tmpl); err != nil {
+		return Card{}, err
+	}
+
+	parts := map[string]*string{"title": &card.Title, "footer": &card.Footer}
+	var fields string
+	parts["fields"] = &fields
+	for name, out := range parts {
+		t := tmpl.Lookup(name)
+		if t == nil {
+			continue
+		}
+		text, err := execute(t)
+		if err != nil {
+			return Card{}, err
+		}
+		*out = strings.TrimSpace(text)
+	}
+	card.Fields = parseFields(fields)
+	return card, nil
+}
+
+// Text는 Render와 같지만, 실패하면 로그를 남기고 오류를 담은 문구를 반환합니다 (알림이 빠지지 않도록)
+func Text(event string, target Target, data interface{}) string {
+	text, err := Render(event, target, data)
+	if err != nil {
+		log.Printf("❌ 메시지 생성 실패 (%s): %v\n", event, err)
+		return fmt.Sprintf("⚠️ %s: %v", event, err)
+	}
+	return text
+}
+
+// Log는 이벤트 문구를 콘솔 형식으로 만들어 한 줄씩 로그로 출력합니다
+func Log(event string, data interface{}) {
+	for _, line := range strings.Split(Text(event, Console, data), "\n") {
+		log.Println(line)
+	}
+}
+
+// source는 템플릿 파일 위치입니다 (override가 true면 데이터 디렉토리의 사용자 템플릿)
+type source struct {
+	override bool
+	name     string // <언어>/<파일 이름>
+}
+
+func (s source) String() string {
+	if s.override {
+		return datadir.Path(datadir.Templates, s.name)
+	}
+	return "내장 " + s.name
+}
+
+// candidates는 템플릿을 찾을 순서입니다
+// 대상 전용 템플릿이 (슬랙, 디스코드, 카카오톡은 카드 템플릿이) 공통 템플릿보다, 같은 단계에서는 사용자 템플릿이 내장 템플릿보다 먼저이며,
+// 현재 언어에 없으면 기본 언어에서 찾습니다
+func candidates(lang, event string, target Target) []source {
+	langs := []string{lang}
+	if lang != DefaultLanguage {
+		langs = append(langs, DefaultLanguage)
+	}
+
+	files := []string{event + "." + string(target) + ".tmpl"}
+	if slices.Contains(cardTargets, target) {
+		files = append(files, event+"."+cardTemplate+".tmpl")
+	}
+	files = append(files, event+".tmpl")
+
+	var list []source
+	for _, l := range langs {
+		for _, file := range files {
+			name := path.Join(l, file)
+			list = append(list, source{override: true, name: name}, source{name: name})
+		}
+	}
+	return list
+}
+
+// load는 템플릿을 읽어 대상의 함수와 자동 이스케이프를 적용합니다 (한 번 읽은 템플릿은 재사용)
+func load(src source, target Target) (*template.Template, error) {
+	key := fmt.Sprintf("%v|%s|%s", src.override, src.name, target)
+
+	mu.Lock()
+	tmpl, ok := cache[key]
+	mu.Unlock()
+	if ok {
+		if tmpl == nil {
+			return nil, fs.ErrNotExist
+		}
+		return tmpl, nil
+	}
+
+	var data []byte
+	var err error
+	if src.override {
+		data, err = os.ReadFile(datadir.Path(datadir.Templates, src.name))
+	} else {
+		data, err = bundled.ReadFile(path.Join("templates", src.name))
+	}
+	if err == nil {
+		tmpl, err = parseTemplate(src.name, string(data), target)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", src, err)
+		}
+	}
+
+	// 없는 파일도 기억해 두어 매번 찾지 않음 (잘못된 파일은 고칠 수 있도록 기억하지 않음)
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		mu.Lock()
+		cache[key] = tmpl
+		mu.Unlock()
+	}
+	return tmpl, err
+}
@@ -0,0 +1,217 @@
+package message
+
+import (
+	"dhlottery/datadir"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// 사용자가 정하는 값(별칭, 오류 내용)에 들어올 수 있는 HTML 특수 문자
+const (
+	hostileAlias  = `<b>"Tom & Jerry"</b>`
+	hostileReason = `잔액 < 1,000 & "한도" > 5 </pre><a href="x">`
+)
+
+// telegramTag는 템플릿이 만드는 텔레그램 HTML 태그입니다
+var telegramTag = regexp.MustCompile(`^</?(b|i|code|pre)>`)
+
+// telegramEntity는 완전한 HTML 엔티티입니다
+var telegramEntity = regexp.MustCompile(`^&(#[0-9]+|[a-z]+);`)
+
+// useTempTemplates는 테스트마다 빈 데이터 디렉토리와 새 템플릿 캐시를 씁니다
+func useTempTemplates(t *testing.T, lang string) {
+	t.Helper()
+	if err := datadir.Init(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLanguage(lang); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetLanguage(DefaultLanguage) })
+}
+
+// checkTelegramHTML은 텔레그램이 거부하지 않을 HTML인지 검사합니다 (허용 태그만, 태그 짝, 완전한 엔티티)
+func checkTelegramHTML(t *testing.T, text string) {
+	t.Helper()
+
+	var open []string
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '<':
+			tag := telegramTag.FindString(text[i:])
+			if tag == "" {
+				t.Fatalf("이스케이프되지 않은 < 또는 허용되지 않은 태그: %q", text[i:min(len(text), i+20)])
+			}
+			name := strings.Trim(tag, "</>")
+			if strings.HasPrefix(tag, "</") {
+				if len(open) == 0 || open[len(open)-1] != name {
+					t.Fatalf("짝이 맞지 않는 닫는 태그 %s: %q", tag, text)
+				}
+				open = open[:len(open)-1]
+			} else {
+				open = append(open, name)
+			}
+			i += len(tag) - 1
+		case '>':
+			t.Fatalf("이스케이프되지 않은 >: %q", text[max(0, i-20):i+1])
+		case '&':
+			if !telegramEntity.MatchString(text[i:]) {
+				t.Fatalf("이스케이프되지 않은 &: %q", text[i:min(len(text), i+20)])
+			}
+		}
+	}
+	if len(open) > 0 {
+		t.Errorf("닫히지 않은 태그 %v: %q", open, text)
+	}
+}
+
+// plainText는 텔레그램 HTML에서 태그를 지우고 엔티티를 되돌린 글자입니다
+func plainText(text string) string {
+	return html.UnescapeString(regexp.MustCompile(`</?(b|i|code|pre)>`).ReplaceAllString(text, ""))
+}
+
+func hostileGames() []Game {
+	return []Game{
+		{Label: "A", Numbers: []int{1, 7, 13, 25, 33, 45}, Mode: "auto", Rank: 5, Matches: 3, Hits: []bool{true, true, true, false, false, false}},
+		{Label: "B", Numbers: []int{2, 8, 14, 26, 34, 44}, Mode: "manual", Hits: make([]bool, 6)},
+	}
+}
+
+// hostileData는 이벤트별로 사용자 값에 특수 문자를 넣은 템플릿 데이터입니다
+var hostileData = []struct {
+	name  string
+	event string
+	data  interface{}
+}{
+	{"구매 성공", EventPurchase, Purchase{Account: hostileAlias, Success: true, Games: hostileGames(), Quantity: 2, Amount: 2000, Round: "1247", DrawDate: "2026-10-24", PayLimitDate: "2027-10-25"}},
+	{"구매 거부", EventPurchase, Purchase{Account: hostileAlias, Failure: "rejected", Reason: hostileReason, Hint: "limit"}},
+	{"구매 확인 불가", EventPurchase, Purchase{Account: hostileAlias, Failure: "unknown"}},
+	{"당첨 확인", EventWinning, Winning{Account: hostileAlias, Status: WinningChecked, Round: "1247", DrawDate: "2026-10-24", Numbers: []int{1, 7, 13, 20, 30, 40}, Bonus: 45, Games: hostileGames(), Wins: 1, BestRank: 5}},
+	{"당첨 확인 불가", EventWinning, Winning{Account: hostileAlias, Status: WinningRoundMismatch, Round: "1247", PurchaseRound: "1246"}},
+	{"예치금 부족", EventBalance, Balance{Account: hostileAlias, Kind: BalanceInsufficient, Balance: 3000, Required: 5000}},
+	{"예치금 알림", EventBalance, Balance{Account: hostileAlias, Kind: BalanceNotice, Balance: 8000, Threshold: 10000}},
+	{"로그인 실패", EventFailure, Failure{Account: hostileAlias, Kind: FailureLogin, Stage: StageLogin, Detail: hostileReason}},
+	{"정책 건너뜀", EventPolicySkip, PolicySkip{Account: hostileAlias, Reason: hostileReason}},
+	{"구매 요약", EventDigest, Digest{
+		Task: DigestPurchase, Games: 5, Amount: 5000, Failures: 1, Low: 1, Threshold: 10000,
+		Rows: []DigestRow{
+			{Account: hostileAlias, HasBalance: true, Balance: 8000, Low: true, Status: "purchased", Games: 5, Amount: 5000},
+			{Account: hostileAlias, Status: "skipped", Reason: hostileReason},
+			{Account: hostileAlias, Status: "failed", Reason: hostileReason},
+		},
+	}},
+	{"주간 요약", EventWeekly, Weekly{
+		Round: "1247", DrawDate: "2026-10-24", Numbers: []int{1, 7, 13, 20, 30, 40}, Bonus: 45,
+		Rows: []WeeklyRow{
+			{Account: hostileAlias, Status: WinningChecked, Games: 5, Spent: 5000, Won: 5000, Wins: 1, BestRank: 5},
+			{Account: hostileAlias, Status: WinningSkipped},
+		},
+		Spent: 5000, Won: 5000, Wins: 1, BestRank: 5, Threshold: 10000,
+		LowBalances: []Balance{{Account: hostileAlias, Balance: 3000}},
+	}},
+}
+
+func TestRenderTelegramEscapesUserValues(t *testing.T) {
+	for _, lang := range Languages {
+		t.Run(lang, func(t *testing.T) {
+			useTempTemplates(t, lang)
+			for _, tt := range hostileData {
+				t.Run(tt.name, func(t *testing.T) {
+					text, err := Render(tt.event, Telegram, tt.data)
+					if err != nil {
+						t.Fatal(err)
+					}
+					checkTelegramHTML(t, text)
+					if !strings.Contains(plainText(text), hostileAlias) {
+						t.Errorf("별칭이 그대로 보여야 함: %q", text)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestRenderOtherTargetsEscapeUserValues(t *testing.T) {
+	for _, lang := range Languages {
+		useTempTemplates(t, lang)
+		for _, tt := range hostileData {
+			// 슬랙은 <, >, &가 링크와 멘션 문법이라 이스케이프해야 함
+			slack, err := Render(tt.event, Slack, tt.data)
+			if err != nil {
+				t.Fatalf("%s %s: %v", lang, tt.name, err)
+			}
+			if strings.Contains(slack, "<b>") || strings.Contains(slack, "</pre>") {
+				t.Errorf("%s %s: 슬랙 문구에 이스케이프되지 않은 태그: %q", lang, tt.name, slack)
+			}
+
+			// 콘솔은 꾸밈도 이스케이프도 없이 그대로
+			console, err := Render(tt.event, Console, tt.data)
+			if err != nil {
+				t.Fatalf("%s %s: %v", lang, tt.name, err)
+			}
+			if strings.Contains(console, "&lt;") || strings.Contains(console, "&amp;") {
+				t.Errorf("%s %s: 콘솔 문구는 값을 그대로 보여야 함: %q", lang, tt.name, console)
+			}
+		}
+	}
+}
+
+// writeTemplate은 데이터 디렉토리에 사용자 템플릿을 씁니다
+func writeTemplate(t *testing.T, name, text string) {
+	t.Helper()
+	path := datadir.Path(datadir.Templates, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(text), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUserTemplateOverrides(t *testing.T) {
+	useTempTemplates(t, "ko")
+	writeTemplate(t, "ko/policy-skip.tmpl", "사용자 템플릿 {{b .Account}}: {{.Reason}}\n")
+	writeTemplate(t, "ko/purchase.telegram.tmpl", "텔레그램 전용 {{.Account}}")
+	writeTemplate(t, "ko/balance.tmpl", "{{if}} 잘못된 템플릿")
+	SetLanguage("ko") // 읽어 둔 템플릿 비우기
+
+	data := PolicySkip{Account: hostileAlias, Reason: hostileReason}
+	text, err := Render(EventPolicySkip, Telegram, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(text, "사용자 템플릿 <b>") {
+		t.Errorf("사용자 템플릿을 써야 함: %q", text)
+	}
+	checkTelegramHTML(t, text)
+	if want := "사용자 템플릿 " + hostileAlias + ": " + hostileReason; plainText(text) != want {
+		t.Errorf("사용자 템플릿 값도 이스케이프되어야 함\n got: %q\nwant: %q", plainText(text), want)
+	}
+
+	// 대상 전용 템플릿은 그 대상에만 적용
+	purchase := Purchase{Account: "me", Failure: "unknown"}
+	if text, _ := Render(EventPurchase, Telegram, purchase); text != "텔레그램 전용 me" {
+		t.Errorf("텔레그램 전용 템플릿을 써야 함: %q", text)
+	}
+	if text, _ := Render(EventPurchase, Markdown, purchase); strings.Contains(text, "텔레그램 전용") {
+		t.Errorf("다른 대상은 내장 템플릿을 써야 함: %q", text)
+	}
+
+	// 잘못된 사용자 템플릿은 내장 템플릿으로 대신함
+	text, err = Render(EventBalance, Telegram, Balance{Kind: BalanceLow, Balance: 3000, Threshold: 10000})
+	if err != nil || !strings.Contains(text, "3,000") {
+		t.Errorf("내장 템플릿으로 대신해야 함: %q, %v", text, err)
+	}
+
+	// 다른 언어에서도 없는 템플릿은 기본 언어의 사용자 템플릿을 씀
+	if err := SetLanguage("en"); err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := Render(EventPolicySkip, Telegram, data); strings.HasPrefix(text, "사용자 템플릿") {
+		t.Errorf("en 내장 템플릿이 ko 사용자 템플릿보다 먼저여야 함: %q", text)
+	}
+}
@@ -0,0 +1,173 @@
+package notify
+
+import (
+	"dhlottery/discord"
+	"dhlottery/kakao"
+	"dhlottery/message"
+	"dhlottery/slack"
+	"dhlottery/telegram"
+	"html"
+	"strings"
+	"time"
+)
+
+// telegramNotifier는 텔레그램 채팅방입니다 (HTML 문구와 사진)
+type telegramNotifier struct {
+	bot *telegram.Bot
+}
+
+func (t telegramNotifier) Key() string {
+	return t.bot.ChatID
+}
+
+func (t telegramNotifier) Send(msg Message, opts Options) {
+	t.bot.SendSafe(msg.Text, telegram.SendOptions{Silent: opts.Silent, NotBefore: opts.NotBefore})
+}
+
+func (t telegramNotifier) SendPhotos(photos []telegram.Photo, opts Options) {
+	t.bot.SendPhotosSafe(photos, telegram.SendOptions{Silent: opts.Silent, NotBefore: opts.NotBefore})
+}
+
+// slackNotifier는 슬랙 incoming webhook입니다 (Block Kit)
+type slackNotifier struct {
+	hook *slack.Webhook
+}
+
+func (s slackNotifier) Key() string {
+	return webhookKey(slack.Channel, s.hook.URL())
+}
+
+func (s slackNotifier) Send(msg Message, opts Options) {
+	s.hook.SendSafe(slackPayload(msg), opts.NotBefore)
+}
+
+// slackPayload는 알림을 Block Kit 메시지로 만듭니다
+// 제목은 header, 항목은 두 열 section, 본문은 section, 꼬리말은 context 블록입니다
+func slackPayload(msg Message) slack.Payload {
+	card := card(msg, message.Slack)
+	// header 블록은 plain_text라 mrkdwn 이스케이프를 되돌림
+	title := html.UnescapeString(card.Title)
+
+	var blocks []slack.Block
+	if title != "" {
+		blocks = append(blocks, slack.Header(title))
+	}
+	if len(card.Fields) > 0 {
+		fields := make([]slack.Text, len(card.Fields))
+		for i, f := range card.Fields {
+			fields[i] = *slack.Mrkdwn("*" + f.Name + "*\n" + f.Value)
+		}
+		blocks = append(blocks, slack.Fields(fields...))
+	}
+	if card.Body != "" {
+		blocks = append(blocks, slack.Section(card.Body))
+	}
+	if card.Footer != "" {
+		blocks = append(blocks, slack.Context(card.Footer))
+	}
+
+	// 알림 미리보기에 쓰이는 대체 문구 (mrkdwn이라 이스케이프한 제목, 제목이 없으면 본문 첫 줄)
+	fallback := card.Title
+	if fallback == "" {
+		fallback, _, _ = strings.Cut(card.Body, "\n")
+	}
+	return slack.Payload{Text: fallback, Blocks: blocks}
+}
+
+// discordNotifier는 디스코드 웹훅입니다 (임베드)
+type discordNotifier struct {
+	hook *discord.Webhook
+}
+
+func (d discordNotifier) Key() string {
+	return webhookKey(discord.Channel, d.hook.URL())
+}
+
+func (d discordNotifier) Send(msg Message, opts Options) {
+	payload := discordPayload(msg)
+	if opts.Silent {
+		payload.Flags = discord.FlagSuppressNotifications
+	}
+	d.hook.SendSafe(payload, opts.NotBefore)
+}
+
+// discordPayload는 알림을 임베드 하나로 만듭니다 (색은 중요도, 항목은 한 줄에 여러 개)
+func discordPayload(msg Message) discord.Payload {
+	card := card(msg, message.Discord)
+
+	embed := discord.Embed{
+		Title:       card.Title,
+		Description: card.Body,
+		Color:       discordColor(msg.Severity),
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+	for _, f := range card.Fields {
+		embed.Fields = append(embed.Fields, discord.Field{Name: f.Name, Value: f.Value, Inline: true})
+	}
+	if card.Footer != "" {
+		embed.Footer = &discord.Footer{Text: card.Footer}
+	}
+	return discord.Payload{Embeds: []discord.Embed{embed}}
+}
+
+// discordColor는 중요도별 임베드 색입니다
+func discordColor(severity message.Severity) int {
+	switch severity {
+	case message.SeverityWarning:
+		return discord.ColorWarning
+	case message.SeverityError:
+		return discord.ColorError
+	case message.SeverityCritical:
+		return discord.ColorCritical
+	}
+	return discord.ColorInfo
+}
+
+// kakaoNotifier는 카카오톡 나에게 보내기입니다 (피드, 리스트, 텍스트 템플릿)
+type kakaoNotifier struct {
+	memo *kakao.Memo
+	link kakao.Link
+}
+
+func (k kakaoNotifier) Key() string {
+	return k.memo.Key()
+}
+
+// 카카오톡은 소리 없는 전송을 지원하지 않아 opts.Silent는 무시
+func (k kakaoNotifier) Send(msg Message, opts Options) {
+	k.memo.SendSafe(kakaoTemplate(msg, k.link), opts.NotBefore)
+}
+
+// kakaoTemplate은 알림을 카카오톡 메시지 템플릿으로 만듭니다
+// 당첨 결과처럼 항목이 여럿인 알림은 리스트(항목마다 한 줄), 구매 결과처럼 항목이 있는 알림은
+// 피드(제목, 본문, 아이템 목록), 나머지는 제목과 본문을 이은 텍스트 템플릿입니다
+func kakaoTemplate(msg Message, link kakao.Link) kakao.Template {
+	card := card(msg, message.Kakao)
+	event, _, _ := strings.Cut(msg.Event, ".")
+
+	switch {
+	case event == message.EventWinning && len(card.Fields) >= kakao.MinListContents:
+		contents := make([]kakao.Content, len(card.Fields))
+		for i, f := range card.Fields {
+			contents[i] = kakao.Content{Title: f.Name, Description: f.Value, Link: link}
+		}
+		return kakao.List(card.Title, link, contents)
+
+	case len(card.Fields) > 0:
+		items := make([]kakao.Item, len(card.Fields))
+		for i, f := range card.Fields {
+			items[i] = kakao.Item{Item: f.Name, ItemOp: f.Value}
+		}
+		description := card.Body
+		if card.Footer != "" {
+			description += "\n" + card.Footer
+		}
+		return kakao.Feed(kakao.Content{Title: card.Title, Description: description, Link: link}, items)
+	}
+
+	text := card.Body
+	if card.Title != "" {
+		text = card.Title + "\n\n" + text
+	}
+	return kakao.Text(text, link)
+}
@@ -0,0 +1,156 @@
+// Package notify는 알림 채널(텔레그램, 슬랙, 디스코드, 카카오톡)을 하나의 Notifier 인터페이스로 다룹니다
+//
+// 작업은 알림 데이터와 텔레그램 HTML 문구를 함께 넘기고, 채널마다 자기 형식으로 보냅니다.
+// 텔레그램은 HTML 문구를 그대로, 슬랙은 Block Kit, 디스코드는 임베드, 카카오톡은 피드·리스트·텍스트
+// 템플릿으로 보내며, 구매 결과와 당첨 결과는 카드 템플릿(<이벤트>.card.tmpl, 카카오톡은
+// <이벤트>.kakao.tmpl)으로 제목과 항목을 나눠 표시합니다.
+package notify
+
+import (
+	"crypto/sha256"
+	"dhlottery/config"
+	"dhlottery/discord"
+	"dhlottery/kakao"
+	"dhlottery/message"
+	"dhlottery/slack"
+	"dhlottery/telegram"
+	"dhlottery/webhook"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Notifier는 알림을 받는 곳 하나입니다 (텔레그램 채팅방, 슬랙·디스코드 웹훅, 카카오톡 사용자)
+type Notifier interface {
+	// Key는 전송 기록(중복 제거, 전송 제한)에서 받는 곳을 구분하는 이름입니다
+	Key() string
+	// Send는 알림을 보냅니다 (보관함이 있으면 보관함에 넣고, 실패는 로그로 남김)
+	Send(msg Message, opts Options)
+}
+
+// PhotoSender는 사진도 보낼 수 있는 Notifier입니다 (텔레그램)
+type PhotoSender interface {
+	SendPhotos(photos []telegram.Photo, opts Options)
+}
+
+// Message는 보낼 알림입니다
+type Message struct {
+	Event    string           // 이벤트 (세부 종류 포함, 예: failure.login, 비우면 템플릿 없이 만든 안내 문구)
+	Severity message.Severity // 중요도 (디스코드 임베드 색)
+	Data     interface{}      // 템플릿 데이터 (슬랙, 디스코드, 카카오톡은 이 데이터로 다시 만듦, 없으면 Text를 변환)
+	Text     string           // 텔레그램 HTML 문구
+}
+
+// Options는 알림별 전송 옵션입니다
+type Options struct {
+	Silent    bool      // 알림 소리 없이 전송 (텔레그램, 디스코드)
+	NotBefore time.Time // 이 시각 이후에 전송 (조용한 시간, 보관함이 있을 때만 적용)
+}
+
+// Queue는 웹훅, 카카오톡 알림 보관함입니다 (outbox)
+type Queue interface {
+	webhook.Queue
+	kakao.Queue
+}
+
+// Hub는 설정한 알림 채널을 묶어 받는 곳별 Notifier를 만듭니다
+// nil Hub는 알림을 보내지 않습니다
+type Hub struct {
+	bot      *telegram.Bot // 텔레그램 봇 (설정이 없으면 nil)
+	talk     *kakao.Client // 카카오 앱 (설정이 없으면 nil)
+	queue    Queue         // 웹훅, 카카오톡 알림 보관함 (없으면 바로 전송)
+	profile  discord.Profile
+	link     kakao.Link           // 카카오톡 메시지를 눌렀을 때 열 주소
+	defaults []config.Destination // 공통 수신자
+}
+
+// defaultKakaoLink는 카카오톡 메시지를 눌렀을 때 여는 기본 주소입니다 (동행복권)
+const defaultKakaoLink = "https://www.dhlottery.co.kr"
+
+// KakaoLink는 카카오톡 메시지를 눌렀을 때 열 주소입니다 (kakao.linkUrl, 없으면 동행복권)
+func KakaoLink(cfg config.Config) kakao.Link {
+	if cfg.Kakao.LinkURL != "" {
+		return kakao.WebLink(cfg.Kakao.LinkURL)
+	}
+	return kakao.WebLink(defaultKakaoLink)
+}
+
+// NewHub는 설정의 알림 채널로 Hub를 만듭니다
+// bot과 talk는 텔레그램, 카카오 설정이 없으면 nil이고, queue가 nil이면 웹훅, 카카오톡 알림을 바로 보냅니다
+func NewHub(cfg config.Config, bot *telegram.Bot, talk *kakao.Client, queue Queue) *Hub {
+	return &Hub{
+		bot:      bot,
+		talk:     talk,
+		queue:    queue,
+		profile:  discord.Profile{Username: cfg.Discord.Username, AvatarURL: cfg.Discord.AvatarURL},
+		link:     KakaoLink(cfg),
+		defaults: cfg.DefaultDestinations(),
+	}
+}
+
+// Bot은 텔레그램 봇입니다 (구매 승인처럼 텔레그램에서만 되는 기능에 사용, 없으면 nil)
+func (h *Hub) Bot() *telegram.Bot {
+	if h == nil {
+		return nil
+	}
+	return h.bot
+}
+
+// Images는 구매 번호, 당첨 결과 이미지를 보내는지 확인합니다 (텔레그램 설정)
+func (h *Hub) Images() bool {
+	return h.Bot().ImagesEnabled()
+}
+
+// To는 받는 곳의 Notifier를 반환합니다 (채널을 쓸 수 없으면 nil)
+func (h *Hub) To(dest config.Destination) Notifier {
+	if h == nil {
+		return nil
+	}
+	switch dest.Channel {
+	case config.ChannelTelegram:
+		if h.bot == nil {
+			return nil
+		}
+		return telegramNotifier{bot: h.bot.WithChatID(dest.Target)}
+	case config.ChannelSlack:
+		return slackNotifier{hook: slack.New(dest.Target, h.queue)}
+	case config.ChannelDiscord:
+		return discordNotifier{hook: discord.New(dest.Target, h.profile, h.queue)}
+	case config.ChannelKakao:
+		if h.talk == nil {
+			return nil
+		}
+		return kakaoNotifier{memo: h.talk.Memo(dest.Target, h.queue), link: h.link}
+	}
+	return nil
+}
+
+// Broadcast는 템플릿 없이 만든 안내 문구(텔레그램 HTML)를 공통 수신자 모두에게 보냅니다 (재시도, 일시정지, 작업 오류 안내)
+func (h *Hub) Broadcast(text string, opts Options) {
+	if h == nil {
+		return
+	}
+	for _, dest := range h.defaults {
+		if n := h.To(dest); n != nil {
+			n.Send(Message{Text: text}, opts)
+		}
+	}
+}
+
+// card는 슬랙, 디스코드, 카카오톡 형식의 알림 카드를 만듭니다 (템플릿 데이터가 없거나 실패하면 텔레그램 문구를 변환한 본문만)
+func card(msg Message, target message.Target) message.Card {
+	if msg.Data != nil && msg.Event != "" {
+		event, _, _ := strings.Cut(msg.Event, ".")
+		card, err := message.RenderCard(event, target, msg.Data)
+		if err == nil {
+			return card
+		}
+	}
+	return message.Card{Body: message.Convert(msg.Text, target)}
+}
+
+// webhookKey는 웹훅의 전송 기록 이름입니다 (토큰이 든 주소 대신 해시)
+func webhookKey(channel, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return channel + ":" + hex.EncodeToString(sum[:6])
+}
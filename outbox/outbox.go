@@ -0,0 +1,536 @@
+package outbox
+
+import (
+	"crypto/rand"
+	"dhlottery/telegram"
+	"dhlottery/webhook"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 보관함 폴더 (데이터 디렉토리의 outbox 아래)
+const (
+	pendingDir = "pending" // 전송 대기
+	sendingDir = "sending" // 전송 중 (다른 발송기가 가져가지 못하게 옮겨 둠)
+	deadDir    = "dead"    // 전송을 포기한 메시지 (replay -dead로 다시 보냄)
+)
+
+// 재시도 설정
+const (
+	baseBackoff  = 30 * time.Second // 첫 재시도 대기 시간 (실패할 때마다 두 배)
+	maxBackoff   = time.Hour        // 재시도 대기 시간 상한
+	maxAttempts  = 20               // 이만큼 실패하면 dead로 옮김
+	staleClaim   = 10 * time.Minute // 전송 중 상태로 이보다 오래 남은 메시지는 대기로 되돌림 (발송 중 종료된 경우)
+	pollInterval = 30 * time.Second // 발송기가 대기 중인 메시지를 다시 확인하는 간격
+)
+
+// Message는 보관함에 저장된 알림입니다
+// 텔레그램 알림은 ChatID로, 웹훅 알림(슬랙, 디스코드)은 Channel, Webhook, Payload로,
+// 그 밖의 채널 알림(카카오톡)은 Channel, Target, Payload로 보냅니다
+type Message struct {
+	ID          string           `json:"id"`
+	ChatID      string           `json:"chatId,omitempty"`
+	Text        string           `json:"text,omitempty"`
+	Photos      []telegram.Photo `json:"photos,omitempty"`  // 사진 알림 (Text 대신)
+	Channel     string           `json:"channel,omitempty"` // 채널 (slack, discord, kakao, 비우면 텔레그램)
+	Webhook     string           `json:"webhook,omitempty"` // 웹훅 주소
+	Target      string           `json:"target,omitempty"`  // 채널의 받는 곳 (카카오톡은 토큰 대신 kakao.Key)
+	Payload     json.RawMessage  `json:"payload,omitempty"` // 웹훅, 채널로 보낼 JSON 본문
+	Silent      bool             `json:"silent,omitempty"`
+	Sent        int              `json:"sent,omitempty"` // 나눠 보내는 메시지에서 이미 보낸 부분 수 (다음 시도는 여기부터)
+	CreatedAt   time.Time        `json:"createdAt"`
+	Attempts    int              `json:"attempts"`
+	NextAttempt time.Time        `json:"nextAttempt,omitempty"` // 다음 재시도 시각 (조용한 시간에 미룬 알림은 보낼 시각)
+	LastError   string           `json:"lastError,omitempty"`
+	DeadAt      time.Time        `json:"deadAt,omitempty"`
+}
+
+// Deliverer는 채널 알림 하나를 보냅니다 (카카오톡처럼 토큰을 관리하는 채널)
+type Deliverer func(target string, payload []byte) error
+
+// Outbox는 알림을 파일로 저장해 두고 텔레그램, 웹훅, 채널로 전달하는 보관함입니다
+// 메시지 하나가 파일 하나이고, 폴더 사이의 이름 바꾸기로 상태를 옮기므로
+// 여러 프로세스(serve와 단발 명령)가 같은 보관함을 함께 써도 한 번씩만 전송됩니다
+type Outbox struct {
+	dir string
+
+	mu         sync.Mutex
+	bot        *telegram.Bot
+	deliverers map[string]Deliverer // 채널 이름 → 전송 함수
+	wake       chan struct{}
+
+	sendMu sync.Mutex // 이 프로세스 안에서는 한 번에 하나씩 전송 (Run과 Flush)
+}
+
+// Open은 dir의 보관함을 엽니다 (폴더가 없으면 생성)
+// bot이 nil이면 텔레그램 알림은 저장과 조회만 하고 전송은 하지 않습니다 (웹훅 알림은 전송)
+func Open(dir string, bot *telegram.Bot) (*Outbox, error) {
+	for _, sub := range []string{pendingDir, sendingDir, deadDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return nil, fmt.Errorf("알림 보관함 디렉토리 생성 실패: %w", err)
+		}
+	}
+	return &Outbox{dir: dir, bot: bot, deliverers: map[string]Deliverer{}, wake: make(chan struct{}, 1)}, nil
+}
+
+// SetBot은 전송에 사용할 봇을 바꿉니다 (설정 다시 읽기)
+func (o *Outbox) SetBot(bot *telegram.Bot) {
+	o.mu.Lock()
+	o.bot = bot
+	o.mu.Unlock()
+	o.notify()
+}
+
+func (o *Outbox) currentBot() *telegram.Bot {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.bot
+}
+
+// SetDeliverer는 채널 알림을 보낼 함수를 바꿉니다 (nil이면 그 채널 알림은 저장과 조회만 함)
+func (o *Outbox) SetDeliverer(channel string, d Deliverer) {
+	o.mu.Lock()
+	if d == nil {
+		delete(o.deliverers, channel)
+	} else {
+		o.deliverers[channel] = d
+	}
+	o.mu.Unlock()
+	o.notify()
+}
+
+func (o *Outbox) deliverer(channel string) Deliverer {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.deliverers[channel]
+}
+
+// Enqueue는 메시지를 보관함에 저장합니다 (telegram.Queue 구현)
+func (o *Outbox) Enqueue(chatID, text string, opts telegram.SendOptions) error {
+	return o.enqueue(Message{ChatID: chatID, Text: text, Silent: opts.Silent, NextAttempt: opts.NotBefore})
+}
+
+// EnqueuePhotos는 사진 알림을 보관함에 저장합니다 (telegram.Queue 구현)
+func (o *Outbox) EnqueuePhotos(chatID string, photos []telegram.Photo, opts telegram.SendOptions) error {
+	return o.enqueue(Message{ChatID: chatID, Photos: photos, Silent: opts.Silent, NextAttempt: opts.NotBefore})
+}
+
+// EnqueueWebhook은 웹훅 알림을 보관함에 저장합니다 (webhook.Queue 구현)
+func (o *Outbox) EnqueueWebhook(channel, url string, payload []byte, notBefore time.Time) error {
+	return o.enqueue(Message{Channel: channel, Webhook: url, Payload: payload, NextAttempt: notBefore})
+}
+
+// EnqueueChannel은 채널 알림을 보관함에 저장합니다 (kakao.Queue 구현)
+func (o *Outbox) EnqueueChannel(channel, target string, payload []byte, notBefore time.Time) error {
+	return o.enqueue(Message{Channel: channel, Target: target, Payload: payload, NextAttempt: notBefore})
+}
+
+// IsWebhook은 웹훅 알림인지 확인합니다
+func (m Message) IsWebhook() bool {
+	return m.Webhook != ""
+}
+
+// IsChannel은 Deliverer로 보내는 채널 알림인지 확인합니다
+func (m Message) IsChannel() bool {
+	return m.Target != ""
+}
+
+func (o *Outbox) enqueue(msg Message) error {
+	id, err := newID(time.Now())
+	if err != nil {
+		return err
+	}
+	msg.ID = id
+	msg.CreatedAt = time.Now()
+	if err := o.write(pendingDir, msg); err != nil {
+		return err
+	}
+	o.notify()
+	return nil
+}
+
+// notify는 대기 중인 발송기를 깨웁니다
+func (o *Outbox) notify() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run은 stop이 닫힐 때까지 대기 중인 메시지를 전송합니다 (백그라운드 발송기)
+func (o *Outbox) Run(stop <-chan struct{}) {
+	for {
+		next := o.deliverDue(time.Time{})
+
+		wait := pollInterval
+		if !next.IsZero() {
+			wait = min(max(time.Until(next), time.Second), pollInterval)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-o.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// Flush는 지금 보낼 수 있는 메시지를 timeout 안에 전송하고 남은 메시지 수를 반환합니다
+// 재시도 대기 중인 메시지는 기다리지 않습니다 (다음 실행 또는 serve 모드의 발송기가 전송)
+func (o *Outbox) Flush(timeout time.Duration) int {
+	o.deliverDue(time.Now().Add(timeout))
+	pending, err := o.Pending()
+	if err != nil {
+		log.Printf("⚠️  %v\n", err)
+	}
+	return len(pending)
+}
+
+// deliverDue는 보낼 때가 된 메시지를 오래된 순서로 전송하고, 다음 재시도 시각을 반환합니다
+// deadline이 지나면 남은 메시지는 다음으로 미룹니다 (zero면 제한 없음)
+func (o *Outbox) deliverDue(deadline time.Time) time.Time {
+	o.sendMu.Lock()
+	defer o.sendMu.Unlock()
+
+	o.recoverStale()
+
+	bot := o.currentBot()
+	pending, err := o.Pending()
+	if err != nil {
+		log.Printf("⚠️  %v\n", err)
+		return time.Time{}
+	}
+
+	var next time.Time
+	for _, msg := range pending {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+		// 텔레그램(채널) 설정이 없으면 그 알림은 남겨 둠
+		if !o.deliverable(bot, msg) {
+			continue
+		}
+		if msg.NextAttempt.After(time.Now()) {
+			if next.IsZero() || msg.NextAttempt.Before(next) {
+				next = msg.NextAttempt
+			}
+			continue
+		}
+		if retryAt := o.deliver(bot, msg); !retryAt.IsZero() && (next.IsZero() || retryAt.Before(next)) {
+			next = retryAt
+		}
+	}
+	return next
+}
+
+// deliverable은 지금 설정으로 메시지를 보낼 수 있는지 확인합니다
+func (o *Outbox) deliverable(bot *telegram.Bot, msg Message) bool {
+	switch {
+	case msg.IsWebhook():
+		return true
+	case msg.IsChannel():
+		return o.deliverer(msg.Channel) != nil
+	}
+	return bot != nil
+}
+
+// deliver는 메시지 하나를 전송합니다
+// 실패하면 재시도 시각을 정해 대기로 되돌리고 그 시각을 반환합니다 (포기하면 dead로 옮김)
+func (o *Outbox) deliver(bot *telegram.Bot, msg Message) time.Time {
+	// 다른 발송기가 먼저 가져갔으면 건너뜀
+	claimed := o.path(sendingDir, msg.ID)
+	if err := os.Rename(o.path(pendingDir, msg.ID), claimed); err != nil {
+		return time.Time{}
+	}
+	now := time.Now()
+	_ = os.Chtimes(claimed, now, now)
+
+	var err error
+	switch {
+	case msg.IsWebhook():
+		err = webhook.Post(msg.Channel, msg.Webhook, msg.Payload)
+	case msg.IsChannel():
+		if deliver := o.deliverer(msg.Channel); deliver != nil {
+			err = deliver(msg.Target, msg.Payload)
+		} else {
+			err = fmt.Errorf("%s 알림 설정이 없습니다", msg.Channel)
+		}
+	case len(msg.Photos) > 0:
+		msg.Sent, err = bot.WithChatID(msg.ChatID).SendPhotosFrom(msg.Photos, msg.Sent, telegram.SendOptions{Silent: msg.Silent})
+	default:
+		msg.Sent, err = bot.WithChatID(msg.ChatID).SendFrom(msg.Text, msg.Sent, telegram.SendOptions{Silent: msg.Silent})
+	}
+	if err == nil {
+		if err := os.Remove(claimed); err != nil {
+			log.Printf("⚠️  전송한 알림을 보관함에서 지우지 못했습니다: %v\n", err)
+		}
+		return time.Time{}
+	}
+
+	msg.Attempts++
+	msg.LastError = err.Error()
+	if permanent(err) || msg.Attempts >= maxAttempts {
+		msg.DeadAt = time.Now()
+		log.Printf("❌ 알림 전송 포기 (%s, %d회 시도): %v\n", msg.ID, msg.Attempts, err)
+		log.Println("   dhlottery outbox list -dead 로 확인하고 outbox replay -dead 로 다시 보낼 수 있습니다")
+		o.move(claimed, deadDir, msg)
+		return time.Time{}
+	}
+
+	msg.NextAttempt = time.Now().Add(backoff(msg.Attempts))
+	log.Printf("⚠️  알림 전송 실패, %s에 다시 시도합니다 (%d회 실패): %v\n", msg.NextAttempt.Format("15:04:05"), msg.Attempts, err)
+	o.move(claimed, pendingDir, msg)
+	return msg.NextAttempt
+}
+
+// move는 전송 중인 메시지를 갱신된 내용으로 다른 폴더에 옮깁니다
+func (o *Outbox) move(claimed, sub string, msg Message) {
+	if err := o.write(sub, msg); err != nil {
+		// 내용을 갱신하지 못하면 원래 파일을 그대로 되돌려 메시지는 잃지 않음
+		log.Printf("⚠️  %v\n", err)
+		if err := os.Rename(claimed, o.path(sub, msg.ID)); err != nil {
+			log.Printf("⚠️  알림 보관함 파일 이동 실패: %v\n", err)
+		}
+		return
+	}
+	_ = os.Remove(claimed)
+}
+
+// recoverStale은 전송 중에 프로그램이 종료되어 남은 메시지를 대기로 되돌립니다
+func (o *Outbox) recoverStale() {
+	entries, err := os.ReadDir(filepath.Join(o.dir, sendingDir))
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !isMessageFile(entry.Name()) || time.Since(info.ModTime()) < staleClaim {
+			continue
+		}
+		if err := os.Rename(filepath.Join(o.dir, sendingDir, entry.Name()), filepath.Join(o.dir, pendingDir, entry.Name())); err == nil {
+			log.Printf("ℹ️  전송 중에 멈춘 알림을 다시 대기열에 넣었습니다: %s\n", strings.TrimSuffix(entry.Name(), ".json"))
+		}
+	}
+}
+
+// permanent는 다시 보내도 성공할 수 없는 오류인지 반환합니다 (잘못된 메시지, 차단된 채팅방, 삭제된 웹훅, 만료된 토큰 등)
+// 웹훅, 채널 오류는 Permanent 메서드로 판단합니다
+// 나눠 보낸 부분이 나누면서 생긴 형식 오류나 길이 초과로 거부되면 telegram.Bot.SendFrom이 다시 나누거나 서식 없이 보내므로,
+// 여기까지 오는 텔레그램 400 응답은 메시지 자체의 문제입니다
+func permanent(err error) bool {
+	var channelErr interface{ Permanent() bool }
+	if errors.As(err, &channelErr) {
+		return channelErr.Permanent()
+	}
+	var apiErr *telegram.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == http.StatusBadRequest || apiErr.Code == http.StatusForbidden
+}
+
+// backoff는 attempts번 실패한 뒤의 재시도 대기 시간입니다
+func backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+// Pending은 전송 대기 중인 메시지를 오래된 순서로 반환합니다
+func (o *Outbox) Pending() ([]Message, error) {
+	return o.list(pendingDir)
+}
+
+// Dead는 전송을 포기한 메시지를 오래된 순서로 반환합니다
+func (o *Outbox) Dead() ([]Message, error) {
+	return o.list(deadDir)
+}
+
+// Get은 대기 또는 dead 폴더에서 메시지를 찾습니다 (dead는 찾은 메시지가 dead인지 여부)
+func (o *Outbox) Get(id string) (msg Message, dead bool, err error) {
+	if !isValidID(id) {
+		return Message{}, false, fmt.Errorf("잘못된 알림 ID: %s", id)
+	}
+	for _, sub := range []string{pendingDir, sendingDir, deadDir} {
+		msg, err := o.read(o.path(sub, id))
+		if err == nil {
+			return msg, sub == deadDir, nil
+		}
+		if !os.IsNotExist(err) {
+			return Message{}, false, err
+		}
+	}
+	return Message{}, false, fmt.Errorf("보관함에 %s 알림이 없습니다", id)
+}
+
+// Replay는 메시지를 바로 다시 보내도록 재시도 대기를 풉니다 (ids가 비어 있으면 전체)
+// dead가 true이면 전송을 포기한 메시지를 시도 횟수를 초기화해 대기로 되돌립니다
+func (o *Outbox) Replay(ids []string, dead bool) (int, error) {
+	sub := pendingDir
+	if dead {
+		sub = deadDir
+	}
+
+	targets, err := o.list(sub)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) > 0 {
+		wanted := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			wanted[id] = true
+		}
+		var selected []Message
+		for _, msg := range targets {
+			if wanted[msg.ID] {
+				selected = append(selected, msg)
+				delete(wanted, msg.ID)
+			}
+		}
+		for id := range wanted {
+			return 0, fmt.Errorf("%s 폴더에 %s 알림이 없습니다", sub, id)
+		}
+		targets = selected
+	}
+
+	for _, msg := range targets {
+		msg.NextAttempt = time.Time{}
+		if dead {
+			msg.Attempts = 0
+			msg.DeadAt = time.Time{}
+		}
+		if err := o.write(pendingDir, msg); err != nil {
+			return 0, err
+		}
+		if dead {
+			if err := os.Remove(o.path(deadDir, msg.ID)); err != nil {
+				return 0, fmt.Errorf("dead 알림 파일 삭제 실패: %w", err)
+			}
+		}
+	}
+	o.notify()
+	return len(targets), nil
+}
+
+// Drop은 대기 또는 dead 폴더의 메시지를 삭제합니다
+func (o *Outbox) Drop(id string) error {
+	if !isValidID(id) {
+		return fmt.Errorf("잘못된 알림 ID: %s", id)
+	}
+	for _, sub := range []string{pendingDir, deadDir} {
+		err := os.Remove(o.path(sub, id))
+		if err == nil {
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("알림 파일 삭제 실패: %w", err)
+		}
+	}
+	return fmt.Errorf("보관함에 %s 알림이 없습니다 (전송 중인 알림은 삭제할 수 없습니다)", id)
+}
+
+// list는 폴더의 메시지를 ID(생성 시각) 순서로 읽습니다
+func (o *Outbox) list(sub string) ([]Message, error) {
+	entries, err := os.ReadDir(filepath.Join(o.dir, sub))
+	if err != nil {
+		return nil, fmt.Errorf("알림 보관함 읽기 실패: %w", err)
+	}
+
+	var messages []Message
+	for _, entry := range entries {
+		if entry.IsDir() || !isMessageFile(entry.Name()) {
+			continue
+		}
+		msg, err := o.read(filepath.Join(o.dir, sub, entry.Name()))
+		if err != nil {
+			// 그 사이 다른 발송기가 옮긴 파일은 건너뜀
+			if !os.IsNotExist(err) {
+				log.Printf("⚠️  %v\n", err)
+			}
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, nil
+}
+
+func (o *Outbox) read(path string) (Message, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Message{}, err
+	}
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return Message{}, fmt.Errorf("알림 파일 파싱 실패 (%s): %w", filepath.Base(path), err)
+	}
+	return msg, nil
+}
+
+// write는 메시지를 임시 파일에 쓴 뒤 이름을 바꿔 저장합니다 (쓰는 도중 종료되어도 깨진 파일이 남지 않음)
+func (o *Outbox) write(sub string, msg Message) error {
+	data, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON 마샬링 실패: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(o.dir, sub), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("알림 파일 생성 실패: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), o.path(sub, msg.ID))
+	}
+	if err != nil {
+		return fmt.Errorf("알림 파일 저장 실패: %w", err)
+	}
+	return nil
+}
+
+func (o *Outbox) path(sub, id string) string {
+	return filepath.Join(o.dir, sub, id+".json")
+}
+
+// newID는 생성 시각 순서로 정렬되는 메시지 ID를 만듭니다 (예: 20261019-153000.123456789-1a2b3c)
+func newID(t time.Time) (string, error) {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("알림 ID 생성 실패: %w", err)
+	}
+	return t.UTC().Format("20060102-150405.000000000") + "-" + hex.EncodeToString(suffix), nil
+}
+
+func isMessageFile(name string) bool {
+	return strings.HasSuffix(name, ".json") && !strings.HasPrefix(name, ".")
+}
+
+// isValidID는 경로 구분자 없이 ID 형식인지 확인합니다 (명령 인자로 다른 파일을 가리키지 못하게)
+func isValidID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\`) && !strings.HasPrefix(id, ".")
+}
@@ -0,0 +1,156 @@
+package pause
+
+import (
+	"dhlottery/datadir"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// stateFileName은 데이터 디렉토리의 state 폴더에 저장하는 일시정지 상태 파일입니다
+const stateFileName = "pause_state.json"
+
+// Entry는 일시정지 설정입니다 (Until이 비어 있으면 재개할 때까지 계속)
+type Entry struct {
+	Until    time.Time `json:"until,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	PausedAt time.Time `json:"pausedAt"`
+}
+
+// ActiveAt은 일시정지가 t 시점에 유효한지 반환합니다
+func (e Entry) ActiveAt(t time.Time) bool {
+	return e.Until.IsZero() || t.Before(e.Until)
+}
+
+// Describe는 일시정지 사유와 해제 시각을 설명합니다
+func (e Entry) Describe() string {
+	text := "재개할 때까지"
+	if !e.Until.IsZero() {
+		text = e.Until.Format("2006/01/02 15:04") + "까지"
+	}
+	if e.Reason != "" {
+		text += " (" + e.Reason + ")"
+	}
+	return text
+}
+
+// State는 전체 및 계정별 일시정지 상태입니다
+type State struct {
+	Global   *Entry           `json:"global,omitempty"`
+	Accounts map[string]Entry `json:"accounts,omitempty"`
+}
+
+// Load는 저장된 일시정지 상태를 읽어옵니다 (파일이 없으면 빈 상태)
+func Load() (*State, error) {
+	state := &State{Accounts: make(map[string]Entry)}
+
+	data, err := os.ReadFile(datadir.Path(datadir.State, stateFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, fmt.Errorf("일시정지 상태 파일 읽기 실패: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return &State{Accounts: make(map[string]Entry)}, fmt.Errorf("일시정지 상태 파일 파싱 실패: %w", err)
+	}
+	if state.Accounts == nil {
+		state.Accounts = make(map[string]Entry)
+	}
+
+	return state, nil
+}
+
+// Save는 일시정지 상태를 저장합니다 (만료된 항목은 정리, 중간에 끊겨도 기존 상태 유지)
+func (s *State) Save() error {
+	s.prune(time.Now())
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON 마샬링 실패: %w", err)
+	}
+
+	if err := datadir.WriteFile(datadir.Path(datadir.State, stateFileName), data); err != nil {
+		return fmt.Errorf("일시정지 상태 파일 저장 실패: %w", err)
+	}
+
+	return nil
+}
+
+// prune은 만료된 일시정지를 제거합니다
+func (s *State) prune(now time.Time) {
+	if s.Global != nil && !s.Global.ActiveAt(now) {
+		s.Global = nil
+	}
+	for id, entry := range s.Accounts {
+		if !entry.ActiveAt(now) {
+			delete(s.Accounts, id)
+		}
+	}
+}
+
+// Pause는 계정을 일시정지합니다 (userID가 비어 있으면 전체)
+func (s *State) Pause(userID string, entry Entry) {
+	if userID == "" {
+		s.Global = &entry
+		return
+	}
+	s.Accounts[userID] = entry
+}
+
+// Resume은 일시정지를 해제하고, 해제된 일시정지가 있었는지 반환합니다 (userID가 비어 있으면 전체)
+func (s *State) Resume(userID string) bool {
+	if userID == "" {
+		resumed := s.Global != nil
+		s.Global = nil
+		return resumed
+	}
+
+	_, resumed := s.Accounts[userID]
+	delete(s.Accounts, userID)
+	return resumed
+}
+
+// Active는 계정에 적용 중인 일시정지를 반환합니다 (전체 일시정지 우선)
+func (s *State) Active(userID string, now time.Time) (Entry, bool) {
+	if s.Global != nil && s.Global.ActiveAt(now) {
+		return *s.Global, true
+	}
+	if entry, ok := s.Accounts[userID]; ok && entry.ActiveAt(now) {
+		return entry, true
+	}
+	return Entry{}, false
+}
+
+// AccountIDs는 일시정지된 계정 ID를 정렬하여 반환합니다 (만료된 항목 제외)
+func (s *State) AccountIDs(now time.Time) []string {
+	ids := make([]string, 0, len(s.Accounts))
+	for id, entry := range s.Accounts {
+		if entry.ActiveAt(now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ParseUntil은 해제 시각을 해석합니다
+//
+//	"2026-12-31"        → 2026/12/31 하루 종일 (2027/01/01 00:00 해제)
+//	"2026-12-31 18:00"  → 해당 시각에 해제
+//	"72h"               → 지금부터 72시간 후 해제
+func ParseUntil(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", value, loc); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("해제 시각 형식 오류 %q (예: 2026-12-31, \"2026-12-31 18:00\", 72h)", value)
+}
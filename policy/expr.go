@@ -0,0 +1,536 @@
+package policy
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Expr은 파싱된 조건식입니다
+type Expr struct {
+	source string
+	root   node
+}
+
+// node는 조건식의 평가 단위입니다 (결과는 float64 또는 bool)
+type node func(env map[string]interface{}) (interface{}, error)
+
+// 숫자 뒤에 붙일 수 있는 한국어 단위 (예: 30억, 5천만, 1억5천만)
+var koreanUnits = map[string]float64{
+	"백": 1e2,
+	"천": 1e3,
+	"만": 1e4,
+	"억": 1e8,
+	"조": 1e12,
+}
+
+// Parse는 조건식을 파싱합니다
+// 지원 문법: 숫자(30억, 1억5천만, 10000), true/false, 변수, 괄호, + - * /, == != < <= > >=, && || ! (and/or/not)
+func Parse(source string) (*Expr, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{source: source, tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.done() {
+		tok := p.peek()
+		return nil, fmt.Errorf("%d번째 글자: 예상하지 못한 %q", tok.pos+1, tok.text)
+	}
+
+	return &Expr{source: source, root: root}, nil
+}
+
+// String은 원래 조건식을 반환합니다
+func (e *Expr) String() string {
+	return e.source
+}
+
+// EvalBool은 조건식을 평가하여 참/거짓을 반환합니다
+func (e *Expr) EvalBool(env map[string]interface{}) (bool, error) {
+	v, err := e.root(env)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("조건식 결과가 참/거짓이 아닙니다: %v", v)
+	}
+	return b, nil
+}
+
+// ---- 토큰 분리 ----
+
+type tokenKind int
+
+const (
+	tokNumber tokenKind = iota
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// 두 글자 연산자를 먼저 검사
+var operators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")"}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(source); {
+		r, size := utf8.DecodeRuneInString(source[i:])
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+
+		case r >= '0' && r <= '9':
+			start := i
+			num, end, err := scanNumber(source, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			tokens = append(tokens, token{kind: tokNumber, text: source[start:i], num: num, pos: start})
+
+		case r == '_' || unicode.IsLetter(r) && r < utf8.RuneSelf:
+			start := i
+			for i < len(source) {
+				c := source[i]
+				if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+					i++
+					continue
+				}
+				break
+			}
+			word := source[start:i]
+
+			// 영문 논리 연산자
+			switch strings.ToLower(word) {
+			case "and":
+				tokens = append(tokens, token{kind: tokOp, text: "&&", pos: start})
+			case "or":
+				tokens = append(tokens, token{kind: tokOp, text: "||", pos: start})
+			case "not":
+				tokens = append(tokens, token{kind: tokOp, text: "!", pos: start})
+			default:
+				tokens = append(tokens, token{kind: tokIdent, text: word, pos: start})
+			}
+
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(source[i:], op) {
+					tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("%d번째 글자: 알 수 없는 문자 %q", i+1, string(r))
+			}
+		}
+	}
+
+	return tokens, nil
+}
+
+// scanNumber는 start부터 숫자를 읽고 숫자 다음 위치를 반환합니다
+// 한국어 단위를 붙인 숫자(30억, 5천만)와 단위를 섞어 쓴 숫자(1억5천만, 3억5000만, 1만5000)를 읽습니다
+// 천·백은 만·억·조 앞의 자리를 만들고, 섞어 쓸 때는 큰 단위부터 적어야 합니다 (5천만1억은 오류)
+func scanNumber(source string, start int) (float64, int, error) {
+	total := 0.0
+	section := 0.0          // 아직 만·억·조를 붙이지 않은 자리 값 (2천3백 등)
+	bigLimit := math.Inf(1) // 다음 만·억·조는 이보다 작아야 함
+	smallLimit := bigLimit  // 다음 천·백은 이보다 작아야 함
+
+	invalid := func(i int) error {
+		return fmt.Errorf("%d번째 글자: 잘못된 금액 %q (큰 단위부터 적어야 합니다, 예: 1억5천만)", start+1, source[start:i])
+	}
+
+	i := start
+	for i < len(source) && source[i] >= '0' && source[i] <= '9' {
+		numStart := i
+		for i < len(source) && (source[i] >= '0' && source[i] <= '9' || source[i] == '.' || source[i] == '_' || source[i] == ',') {
+			i++
+		}
+		text := strings.NewReplacer("_", "", ",", "").Replace(source[numStart:i])
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return 0, i, fmt.Errorf("%d번째 글자: 잘못된 숫자 %q", numStart+1, source[numStart:i])
+		}
+		pending := true // num을 아직 자리 값에 더하지 않음
+
+		for i < len(source) {
+			r, size := utf8.DecodeRuneInString(source[i:])
+			unit, ok := koreanUnits[string(r)]
+			if !ok {
+				break
+			}
+			i += size
+
+			if unit < 1e4 {
+				// 천·백: 바로 앞 숫자에 붙어 자리 값을 만듦
+				if !pending || unit >= smallLimit {
+					return 0, i, invalid(i)
+				}
+				section += num * unit
+				smallLimit = unit
+				pending = false
+				continue
+			}
+
+			// 만·억·조: 지금까지의 자리 값 전체에 붙음 (5천만 = 5천 × 만)
+			if pending {
+				section += num
+				pending = false
+			}
+			if section == 0 || unit >= bigLimit {
+				return 0, i, invalid(i)
+			}
+			total += section * unit
+			section = 0
+			bigLimit = unit
+			smallLimit = math.Inf(1)
+		}
+
+		if pending {
+			section += num
+			if smallLimit < math.Inf(1) && num >= smallLimit {
+				return 0, i, invalid(i)
+			}
+			// 단위 없는 숫자 뒤에는 더 이어 쓸 수 없음
+			break
+		}
+	}
+
+	if section >= bigLimit {
+		return 0, i, invalid(i)
+	}
+	return total + section, i, nil
+}
+
+// ---- 파서 ----
+
+type parser struct {
+	source string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// accept는 다음 토큰이 주어진 연산자 중 하나이면 소비하고 반환합니다
+func (p *parser) accept(ops ...string) (string, bool) {
+	if p.done() || p.peek().kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.peek().text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	pos := len(p.source)
+	if !p.done() {
+		pos = p.peek().pos
+	}
+	return fmt.Errorf("%d번째 글자: %s", pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env map[string]interface{}) (interface{}, error) {
+			a, err := evalBool(l, env)
+			if err != nil || a {
+				return a, err
+			}
+			return evalBool(right, env)
+		}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env map[string]interface{}) (interface{}, error) {
+			a, err := evalBool(l, env)
+			if err != nil || !a {
+				return a, err
+			}
+			return evalBool(right, env)
+		}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	if _, ok := p.accept("!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(env map[string]interface{}) (interface{}, error) {
+			v, err := evalBool(operand, env)
+			return !v, err
+		}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(env map[string]interface{}) (interface{}, error) {
+		a, err := left(env)
+		if err != nil {
+			return nil, err
+		}
+		b, err := right(env)
+		if err != nil {
+			return nil, err
+		}
+
+		// 참/거짓끼리는 ==, != 만 허용
+		if ab, ok := a.(bool); ok {
+			bb, ok := b.(bool)
+			if !ok || (op != "==" && op != "!=") {
+				return nil, fmt.Errorf("참/거짓 값은 %s 비교를 할 수 없습니다", op)
+			}
+			return (ab == bb) == (op == "=="), nil
+		}
+
+		x, y, err := numbers(a, b, op)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "==":
+			return x == y, nil
+		case "!=":
+			return x != y, nil
+		case "<":
+			return x < y, nil
+		case "<=":
+			return x <= y, nil
+		case ">":
+			return x > y, nil
+		default:
+			return x >= y, nil
+		}
+	}, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmetic(left, right, op)
+	}
+}
+
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.accept("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmetic(left, right, op)
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if _, ok := p.accept("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		zero := func(map[string]interface{}) (interface{}, error) { return 0.0, nil }
+		return arithmetic(zero, operand, "-"), nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if p.done() {
+		return nil, p.errorf("식이 끝나지 않았습니다")
+	}
+
+	tok := p.peek()
+	switch tok.kind {
+	case tokNumber:
+		p.pos++
+		return func(map[string]interface{}) (interface{}, error) { return tok.num, nil }, nil
+
+	case tokIdent:
+		p.pos++
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return func(map[string]interface{}) (interface{}, error) { return true, nil }, nil
+		case "false":
+			return func(map[string]interface{}) (interface{}, error) { return false, nil }, nil
+		}
+		if renamed, ok := renamedVariables[tok.text]; ok {
+			return nil, fmt.Errorf("%d번째 글자: %s은(는) 직전 회차 값이라 %s(으)로 이름이 바뀌었습니다", tok.pos+1, tok.text, renamed)
+		}
+		if !isVariable(tok.text) {
+			return nil, fmt.Errorf("%d번째 글자: 알 수 없는 변수 %q (사용 가능: %s)", tok.pos+1, tok.text, strings.Join(VariableNames(), ", "))
+		}
+		name := tok.text
+		return func(env map[string]interface{}) (interface{}, error) {
+			v, ok := env[name]
+			if !ok {
+				return nil, fmt.Errorf("변수 %q 값이 없습니다", name)
+			}
+			return v, nil
+		}, nil
+	}
+
+	if _, ok := p.accept("("); ok {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, p.errorf("닫는 괄호 ')'가 필요합니다")
+		}
+		return inner, nil
+	}
+
+	return nil, p.errorf("예상하지 못한 %q", tok.text)
+}
+
+// ---- 평가 도우미 ----
+
+func evalBool(n node, env map[string]interface{}) (bool, error) {
+	v, err := n(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("참/거짓 값이 필요합니다: %v", v)
+	}
+	return b, nil
+}
+
+func numbers(a, b interface{}, op string) (float64, float64, error) {
+	x, ok1 := a.(float64)
+	y, ok2 := b.(float64)
+	if !ok1 || !ok2 {
+		return 0, 0, fmt.Errorf("%s 연산에는 숫자가 필요합니다 (%v %s %v)", op, a, op, b)
+	}
+	return x, y, nil
+}
+
+func arithmetic(left, right node, op string) node {
+	return func(env map[string]interface{}) (interface{}, error) {
+		a, err := left(env)
+		if err != nil {
+			return nil, err
+		}
+		b, err := right(env)
+		if err != nil {
+			return nil, err
+		}
+		x, y, err := numbers(a, b, op)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "+":
+			return x + y, nil
+		case "-":
+			return x - y, nil
+		case "*":
+			return x * y, nil
+		default:
+			if y == 0 {
+				return nil, fmt.Errorf("0으로 나눌 수 없습니다")
+			}
+			return x / y, nil
+		}
+	}
+}
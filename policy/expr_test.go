@@ -0,0 +1,118 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanNumber(t *testing.T) {
+	tests := []struct {
+		source string
+		want   float64
+	}{
+		{"10000", 10000},
+		{"1,000", 1000},
+		{"1_000", 1000},
+		{"2.5", 2.5},
+		{"2만", 2e4},
+		{"30억", 30e8},
+		{"5천만", 5e7},
+		{"1억5천만", 1.5e8},
+		{"3억5000만", 3.5e8},
+		{"1만5000", 15000},
+		{"2조3억", 2e12 + 3e8},
+		{"1억2천3백만4천", 123004000},
+		{"1천2백", 1200},
+		{"1억5천", 100005000},
+	}
+
+	for _, tt := range tests {
+		tokens, err := tokenize(tt.source)
+		if err != nil {
+			t.Errorf("%s: 오류 %v", tt.source, err)
+			continue
+		}
+		if len(tokens) != 1 || tokens[0].kind != tokNumber {
+			t.Errorf("%s: 숫자 하나여야 함: %+v", tt.source, tokens)
+			continue
+		}
+		if tokens[0].num != tt.want {
+			t.Errorf("%s = %v, want %v", tt.source, tokens[0].num, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string // 오류 메시지에 포함되어야 하는 내용
+	}{
+		{"5천만1억 > 0", "큰 단위부터"},
+		{"1억2억 > 0", "큰 단위부터"},
+		{"1만1만 > 0", "큰 단위부터"},
+		{"1만20000 > 0", "큰 단위부터"},
+		{"3백5천 > 0", "큰 단위부터"},
+		{"5만천 > 0", "큰 단위부터"},
+		{"jackpot > 100억", "lastJackpot"},
+		{"winners == 0", "lastWinners"},
+		{"unknown > 1", "알 수 없는 변수"},
+		{"balance >", ""},
+		{"(balance > 1", ""},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.source)
+		if err == nil {
+			t.Errorf("%q: 오류가 나야 함", tt.source)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: 오류 %q에 %q가 없음", tt.source, err, tt.want)
+		}
+	}
+}
+
+func TestEvalBool(t *testing.T) {
+	env := Inputs{
+		Round:            1247,
+		Balance:          15000,
+		LastJackpot:      1_500_000_000,
+		LastJackpotTotal: 22_500_000_000,
+		LastWinners:      15,
+		LastRank:         5,
+		RecentSpend:      20000,
+	}.Env()
+
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{"true", true},
+		{"balance < 2만", true},
+		{"balance >= 1만5000", true},
+		{"lastJackpot == 1억5천만 * 10", true},
+		{"lastJackpot > 15억", false},
+		{"lastJackpotTotal >= 225억 && lastWinners == 15", true},
+		{"carryover || lastJackpot >= 30억", false},
+		{"not carryover and lastPlayed == false", true},
+		{"lastRank > 0 && lastRank <= 5", true},
+		{"recentSpend / 5000 == 4", true},
+		{"(balance - recentSpend) < 0", true},
+	}
+
+	for _, tt := range tests {
+		expr, err := Parse(tt.source)
+		if err != nil {
+			t.Errorf("%q: 파싱 실패: %v", tt.source, err)
+			continue
+		}
+		got, err := expr.EvalBool(env)
+		if err != nil {
+			t.Errorf("%q: 평가 실패: %v", tt.source, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
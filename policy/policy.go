@@ -0,0 +1,139 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Inputs는 구매 정책 평가에 사용되는 현재 회차 정보입니다
+type Inputs struct {
+	Round            int   // 이번 회차
+	Balance          int   // 현재 예치금 (원)
+	LastJackpot      int64 // 직전 회차 1등 1인당 당첨금 (원)
+	LastJackpotTotal int64 // 직전 회차 1등 총 당첨금 (원)
+	LastWinners      int   // 직전 회차 1등 당첨자 수
+	Carryover        bool  // 직전 회차 1등 당첨자가 없어 이월되었는지
+	LastPlayed       bool  // 직전 회차에 구매했는지
+	LastRank         int   // 직전 회차 최고 등수 (0 = 낙첨 또는 구매 안 함)
+	RecentSpend      int   // 최근 회차 구매 금액 합계 (원)
+}
+
+// variables는 조건식에서 사용할 수 있는 변수와 설명입니다
+// 이번 회차 1등 당첨금은 추첨 전까지 공개되지 않으므로 당첨금 변수는 모두 직전 회차 값입니다
+var variables = map[string]string{
+	"round":            "이번 회차",
+	"balance":          "현재 예치금 (원)",
+	"lastJackpot":      "직전 회차 1등 1인당 당첨금 (원)",
+	"lastJackpotTotal": "직전 회차 1등 총 당첨금 (원)",
+	"lastWinners":      "직전 회차 1등 당첨자 수",
+	"carryover":        "직전 회차 1등 이월 여부 (true/false)",
+	"lastPlayed":       "직전 회차 구매 여부 (true/false)",
+	"lastRank":         "직전 회차 최고 등수 (0 = 낙첨/미구매)",
+	"recentSpend":      "최근 회차 구매 금액 합계 (원)",
+}
+
+// renamedVariables는 이름이 바뀐 변수입니다 (이전 이름을 쓰면 새 이름을 안내)
+var renamedVariables = map[string]string{
+	"jackpot":      "lastJackpot",
+	"jackpotTotal": "lastJackpotTotal",
+	"winners":      "lastWinners",
+}
+
+// VariableNames는 사용 가능한 변수 이름을 정렬하여 반환합니다
+func VariableNames() []string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// VariableDescription은 변수 설명을 반환합니다
+func VariableDescription(name string) string {
+	return variables[name]
+}
+
+func isVariable(name string) bool {
+	_, ok := variables[name]
+	return ok
+}
+
+// Env는 조건식 평가용 변수 맵을 만듭니다
+func (in Inputs) Env() map[string]interface{} {
+	return map[string]interface{}{
+		"round":            float64(in.Round),
+		"balance":          float64(in.Balance),
+		"lastJackpot":      float64(in.LastJackpot),
+		"lastJackpotTotal": float64(in.LastJackpotTotal),
+		"lastWinners":      float64(in.LastWinners),
+		"carryover":        in.Carryover,
+		"lastPlayed":       in.LastPlayed,
+		"lastRank":         float64(in.LastRank),
+		"recentSpend":      float64(in.RecentSpend),
+	}
+}
+
+// Rule은 조건과 구매 게임 수로 이루어진 정책 규칙입니다
+type Rule struct {
+	Name  string
+	When  *Expr
+	Games int
+}
+
+// Trace는 규칙별 평가 결과입니다
+type Trace struct {
+	Rule    Rule
+	Matched bool
+	Err     error
+}
+
+// Decision은 정책 평가 결과입니다
+type Decision struct {
+	Games   int     // 구매할 게임 수
+	Rule    *Rule   // 적용된 규칙 (nil이면 기본값)
+	Traces  []Trace // 모든 규칙의 평가 결과 (설명용)
+	Default bool    // 일치하는 규칙이 없어 기본값을 사용했는지
+}
+
+// Decide는 규칙을 순서대로 평가하여 처음 일치하는 규칙의 게임 수를 반환합니다
+// 일치하는 규칙이 없으면 defaultGames를 사용합니다
+func Decide(rules []Rule, inputs Inputs, defaultGames int) Decision {
+	env := inputs.Env()
+	decision := Decision{Games: defaultGames, Default: true}
+
+	for i := range rules {
+		matched, err := rules[i].When.EvalBool(env)
+		decision.Traces = append(decision.Traces, Trace{Rule: rules[i], Matched: matched, Err: err})
+
+		if matched && decision.Rule == nil {
+			decision.Rule = &rules[i]
+			decision.Games = rules[i].Games
+			decision.Default = false
+		}
+	}
+
+	return decision
+}
+
+// Err는 결정에 영향을 준 규칙의 평가 오류를 반환합니다
+// 적용된 규칙까지(일치한 규칙이 없으면 모든 규칙)만 확인하므로, 적용된 규칙 아래의 잘못된 규칙은 결정을 막지 않습니다
+func (d Decision) Err() error {
+	for _, trace := range d.Traces {
+		if trace.Err != nil {
+			return fmt.Errorf("규칙 %q 평가 실패: %w", trace.Rule.Name, trace.Err)
+		}
+		if trace.Matched {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Describe는 적용된 규칙을 사람이 읽을 수 있게 설명합니다
+func (d Decision) Describe() string {
+	if d.Rule == nil {
+		return fmt.Sprintf("일치하는 규칙 없음 → 기본 %d게임", d.Games)
+	}
+	return fmt.Sprintf("규칙 %q (%s) → %d게임", d.Rule.Name, d.Rule.When, d.Games)
+}
@@ -0,0 +1,51 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+func mustRules(t *testing.T, whens ...string) []Rule {
+	t.Helper()
+	rules := make([]Rule, len(whens))
+	for i, when := range whens {
+		expr, err := Parse(when)
+		if err != nil {
+			t.Fatalf("%q: 파싱 실패: %v", when, err)
+		}
+		rules[i] = Rule{Name: when, When: expr, Games: i + 1}
+	}
+	return rules
+}
+
+func TestDecideErrOnlyUpToMatch(t *testing.T) {
+	inputs := Inputs{Balance: 15000}
+
+	tests := []struct {
+		name    string
+		whens   []string
+		games   int
+		wantErr string // 비어 있으면 오류 없음
+	}{
+		{"일치한 규칙 아래의 오류는 무시", []string{"balance < 2만", "balance + 1"}, 1, ""},
+		{"일치하기 전의 오류는 실패", []string{"balance + 1", "true"}, 2, "balance + 1"},
+		{"일치한 규칙 없이 오류", []string{"balance > 2만", "balance + 1"}, 5, "balance + 1"},
+		{"일치한 규칙 없음", []string{"balance > 2만"}, 5, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := Decide(mustRules(t, tt.whens...), inputs, 5)
+			if decision.Games != tt.games {
+				t.Errorf("게임 수 %d, want %d", decision.Games, tt.games)
+			}
+			err := decision.Err()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("오류가 없어야 함: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("규칙 %q 오류여야 함: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
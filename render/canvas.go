@@ -0,0 +1,139 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"unicode"
+)
+
+// canvas는 도형과 비트맵 글자를 그리는 RGBA 이미지입니다 (외부 글꼴, 그래픽 라이브러리 없이 그림)
+type canvas struct {
+	img *image.RGBA
+}
+
+func newCanvas(width, height int, background color.RGBA) *canvas {
+	c := &canvas{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+	c.rect(0, 0, width, height, background)
+	return c
+}
+
+// png는 이미지를 PNG로 인코딩합니다
+func (c *canvas) png() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.img); err != nil {
+		return nil, fmt.Errorf("PNG 인코딩 실패: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// blend는 (x, y) 픽셀에 col을 coverage(0~1) 비율로 덮어 그립니다
+func (c *canvas) blend(x, y int, col color.RGBA, coverage float64) {
+	if !(image.Point{x, y}.In(c.img.Rect)) {
+		return
+	}
+	alpha := coverage * float64(col.A) / 255
+	if alpha <= 0 {
+		return
+	}
+	if alpha > 1 {
+		alpha = 1
+	}
+
+	i := c.img.PixOffset(x, y)
+	pix := c.img.Pix[i : i+4 : i+4]
+	mix := func(dst, src uint8) uint8 {
+		return uint8(math.Round(float64(dst)*(1-alpha) + float64(src)*alpha))
+	}
+	pix[0] = mix(pix[0], col.R)
+	pix[1] = mix(pix[1], col.G)
+	pix[2] = mix(pix[2], col.B)
+	pix[3] = 255
+}
+
+// rect는 사각형을 채웁니다
+func (c *canvas) rect(x, y, width, height int, col color.RGBA) {
+	for py := y; py < y+height; py++ {
+		for px := x; px < x+width; px++ {
+			c.blend(px, py, col, 1)
+		}
+	}
+}
+
+// roundRect는 모서리가 둥근 사각형을 채웁니다
+func (c *canvas) roundRect(x, y, width, height, radius int, col color.RGBA) {
+	c.rect(x+radius, y, width-2*radius, height, col)
+	c.rect(x, y+radius, radius, height-2*radius, col)
+	c.rect(x+width-radius, y+radius, radius, height-2*radius, col)
+
+	r := float64(radius)
+	for _, corner := range [][2]int{{x, y}, {x + width - 2*radius, y}, {x, y + height - 2*radius}, {x + width - 2*radius, y + height - 2*radius}} {
+		cx, cy := float64(corner[0])+r, float64(corner[1])+r
+		for py := corner[1]; py < corner[1]+2*radius; py++ {
+			for px := corner[0]; px < corner[0]+2*radius; px++ {
+				// 모서리 사분원만 칠함 (나머지는 위의 사각형이 칠함)
+				inX := px < x+radius || px >= x+width-radius
+				inY := py < y+radius || py >= y+height-radius
+				if inX && inY {
+					c.blend(px, py, col, circleCoverage(px, py, cx, cy, r))
+				}
+			}
+		}
+	}
+}
+
+// circle은 중심 (cx, cy), 반지름 r인 원을 가장자리를 부드럽게 채웁니다
+func (c *canvas) circle(cx, cy, r float64, col color.RGBA) {
+	for py := int(cy - r - 1); py <= int(cy+r+1); py++ {
+		for px := int(cx - r - 1); px <= int(cx+r+1); px++ {
+			c.blend(px, py, col, circleCoverage(px, py, cx, cy, r))
+		}
+	}
+}
+
+// ring은 바깥 반지름 r, 두께 width인 고리를 그립니다
+func (c *canvas) ring(cx, cy, r, width float64, col color.RGBA) {
+	for py := int(cy - r - 1); py <= int(cy+r+1); py++ {
+		for px := int(cx - r - 1); px <= int(cx+r+1); px++ {
+			outer := circleCoverage(px, py, cx, cy, r)
+			inner := circleCoverage(px, py, cx, cy, r-width)
+			c.blend(px, py, col, outer-inner)
+		}
+	}
+}
+
+// circleCoverage는 픽셀 (px, py)가 원에 덮이는 비율입니다 (가장자리 1픽셀에서 0~1)
+func circleCoverage(px, py int, cx, cy, r float64) float64 {
+	d := math.Hypot(float64(px)+0.5-cx, float64(py)+0.5-cy)
+	return math.Max(0, math.Min(1, r-d+0.5))
+}
+
+// text는 (x, y)를 왼쪽 위로 하여 글자열을 배율 scale로 그립니다 (글꼴에 없는 글자는 빈칸)
+func (c *canvas) text(x, y, scale int, text string, col color.RGBA) {
+	for _, ch := range text {
+		glyph, ok := glyphs[unicode.ToUpper(ch)]
+		if ok {
+			for row, line := range glyph {
+				for column, bit := range line {
+					if bit == '#' {
+						c.rect(x+column*scale, y+row*scale, scale, scale, col)
+					}
+				}
+			}
+		}
+		x += (glyphWidth + 1) * scale
+	}
+}
+
+// centerText는 (cx, cy)를 중심으로 글자열을 그립니다
+func (c *canvas) centerText(cx, cy, scale int, text string, col color.RGBA) {
+	c.text(cx-textWidth(text, scale)/2, cy-textHeight(scale)/2, scale, text, col)
+}
+
+// rightText는 오른쪽 끝이 right인 글자열을 그립니다
+func (c *canvas) rightText(right, y, scale int, text string, col color.RGBA) {
+	c.text(right-textWidth(text, scale), y, scale, text, col)
+}
@@ -0,0 +1,71 @@
+package render
+
+// glyphWidth, glyphHeight는 비트맵 글꼴 한 글자의 크기입니다 (픽셀, 배율 1 기준)
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+)
+
+// glyphs는 이미지에 쓰는 5x7 비트맵 글꼴입니다 (숫자, 영문 대문자, 일부 기호)
+// 한글은 그리지 않으므로 이미지의 글자는 영문과 숫자만 사용하고, 한글 설명은 사진 캡션에 넣습니다
+var glyphs = map[rune][glyphHeight]string{
+	'0': {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'A': {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B': {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C': {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D': {"###..", "#..#.", "#...#", "#...#", "#...#", "#..#.", "###.."},
+	'E': {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F': {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G': {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H': {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I': {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J': {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K': {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L': {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M': {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N': {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O': {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P': {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q': {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R': {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S': {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T': {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U': {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V': {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W': {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X': {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y': {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z': {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	' ': {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+	'#': {".#.#.", ".#.#.", "#####", ".#.#.", "#####", ".#.#.", ".#.#."},
+	'+': {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+	'-': {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'/': {"....#", "....#", "...#.", "..#..", ".#...", "#....", "#...."},
+	':': {".....", ".##..", ".##..", ".....", ".##..", ".##..", "....."},
+	'.': {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	'(': {"...#.", "..#..", ".#...", ".#...", ".#...", "..#..", "...#."},
+	')': {".#...", "..#..", "...#.", "...#.", "...#.", "..#..", ".#..."},
+}
+
+// textWidth는 배율 scale로 그린 글자열의 너비입니다 (글자 사이 간격 1칸)
+func textWidth(text string, scale int) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (n*(glyphWidth+1) - 1) * scale
+}
+
+// textHeight는 배율 scale로 그린 글자의 높이입니다
+func textHeight(scale int) int {
+	return glyphHeight * scale
+}
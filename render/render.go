@@ -0,0 +1,236 @@
+// Package render는 구매 번호와 당첨 결과를 로또 공 이미지(PNG)로 그립니다
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// Game은 구매한 게임 한 줄입니다
+type Game struct {
+	Label   string // A, B, C, D, E
+	Numbers []int
+	Auto    bool // 자동 선택
+}
+
+// Draw는 회차의 당첨번호입니다
+type Draw struct {
+	Round   string
+	Date    string
+	Numbers []int
+	Bonus   int
+}
+
+// GameResult는 당첨 결과가 표시된 게임입니다
+type GameResult struct {
+	Game
+	Rank    int  // 등수 (0 = 낙첨)
+	Matches int  // 일치한 번호 수
+	Bonus   bool // 보너스 번호 일치
+}
+
+// 색상 (공 색은 동행복권 공식 번호 구간별 색상)
+var (
+	backgroundColor = color.RGBA{0xF4, 0xF5, 0xF7, 0xFF}
+	cardColor       = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	lineColor       = color.RGBA{0xE3, 0xE5, 0xE8, 0xFF}
+	titleColor      = color.RGBA{0x22, 0x25, 0x2B, 0xFF}
+	subtleColor     = color.RGBA{0x8A, 0x8F, 0x98, 0xFF}
+	winColor        = color.RGBA{0xE0, 0x3E, 0x3E, 0xFF}
+	winRowColor     = color.RGBA{0xFF, 0xF6, 0xD5, 0xFF}
+	missBallColor   = color.RGBA{0xE6, 0xE7, 0xEA, 0xFF}
+	missDigitColor  = color.RGBA{0xA0, 0xA4, 0xAB, 0xFF}
+	digitColor      = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	shadowColor     = color.RGBA{0x00, 0x00, 0x00, 0x50}
+	shineColor      = color.RGBA{0xFF, 0xFF, 0xFF, 0x40}
+)
+
+// BallColor는 번호 구간별 공 색상입니다 (1-10 노랑, 11-20 파랑, 21-30 빨강, 31-40 회색, 41-45 초록)
+func BallColor(number int) color.RGBA {
+	switch {
+	case number <= 10:
+		return color.RGBA{0xFB, 0xC4, 0x00, 0xFF}
+	case number <= 20:
+		return color.RGBA{0x69, 0xC8, 0xF2, 0xFF}
+	case number <= 30:
+		return color.RGBA{0xFF, 0x72, 0x72, 0xFF}
+	case number <= 40:
+		return color.RGBA{0xAA, 0xAA, 0xAA, 0xFF}
+	default:
+		return color.RGBA{0xB0, 0xD8, 0x40, 0xFF}
+	}
+}
+
+// 배치 (픽셀)
+const (
+	padding     = 24 // 카드 바깥 여백
+	inset       = 20 // 카드 안쪽 여백
+	ballRadius  = 26
+	ballGap     = 10
+	ballStep    = 2*ballRadius + ballGap
+	labelWidth  = 64 // 게임 이름 (A 자동) 칸
+	rankWidth   = 96 // 등수 칸 (결과 카드)
+	rowHeight   = 2*ballRadius + 16
+	headerSize  = 3 // 제목 글자 배율
+	subSize     = 2 // 보조 글자 배율
+	digitSize   = 3 // 공 번호 글자 배율
+	headerSpace = 78
+)
+
+// Ticket은 구매한 게임을 로또 공으로 그린 이미지(PNG)를 만듭니다
+func Ticket(round, drawDate string, games []Game) ([]byte, error) {
+	if len(games) == 0 {
+		return nil, fmt.Errorf("그릴 게임이 없습니다")
+	}
+
+	inner := labelWidth + 6*ballStep - ballGap
+	width := 2*(padding+inset) + inner
+	height := 2*padding + 2*inset + headerSpace + len(games)*rowHeight
+
+	c := newCanvas(width, height, backgroundColor)
+	c.roundRect(padding, padding, width-2*padding, height-2*padding, 16, cardColor)
+
+	left, top := padding+inset, padding+inset
+	drawHeader(c, left, top, width-left, round, drawDate)
+
+	y := top + headerSpace
+	for _, game := range games {
+		drawLabel(c, left, y, game)
+		x := left + labelWidth
+		for _, number := range game.Numbers {
+			drawBall(c, x+ballRadius, y+rowHeight/2, number, true)
+			x += ballStep
+		}
+		y += rowHeight
+	}
+
+	return c.png()
+}
+
+// Result는 당첨번호와 구매한 게임을 비교한 결과 카드 이미지(PNG)를 만듭니다
+// 일치한 번호는 공 색상으로, 나머지는 회색으로 그리고, 보너스 번호와 같은 번호는 테두리로 표시합니다
+func Result(draw Draw, games []GameResult) ([]byte, error) {
+	if len(draw.Numbers) == 0 {
+		return nil, fmt.Errorf("당첨번호가 없습니다")
+	}
+
+	inner := labelWidth + 6*ballStep - ballGap + rankWidth
+	width := 2*(padding+inset) + inner
+	height := 2*padding + 2*inset + headerSpace + rowHeight + 24 + len(games)*rowHeight
+
+	c := newCanvas(width, height, backgroundColor)
+	c.roundRect(padding, padding, width-2*padding, height-2*padding, 16, cardColor)
+
+	left, top := padding+inset, padding+inset
+	right := width - left
+	drawHeader(c, left, top, right, draw.Round, draw.Date)
+
+	// 당첨번호 + 보너스 (가운데 정렬)
+	y := top + headerSpace
+	plusWidth := textWidth("+", headerSize) + 2*ballGap
+	rowWidth := len(draw.Numbers)*ballStep + plusWidth + 2*ballRadius
+	x := (width - rowWidth) / 2
+	for _, number := range draw.Numbers {
+		drawBall(c, x+ballRadius, y+rowHeight/2, number, true)
+		x += ballStep
+	}
+	c.centerText(x+plusWidth/2-ballGap/2, y+rowHeight/2, headerSize, "+", subtleColor)
+	x += plusWidth
+	drawBall(c, x+ballRadius, y+rowHeight/2, draw.Bonus, true)
+
+	y += rowHeight + 12
+	c.rect(left, y, right-left, 2, lineColor)
+	y += 12
+
+	winning := make(map[int]bool, len(draw.Numbers))
+	for _, number := range draw.Numbers {
+		winning[number] = true
+	}
+
+	for _, game := range games {
+		if game.Rank > 0 {
+			c.roundRect(left-8, y+2, right-left+16, rowHeight-4, 10, winRowColor)
+		}
+		drawLabel(c, left, y, game.Game)
+
+		x := left + labelWidth
+		cy := y + rowHeight/2
+		for _, number := range game.Numbers {
+			drawBall(c, x+ballRadius, cy, number, winning[number])
+			if number == draw.Bonus {
+				c.ring(float64(x+ballRadius), float64(cy), ballRadius+1, 4, BallColor(number))
+			}
+			x += ballStep
+		}
+
+		rank, detail := "-", fmt.Sprintf("%d MATCH", game.Matches)
+		rankColor := subtleColor
+		if game.Rank > 0 {
+			rank, rankColor = ordinal(game.Rank), winColor
+		}
+		if game.Bonus && game.Rank == 2 {
+			detail = fmt.Sprintf("%d+BONUS", game.Matches)
+		}
+		c.rightText(right, cy-textHeight(headerSize)-2, headerSize, rank, rankColor)
+		c.rightText(right, cy+4, subSize, detail, subtleColor)
+
+		y += rowHeight
+	}
+
+	return c.png()
+}
+
+// drawHeader는 제목(LOTTO 6/45, 회차)과 추첨일을 그립니다
+func drawHeader(c *canvas, left, top, right int, round, date string) {
+	c.text(left, top, headerSize, "LOTTO 6/45", titleColor)
+	if round != "" {
+		c.rightText(right, top, headerSize, "#"+round, titleColor)
+	}
+	if date != "" {
+		c.text(left, top+textHeight(headerSize)+12, subSize, "DRAW "+strings.ReplaceAll(date, "-", "/"), subtleColor)
+	}
+	c.rect(left, top+headerSpace-14, right-left, 2, lineColor)
+}
+
+// drawLabel은 게임 이름(A)과 자동 여부를 그립니다
+func drawLabel(c *canvas, x, y int, game Game) {
+	if !game.Auto {
+		c.text(x, y+(rowHeight-textHeight(4))/2, 4, game.Label, titleColor)
+		return
+	}
+	labelHeight := textHeight(4) + 6 + textHeight(subSize)
+	top := y + (rowHeight-labelHeight)/2
+	c.text(x, top, 4, game.Label, titleColor)
+	c.text(x, top+textHeight(4)+6, subSize, "AUTO", subtleColor)
+}
+
+// drawBall은 (cx, cy)를 중심으로 번호 공을 그립니다 (active가 false면 회색 공)
+func drawBall(c *canvas, cx, cy, number int, active bool) {
+	fx, fy := float64(cx), float64(cy)
+	label := fmt.Sprintf("%d", number)
+
+	if !active {
+		c.circle(fx, fy, ballRadius, missBallColor)
+		c.centerText(cx, cy, digitSize, label, missDigitColor)
+		return
+	}
+
+	c.circle(fx, fy, ballRadius, BallColor(number))
+	c.circle(fx-ballRadius/3, fy-ballRadius/3, ballRadius/2, shineColor)
+	c.centerText(cx+1, cy+1, digitSize, label, shadowColor)
+	c.centerText(cx, cy, digitSize, label, digitColor)
+}
+
+// ordinal은 등수를 영문 서수로 표시합니다 (1ST, 2ND, 3RD, 4TH, 5TH)
+func ordinal(rank int) string {
+	switch rank {
+	case 1:
+		return "1ST"
+	case 2:
+		return "2ND"
+	case 3:
+		return "3RD"
+	}
+	return fmt.Sprintf("%dTH", rank)
+}
@@ -0,0 +1,248 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Version은 출력 구조의 버전입니다 (필드를 바꾸거나 지울 때만 올림, 추가는 그대로)
+const Version = 1
+
+// 출력 종류
+const (
+	KindBalance  = "balance"
+	KindPurchase = "purchase"
+	KindResult   = "result"
+	KindWinning  = "winning"
+	KindHistory  = "history"
+)
+
+// Format은 출력 형식입니다
+type Format string
+
+const (
+	FormatNone   Format = ""       // 구조화된 출력 없음 (로그만)
+	FormatTable  Format = "table"  // 사람이 읽기 쉬운 표
+	FormatJSON   Format = "json"   // 문서 하나 {"version", "kind", "generatedAt", "items"}
+	FormatNDJSON Format = "ndjson" // 항목마다 한 줄 {"version", "kind", "data"}
+)
+
+// ParseFormat은 --output 값을 확인합니다
+func ParseFormat(value string) (Format, error) {
+	switch f := Format(value); f {
+	case FormatNone, FormatTable, FormatJSON, FormatNDJSON:
+		return f, nil
+	}
+	return FormatNone, fmt.Errorf("알 수 없는 출력 형식 %q (json, ndjson, table 중 하나)", value)
+}
+
+// Record는 출력 항목입니다 (표 형식의 열 정의 포함)
+type Record interface {
+	Columns() []string
+	Row() []string
+}
+
+// document는 json 형식의 출력 문서입니다
+type document[T Record] struct {
+	Version     int       `json:"version"`
+	Kind        string    `json:"kind"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	Items       []T       `json:"items"`
+}
+
+// line은 ndjson 형식의 한 줄입니다
+type line[T Record] struct {
+	Version int    `json:"version"`
+	Kind    string `json:"kind"`
+	Data    T      `json:"data"`
+}
+
+// Write는 항목 목록을 지정한 형식으로 씁니다
+func Write[T Record](w io.Writer, format Format, kind string, items []T) error {
+	switch format {
+	case FormatNone:
+		return nil
+
+	case FormatJSON:
+		if items == nil {
+			items = []T{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(document[T]{Version: Version, Kind: kind, GeneratedAt: time.Now(), Items: items})
+
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, item := range items {
+			if err := enc.Encode(line[T]{Version: Version, Kind: kind, Data: item}); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case FormatTable:
+		var zero T
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(zero.Columns(), "\t"))
+		for _, item := range items {
+			fmt.Fprintln(tw, strings.Join(item.Row(), "\t"))
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("알 수 없는 출력 형식 %q", format)
+}
+
+// ---- 항목 ----
+
+// Game은 구매한 게임 하나입니다 (당첨 확인 시 등수 포함)
+type Game struct {
+	Type    string `json:"type"` // A~E
+	Numbers []int  `json:"numbers"`
+	Rank    int    `json:"rank,omitempty"`    // 1~5등 (0이면 낙첨 또는 미확인)
+	Matches int    `json:"matches,omitempty"` // 일치한 번호 수
+	Bonus   bool   `json:"bonus,omitempty"`   // 보너스 번호 일치
+}
+
+// Balance는 계정별 예치금 확인 결과입니다
+type Balance struct {
+	Account    string `json:"account"`
+	OK         bool   `json:"ok"`
+	Balance    int    `json:"balance"`    // 원
+	Sufficient bool   `json:"sufficient"` // 10,000원 이상 (5게임 구매 가능)
+	Error      string `json:"error,omitempty"`
+}
+
+func (Balance) Columns() []string { return []string{"ACCOUNT", "BALANCE", "SUFFICIENT", "ERROR"} }
+
+func (b Balance) Row() []string {
+	balance := "-"
+	if b.OK {
+		balance = strconv.Itoa(b.Balance)
+	}
+	return []string{b.Account, balance, yesNo(b.Sufficient), dash(b.Error)}
+}
+
+// 구매 상태
+const (
+	StatusPurchased = "purchased" // 구매 완료
+	StatusFailed    = "failed"    // 구매 실패
+	StatusSkipped   = "skipped"   // 정책 또는 승인에 따라 구매 안 함
+)
+
+// Purchase는 계정별 구매 결과입니다
+type Purchase struct {
+	Account string `json:"account"`
+	Round   string `json:"round,omitempty"`
+	Status  string `json:"status"`
+	Games   []Game `json:"games"`
+	Amount  int    `json:"amount"`            // 구매 금액 (원)
+	Balance *int   `json:"balance,omitempty"` // 구매 전 예치금 (확인한 경우)
+	Reason  string `json:"reason,omitempty"`  // 건너뛴 이유 또는 실패 이유
+}
+
+func (Purchase) Columns() []string {
+	return []string{"ACCOUNT", "ROUND", "STATUS", "GAMES", "AMOUNT", "REASON"}
+}
+
+func (p Purchase) Row() []string {
+	return []string{p.Account, dash(p.Round), p.Status, strconv.Itoa(len(p.Games)), strconv.Itoa(p.Amount), dash(p.Reason)}
+}
+
+// Result는 회차별 당첨번호입니다
+type Result struct {
+	Round           string `json:"round"`
+	DrawDate        string `json:"drawDate"`
+	Numbers         []int  `json:"numbers"`
+	Bonus           int    `json:"bonus"`
+	FirstPrize      int64  `json:"firstPrize"`      // 1등 1인당 당첨금
+	FirstPrizeTotal int64  `json:"firstPrizeTotal"` // 1등 총 당첨금
+	FirstWinners    int    `json:"firstWinners"`    // 0이면 이월
+}
+
+func (Result) Columns() []string {
+	return []string{"ROUND", "DRAW_DATE", "NUMBERS", "BONUS", "FIRST_WINNERS", "FIRST_PRIZE"}
+}
+
+func (r Result) Row() []string {
+	return []string{r.Round, r.DrawDate, joinNumbers(r.Numbers), strconv.Itoa(r.Bonus), strconv.Itoa(r.FirstWinners), strconv.FormatInt(r.FirstPrize, 10)}
+}
+
+// 당첨 확인 상태
+const (
+	WinningChecked       = "checked"        // 당첨 확인 완료
+	WinningNoHistory     = "no-history"     // 저장된 구매 내역 없음
+	WinningRoundMismatch = "round-mismatch" // 구매 회차와 추첨 회차가 다름
+	WinningNoPurchase    = "no-purchase"    // 해당 회차 구매 내역 없음
+	WinningSkipped       = "skipped"        // 승인하지 않아 구매 안 함
+	WinningFailed        = "failed"         // 구매 실패
+)
+
+// Winning은 계정별 당첨 확인 결과입니다
+type Winning struct {
+	Account  string `json:"account"`
+	Round    string `json:"round"`
+	Status   string `json:"status"`
+	BestRank int    `json:"bestRank"` // 가장 높은 등수 (0이면 낙첨)
+	Games    []Game `json:"games"`
+}
+
+func (Winning) Columns() []string {
+	return []string{"ACCOUNT", "ROUND", "STATUS", "BEST_RANK", "GAMES"}
+}
+
+func (w Winning) Row() []string {
+	rank := "-"
+	if w.Status == WinningChecked {
+		rank = strconv.Itoa(w.BestRank)
+	}
+	return []string{w.Account, w.Round, w.Status, rank, strconv.Itoa(len(w.Games))}
+}
+
+// HistoryEntry는 계정의 회차별 구매 내역입니다
+type HistoryEntry struct {
+	Round    string `json:"round"`
+	DrawDate string `json:"drawDate"`
+	Account  string `json:"account"`
+	Status   string `json:"status"` // purchased, failed, skipped
+	Approval string `json:"approval,omitempty"`
+	Games    []Game `json:"games"`
+}
+
+func (HistoryEntry) Columns() []string {
+	return []string{"ROUND", "DRAW_DATE", "ACCOUNT", "STATUS", "NUMBERS"}
+}
+
+func (h HistoryEntry) Row() []string {
+	games := make([]string, len(h.Games))
+	for i, g := range h.Games {
+		games[i] = g.Type + ":" + joinNumbers(g.Numbers)
+	}
+	return []string{h.Round, h.DrawDate, h.Account, h.Status, dash(strings.Join(games, " "))}
+}
+
+func joinNumbers(numbers []int) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
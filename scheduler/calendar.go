@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// 요일 이름 (영문 약어, 영문, 한글)
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday, "일": time.Sunday, "일요일": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday, "월": time.Monday, "월요일": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday, "화": time.Tuesday, "화요일": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday, "수": time.Wednesday, "수요일": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday, "목": time.Thursday, "목요일": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday, "금": time.Friday, "금요일": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday, "토": time.Saturday, "토요일": time.Saturday,
+}
+
+var koreanWeekdays = []string{"일", "월", "화", "수", "목", "금", "토"}
+
+const minutesPerWeek = 7 * 24 * 60
+
+// ParseCalendar는 달력 표현식을 크론 표현식으로 변환합니다
+//
+//	"mon 19:00"      → 매주 월요일 19:00
+//	"sat 20:00 -2h"  → 매주 토요일 20:00의 2시간 전 (토요일 18:00)
+//	"daily 09:30"    → 매일 09:30
+func ParseCalendar(expr string) (string, error) {
+	fields := strings.Fields(strings.ToLower(strings.TrimSpace(expr)))
+	if len(fields) < 2 || len(fields) > 3 {
+		return "", fmt.Errorf("달력 표현식 형식 오류 %q (예: \"mon 19:00\", \"sat 20:00 -2h\")", expr)
+	}
+
+	at, err := time.Parse("15:04", fields[1])
+	if err != nil {
+		return "", fmt.Errorf("시각 형식 오류 %q (HH:MM)", fields[1])
+	}
+
+	var offset time.Duration
+	if len(fields) == 3 {
+		offset, err = time.ParseDuration(fields[2])
+		if err != nil {
+			return "", fmt.Errorf("오프셋 형식 오류 %q (예: -2h, +30m)", fields[2])
+		}
+		if offset%time.Minute != 0 {
+			return "", fmt.Errorf("오프셋은 분 단위여야 합니다 (%s)", fields[2])
+		}
+	}
+
+	// 매일
+	if fields[0] == "daily" || fields[0] == "매일" {
+		if offset <= -24*time.Hour || offset >= 24*time.Hour {
+			return "", fmt.Errorf("매일 일정의 오프셋은 24시간 미만이어야 합니다 (%s)", fields[2])
+		}
+		minute := ((at.Hour()*60+at.Minute()+int(offset.Minutes()))%(24*60) + 24*60) % (24 * 60)
+		return fmt.Sprintf("%d %d * * *", minute%60, minute/60), nil
+	}
+
+	weekday, ok := weekdayNames[fields[0]]
+	if !ok {
+		return "", fmt.Errorf("알 수 없는 요일 %q", fields[0])
+	}
+
+	// 한 주 안에서의 분 단위 위치로 계산하여 요일을 넘어가는 오프셋 처리
+	minute := int(weekday)*24*60 + at.Hour()*60 + at.Minute() + int(offset.Minutes())
+	minute = (minute%minutesPerWeek + minutesPerWeek) % minutesPerWeek
+
+	day := minute / (24 * 60)
+	minute %= 24 * 60
+	return fmt.Sprintf("%d %d * * %d", minute%60, minute/60, day), nil
+}
+
+// BuildSpec은 크론 표현식 또는 달력 표현식과 시간대로 최종 크론 표현식을 만들고 검증합니다
+func BuildSpec(cronExpr, calendar, timezone string) (string, error) {
+	spec := strings.TrimSpace(cronExpr)
+	switch {
+	case spec != "" && calendar != "":
+		return "", fmt.Errorf("cron과 at 중 하나만 지정해야 합니다")
+	case spec == "" && calendar == "":
+		return "", fmt.Errorf("cron 또는 at 중 하나를 지정해야 합니다")
+	case calendar != "":
+		var err error
+		if spec, err = ParseCalendar(calendar); err != nil {
+			return "", err
+		}
+	}
+
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return "", fmt.Errorf("알 수 없는 시간대 %q: %w", timezone, err)
+		}
+		spec = fmt.Sprintf("CRON_TZ=%s %s", timezone, spec)
+	}
+
+	if _, err := cron.ParseStandard(spec); err != nil {
+		return "", fmt.Errorf("크론 표현식 오류 %q: %w", spec, err)
+	}
+
+	return spec, nil
+}
+
+// NextRun은 크론 표현식의 from 이후 첫 실행 시각을 반환합니다 (시간대가 없는 표현식은 from의 시간대 기준)
+func NextRun(spec string, from time.Time) (time.Time, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("크론 표현식 오류 %q: %w", spec, err)
+	}
+	return schedule.Next(from), nil
+}
+
+// DescribeSpec은 단순한 주간/일간 크론 표현식을 한국어로 설명합니다 (그 외에는 표현식 그대로)
+func DescribeSpec(spec string) string {
+	tz := ""
+	expr := spec
+	if strings.HasPrefix(expr, "CRON_TZ=") {
+		parts := strings.SplitN(expr, " ", 2)
+		tz = " (" + strings.TrimPrefix(parts[0], "CRON_TZ=") + ")"
+		if len(parts) == 2 {
+			expr = parts[1]
+		}
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 || fields[2] != "*" || fields[3] != "*" {
+		return spec
+	}
+
+	var minute, hour, day int
+	if _, err := fmt.Sscanf(fields[0]+" "+fields[1], "%d %d", &minute, &hour); err != nil {
+		return spec
+	}
+
+	timeStr := fmt.Sprintf("%02d:%02d", hour, minute)
+	if fields[4] == "*" {
+		return "매일 " + timeStr + tz
+	}
+	if _, err := fmt.Sscanf(fields[4], "%d", &day); err != nil || fmt.Sprint(day) != fields[4] || day < 0 || day > 7 {
+		return spec
+	}
+
+	return fmt.Sprintf("매주 %s요일 %s%s", koreanWeekdays[day%7], timeStr, tz)
+}
@@ -1,7 +1,12 @@
 package scheduler
 
 import (
+	"fmt"
 	"log"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -9,7 +14,54 @@ import (
 
 // Scheduler는 크론 스케줄러입니다
 type Scheduler struct {
-	cron *cron.Cron
+	cron     *cron.Cron
+	location *time.Location
+
+	mu           sync.Mutex
+	timers       map[*time.Timer]bool // 아직 실행되지 않은 1회성 작업
+	jobs         []*job
+	states       map[string]JobState
+	panicHandler PanicHandler
+
+	// 작업 묶음별 실행 잠금 (같은 묶음의 정기 작업, 1회성 작업, 즉시 실행은 겹쳐 실행되지 않음)
+	groups map[string]*sync.Mutex
+
+	// 다시 등록 중일 때 이전 작업의 실행 중 표시 (같은 이름의 새 작업이 이어받음)
+	previous map[string]*atomic.Bool
+}
+
+// PanicHandler는 작업 실행 중 패닉이 발생했을 때 호출됩니다 (크래시 리포트 전송용)
+type PanicHandler func(name string, recovered interface{}, stack []byte)
+
+// PanicError는 작업 실행 중 복구된 패닉입니다
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("패닉 발생: %v", e.Value)
+}
+
+// job은 이름이 붙은 정기 작업입니다
+type job struct {
+	name        string
+	description string
+	spec        string
+	group       string // 작업 묶음 (비어 있으면 묶음 없음)
+	schedule    cron.Schedule
+	cmd         func() error
+	running     *atomic.Bool
+	entryID     cron.EntryID
+}
+
+// JobInfo는 등록된 작업의 정보입니다
+type JobInfo struct {
+	Name        string
+	Description string
+	Spec        string
+	Next        time.Time
+	State       JobState // 마지막 실행 결과와 실행 기록
 }
 
 // New는 새로운 스케줄러를 생성합니다
@@ -21,25 +73,411 @@ func New() *Scheduler {
 		location = time.UTC
 	}
 
+	states, err := loadState()
+	if err != nil {
+		log.Printf("⚠️  스케줄러 상태 로드 실패, 새로 시작합니다: %v\n", err)
+	}
+
 	return &Scheduler{
-		cron: cron.New(cron.WithLocation(location)),
+		cron:     cron.New(cron.WithLocation(location)),
+		location: location,
+		timers:   make(map[*time.Timer]bool),
+		states:   states,
+		groups:   make(map[string]*sync.Mutex),
 	}
 }
 
-// AddFunc는 크론 작업을 추가합니다
+// SetPanicHandler는 작업 패닉 시 호출할 함수를 설정합니다
+func (s *Scheduler) SetPanicHandler(handler PanicHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.panicHandler = handler
+}
+
+// AddFunc는 크론 작업을 추가합니다 (크론 표현식을 작업 이름으로 사용)
 func (s *Scheduler) AddFunc(spec string, cmd func()) error {
-	_, err := s.cron.AddFunc(spec, cmd)
-	return err
+	return s.AddJob(spec, spec, spec, func() error {
+		cmd()
+		return nil
+	})
+}
+
+// AddJob은 이름이 붙은 크론 작업을 추가합니다
+// 이전 실행이 끝나지 않았으면 이번 실행은 건너뛰고, 패닉은 복구하여 실패로 기록합니다
+// 실행 시각과 결과가 상태 파일에 기록되어 재시작 시 놓친 작업을 판단하는 데 사용됩니다
+func (s *Scheduler) AddJob(name, description, spec string, cmd func() error) error {
+	return s.AddGroupJob(name, "", description, spec, cmd)
+}
+
+// AddGroupJob은 작업 묶음에 속한 크론 작업을 추가합니다
+// 같은 묶음의 다른 작업(다른 일정, AddOnce, Go)이 실행 중이면 끝날 때까지 기다렸다가 실행합니다
+func (s *Scheduler) AddGroupJob(name, group, description, spec string, cmd func() error) error {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("크론 표현식 파싱 실패 (%s): %w", spec, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.jobs {
+		if existing.name == name {
+			return fmt.Errorf("이미 등록된 작업 이름입니다: %s", name)
+		}
+	}
+
+	j := &job{name: name, description: description, group: group, spec: spec, schedule: schedule, cmd: cmd, running: new(atomic.Bool)}
+	if running, ok := s.previous[name]; ok {
+		j.running = running
+	}
+	s.schedule(j)
+	s.jobs = append(s.jobs, j)
+
+	return nil
+}
+
+// schedule은 작업을 크론에 등록합니다
+func (s *Scheduler) schedule(j *job) {
+	j.entryID = s.cron.Schedule(j.schedule, cron.FuncJob(func() {
+		s.runJob(j)
+	}))
 }
 
-// Start는 스케줄러를 시작합니다
+// Reload는 등록된 정기 작업을 모두 제거하고 register로 다시 등록합니다
+// 실행 중인 작업은 중단되지 않고, 같은 이름으로 다시 등록된 작업은 실행 중 표시를 이어받아 겹쳐 실행되지 않습니다
+// register가 실패하면 이전 작업 목록으로 되돌립니다
+func (s *Scheduler) Reload(register func() error) error {
+	s.mu.Lock()
+	old := s.jobs
+	s.jobs = nil
+	s.previous = make(map[string]*atomic.Bool, len(old))
+	for _, j := range old {
+		s.cron.Remove(j.entryID)
+		s.previous[j.name] = j.running
+	}
+	s.mu.Unlock()
+
+	err := register()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.previous = nil
+
+	if err != nil {
+		for _, j := range s.jobs {
+			s.cron.Remove(j.entryID)
+		}
+		for _, j := range old {
+			s.schedule(j)
+		}
+		s.jobs = old
+		return err
+	}
+
+	s.updateNextRuns()
+	return nil
+}
+
+// Jobs는 등록된 작업 목록을 다음 실행 시각 순으로 반환합니다
+func (s *Scheduler) Jobs() []JobInfo {
+	now := time.Now().In(s.location)
+
+	s.mu.Lock()
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		infos = append(infos, JobInfo{
+			Name:        j.name,
+			Description: j.description,
+			Spec:        j.spec,
+			Next:        j.schedule.Next(now),
+			State:       s.states[j.name],
+		})
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(infos, func(a, b int) bool {
+		return infos[a].Next.Before(infos[b].Next)
+	})
+	return infos
+}
+
+// NextRuns는 작업의 다음 실행 예정 시각 n개를 반환합니다
+func (s *Scheduler) NextRuns(name string, n int) []time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.name != name {
+			continue
+		}
+
+		runs := make([]time.Time, 0, n)
+		for t := time.Now().In(s.location); len(runs) < n; {
+			t = j.schedule.Next(t)
+			if t.IsZero() {
+				break
+			}
+			runs = append(runs, t)
+		}
+		return runs
+	}
+
+	return nil
+}
+
+// runJob은 작업을 실행하고 실행 시각, 소요 시간, 결과를 저장합니다
+// 같은 작업이 아직 실행 중이면 건너뜁니다
+func (s *Scheduler) runJob(j *job) {
+	startedAt := time.Now()
+
+	if !j.running.CompareAndSwap(false, true) {
+		log.Printf("⏭  작업 건너뜀: %s (이전 실행이 아직 진행 중)\n", j.name)
+		s.recordRun(j.name, RunRecord{Started: startedAt, Outcome: OutcomeSkipped}, j.schedule.Next(startedAt))
+		return
+	}
+	defer j.running.Store(false)
+
+	unlock := s.lockGroup(j.name, j.group)
+	defer unlock()
+
+	rec := s.execute(j.name, j.cmd)
+	s.recordRun(j.name, rec, j.schedule.Next(time.Now()))
+}
+
+// execute는 작업을 패닉 복구와 함께 실행하고 실행 기록을 만듭니다
+func (s *Scheduler) execute(name string, cmd func() error) RunRecord {
+	startedAt := time.Now()
+	err := s.safeRun(name, cmd)
+	duration := time.Since(startedAt).Round(time.Millisecond)
+
+	rec := RunRecord{Started: startedAt, Duration: duration.String(), Outcome: OutcomeSuccess}
+	if err != nil {
+		rec.Outcome = OutcomeFailed
+		if _, ok := err.(*PanicError); ok {
+			rec.Outcome = OutcomePanic
+		}
+		rec.Error = err.Error()
+		log.Printf("❌ 작업 실패: %s (%s, %v)\n", name, duration, err)
+	}
+
+	return rec
+}
+
+// safeRun은 작업을 실행하고, 패닉이 발생하면 복구하여 PanicError로 반환합니다
+func (s *Scheduler) safeRun(name string, cmd func() error) (err error) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		stack := debug.Stack()
+		log.Printf("💥 작업 %s 패닉 복구: %v\n%s\n", name, recovered, stack)
+		err = &PanicError{Value: recovered, Stack: stack}
+		s.reportPanic(name, recovered, stack)
+	}()
+
+	return cmd()
+}
+
+// reportPanic은 패닉 핸들러를 호출합니다 (핸들러 자체의 패닉도 복구)
+func (s *Scheduler) reportPanic(name string, recovered interface{}, stack []byte) {
+	s.mu.Lock()
+	handler := s.panicHandler
+	s.mu.Unlock()
+
+	if handler == nil {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️  패닉 리포트 전송 중 패닉: %v\n", r)
+		}
+	}()
+	handler(name, recovered, stack)
+}
+
+// recordRun은 실행 기록과 다음 실행 예정 시각을 상태 파일에 저장합니다
+func (s *Scheduler) recordRun(name string, rec RunRecord, next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.states[name]
+	state.record(rec)
+	state.NextRun = next
+	s.states[name] = state
+
+	if err := saveState(s.states); err != nil {
+		log.Printf("⚠️  스케줄러 상태 저장 실패: %v\n", err)
+	}
+}
+
+// RunMissed는 서비스가 중지되어 있던 동안 놓친 작업을 실행합니다
+// since 이후(현재 회차)에 예정되어 있었고, window 이내에 있었으며, 그 이후 실행 기록이 없는 작업만 대상입니다
+// 놓친 작업은 원래 예정 시각 순서대로 실행되며, 실행된 작업 이름 목록을 반환합니다
+func (s *Scheduler) RunMissed(window time.Duration, since time.Time) []string {
+	now := time.Now().In(s.location)
+
+	earliest := now.Add(-window)
+	if since.After(earliest) {
+		earliest = since.In(s.location)
+	}
+
+	type missedJob struct {
+		job       *job
+		scheduled time.Time
+	}
+
+	s.mu.Lock()
+	var missed []missedJob
+	for _, j := range s.jobs {
+		scheduled, ok := prevRun(j.schedule, earliest, now)
+		if !ok {
+			continue
+		}
+		if s.states[j.name].LastRun.Before(scheduled) {
+			missed = append(missed, missedJob{job: j, scheduled: scheduled})
+		}
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(missed, func(a, b int) bool {
+		return missed[a].scheduled.Before(missed[b].scheduled)
+	})
+
+	names := make([]string, 0, len(missed))
+	for _, m := range missed {
+		log.Printf("⏪ 놓친 작업 실행: %s (예정 시각 %s)\n", m.job.name, m.scheduled.Format("01/02 15:04"))
+		s.runJob(m.job)
+		names = append(names, m.job.name)
+	}
+
+	return names
+}
+
+// prevRun은 (from, now] 구간에서 가장 최근의 예정 실행 시각을 찾습니다
+func prevRun(schedule cron.Schedule, from, now time.Time) (time.Time, bool) {
+	var prev time.Time
+	found := false
+
+	for t := schedule.Next(from); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+		prev = t
+		found = true
+	}
+
+	return prev, found
+}
+
+// AddOnce는 지정한 시각에 한 번만 실행되는 작업을 추가합니다
+// 정기 작업과 마찬가지로 패닉을 복구하고 실행 결과를 name으로 기록하며,
+// 같은 묶음(group)의 작업이 실행 중이면 끝날 때까지 기다렸다가 실행합니다
+func (s *Scheduler) AddOnce(name, group string, at time.Time, cmd func() error) {
+	delay := time.Until(at)
+	if delay < 0 {
+		delay = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.states[name]
+	state.NextRun = at
+	s.states[name] = state
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		s.mu.Lock()
+		delete(s.timers, timer)
+		s.mu.Unlock()
+
+		unlock := s.lockGroup(name, group)
+		defer unlock()
+		s.recordRun(name, s.execute(name, cmd), time.Time{})
+	})
+	s.timers[timer] = true
+}
+
+// Go는 같은 묶음의 작업이 실행 중이 아니면 cmd를 바로 백그라운드에서 실행합니다 (실행 중이면 false)
+// 정기 작업과 마찬가지로 패닉을 복구하고 실행 결과를 name으로 기록합니다
+func (s *Scheduler) Go(name, group string, cmd func() error) bool {
+	lock := s.groupLock(group)
+	if !lock.TryLock() {
+		return false
+	}
+
+	go func() {
+		defer lock.Unlock()
+		s.recordRun(name, s.execute(name, cmd), time.Time{})
+	}()
+	return true
+}
+
+// groupLock은 작업 묶음의 실행 잠금을 반환합니다
+func (s *Scheduler) groupLock(group string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.groups[group]
+	if !ok {
+		lock = new(sync.Mutex)
+		s.groups[group] = lock
+	}
+	return lock
+}
+
+// lockGroup은 작업 묶음의 다른 작업이 끝날 때까지 기다려 잠그고, 잠금을 푸는 함수를 반환합니다 (묶음이 없으면 바로 반환)
+func (s *Scheduler) lockGroup(name, group string) (unlock func()) {
+	if group == "" {
+		return func() {}
+	}
+
+	lock := s.groupLock(group)
+	if !lock.TryLock() {
+		log.Printf("⏳ %s: 같은 묶음(%s)의 작업이 끝날 때까지 기다립니다\n", name, group)
+		lock.Lock()
+	}
+	return lock.Unlock
+}
+
+// Location은 스케줄러의 시간대를 반환합니다
+func (s *Scheduler) Location() *time.Location {
+	return s.location
+}
+
+// Start는 스케줄러를 시작하고 작업별 다음 실행 시각을 기록합니다
 func (s *Scheduler) Start() {
 	s.cron.Start()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updateNextRuns()
+}
+
+// updateNextRuns는 작업별 다음 실행 시각을 상태 파일에 기록합니다 (s.mu를 잡은 상태에서 호출)
+func (s *Scheduler) updateNextRuns() {
+	now := time.Now().In(s.location)
+	for _, j := range s.jobs {
+		state := s.states[j.name]
+		state.NextRun = j.schedule.Next(now)
+		s.states[j.name] = state
+	}
+	if err := saveState(s.states); err != nil {
+		log.Printf("⚠️  스케줄러 상태 저장 실패: %v\n", err)
+	}
 }
 
 // Stop은 스케줄러를 중지합니다
 func (s *Scheduler) Stop() {
 	s.cron.Stop()
+
+	// 예약된 1회성 작업 취소
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for timer := range s.timers {
+		timer.Stop()
+	}
+	clear(s.timers)
 }
 
 // Wait는 무한 대기합니다
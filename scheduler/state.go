@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"dhlottery/datadir"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// stateFileName은 데이터 디렉토리의 state 폴더에 저장하는 실행 기록 파일입니다
+const stateFileName = "scheduler_state.json"
+
+// maxRunHistory는 작업별로 보관하는 실행 기록 수입니다
+const maxRunHistory = 20
+
+// 작업 실행 결과
+const (
+	OutcomeSuccess = "success" // 정상 완료
+	OutcomeFailed  = "failed"  // 에러 반환
+	OutcomePanic   = "panic"   // 패닉 발생 (복구됨)
+	OutcomeSkipped = "skipped" // 이전 실행이 끝나지 않아 건너뜀
+)
+
+// RunRecord는 작업 1회 실행 기록입니다
+type RunRecord struct {
+	Started  time.Time `json:"started"`
+	Duration string    `json:"duration,omitempty"`
+	Outcome  string    `json:"outcome"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// JobState는 작업별 마지막 실행 정보입니다
+type JobState struct {
+	LastRun      time.Time   `json:"lastRun"`
+	NextRun      time.Time   `json:"nextRun,omitempty"`
+	LastOutcome  string      `json:"lastOutcome,omitempty"`
+	LastDuration string      `json:"lastDuration,omitempty"`
+	LastError    string      `json:"lastError,omitempty"`
+	History      []RunRecord `json:"history,omitempty"`
+}
+
+// record는 실행 기록을 추가합니다 (건너뛴 실행은 마지막 실행 시각을 바꾸지 않음)
+func (st *JobState) record(rec RunRecord) {
+	if rec.Outcome != OutcomeSkipped {
+		st.LastRun = rec.Started
+		st.LastOutcome = rec.Outcome
+		st.LastDuration = rec.Duration
+		st.LastError = rec.Error
+	}
+
+	st.History = append(st.History, rec)
+	if len(st.History) > maxRunHistory {
+		st.History = st.History[len(st.History)-maxRunHistory:]
+	}
+}
+
+// loadState는 저장된 작업 실행 상태를 읽어옵니다 (파일이 없으면 빈 상태)
+func loadState() (map[string]JobState, error) {
+	states := make(map[string]JobState)
+
+	data, err := os.ReadFile(datadir.Path(datadir.State, stateFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return states, nil
+		}
+		return states, fmt.Errorf("상태 파일 읽기 실패: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &states); err != nil {
+		return make(map[string]JobState), fmt.Errorf("상태 파일 파싱 실패: %w", err)
+	}
+
+	return states, nil
+}
+
+// saveState는 작업 실행 상태를 저장합니다 (임시 파일에 쓴 뒤 이름을 바꿔 중간에 끊겨도 기존 기록 유지)
+func saveState(states map[string]JobState) error {
+	if err := os.MkdirAll(datadir.Path(datadir.State), 0700); err != nil {
+		return fmt.Errorf("상태 디렉토리 생성 실패: %w", err)
+	}
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON 마샬링 실패: %w", err)
+	}
+
+	path := datadir.Path(datadir.State, stateFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("상태 파일 저장 실패: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("상태 파일 저장 실패: %w", err)
+	}
+
+	return nil
+}
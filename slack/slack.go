@@ -0,0 +1,122 @@
+// Package slack은 슬랙 incoming webhook으로 Block Kit 메시지를 보냅니다
+//
+// 웹훅은 슬랙 앱의 Incoming Webhooks에서 채널별로 만들며 (https://hooks.slack.com/services/...),
+// 사진은 올릴 수 없어 텍스트와 블록만 보냅니다.
+package slack
+
+import (
+	"dhlottery/webhook"
+	"time"
+)
+
+// Channel은 알림 채널 이름입니다 (보관함, 로그 표시용)
+const Channel = "slack"
+
+// Block Kit 길이 제한 (넘으면 잘라서 보냄)
+const (
+	MaxHeaderLength  = 150  // header 블록
+	MaxSectionLength = 3000 // section 블록 텍스트
+	MaxFieldLength   = 2000 // section 블록 항목 하나
+	MaxFields        = 10   // section 블록 항목 수
+	MaxBlocks        = 50   // 메시지 하나의 블록 수
+)
+
+// Payload는 웹훅으로 보내는 메시지입니다
+// Text는 알림과 블록을 표시할 수 없는 곳에 쓰이는 대체 문구이며, Blocks가 없으면 본문이 됩니다
+type Payload struct {
+	Text   string  `json:"text"`
+	Blocks []Block `json:"blocks,omitempty"`
+}
+
+// Block은 Block Kit 블록입니다 (header, section, context, divider)
+type Block struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text,omitempty"`
+	Fields   []Text `json:"fields,omitempty"`
+	Elements []Text `json:"elements,omitempty"`
+}
+
+// Text는 Block Kit 텍스트 객체입니다 (plain_text 또는 mrkdwn)
+type Text struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+// Header는 제목 블록입니다 (꾸밈 없는 텍스트)
+func Header(text string) Block {
+	return Block{Type: "header", Text: &Text{Type: "plain_text", Text: truncate(text, MaxHeaderLength), Emoji: true}}
+}
+
+// Section은 mrkdwn 본문 블록입니다
+func Section(text string) Block {
+	return Block{Type: "section", Text: Mrkdwn(truncate(text, MaxSectionLength))}
+}
+
+// Fields는 두 열로 표시되는 항목 블록입니다 (MaxFields개까지)
+func Fields(fields ...Text) Block {
+	if len(fields) > MaxFields {
+		fields = fields[:MaxFields]
+	}
+	for i := range fields {
+		fields[i].Text = truncate(fields[i].Text, MaxFieldLength)
+	}
+	return Block{Type: "section", Fields: fields}
+}
+
+// Context는 작은 글씨의 꼬리말 블록입니다
+func Context(text string) Block {
+	return Block{Type: "context", Elements: []Text{*Mrkdwn(truncate(text, MaxSectionLength))}}
+}
+
+// Divider는 구분선 블록입니다
+func Divider() Block {
+	return Block{Type: "divider"}
+}
+
+// Mrkdwn은 mrkdwn 텍스트 객체입니다
+func Mrkdwn(text string) *Text {
+	return &Text{Type: "mrkdwn", Text: text}
+}
+
+// Webhook은 슬랙 incoming webhook입니다
+type Webhook struct {
+	hook *webhook.Hook
+}
+
+// New는 웹훅 주소로 슬랙 웹훅을 생성합니다 (queue가 nil이면 바로 전송)
+func New(url string, queue webhook.Queue) *Webhook {
+	return &Webhook{hook: webhook.New(Channel, url, queue)}
+}
+
+// URL은 웹훅 주소입니다
+func (w *Webhook) URL() string {
+	return w.hook.URL
+}
+
+// Send는 메시지를 바로 보냅니다
+func (w *Webhook) Send(p Payload) error {
+	return w.hook.Send(p.limit())
+}
+
+// SendSafe는 메시지를 보관함을 거쳐 보내고 에러를 로그로 출력합니다 (notBefore 이후에 전송)
+func (w *Webhook) SendSafe(p Payload, notBefore time.Time) {
+	w.hook.SendSafe(p.limit(), notBefore)
+}
+
+// limit은 블록 수 제한을 넘는 블록을 버립니다
+func (p Payload) limit() Payload {
+	if len(p.Blocks) > MaxBlocks {
+		p.Blocks = p.Blocks[:MaxBlocks]
+	}
+	return p
+}
+
+// truncate는 문자열을 최대 n글자로 자릅니다
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
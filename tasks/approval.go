@@ -0,0 +1,156 @@
+package tasks
+
+import (
+	"dhlottery/config"
+	"dhlottery/lottery"
+	"dhlottery/telegram"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// 승인 버튼 콜백 데이터
+const (
+	approvalCallbackBuy  = "buy"
+	approvalCallbackSkip = "skip"
+)
+
+// approvalRequest는 보낸 구매 승인 요청입니다
+type approvalRequest struct {
+	name      string // 로그에 표시할 계정 이름
+	bot       *telegram.Bot
+	messageID int
+	message   string
+	expires   time.Time // 응답을 기다리는 마지막 시각
+
+	timeoutDecision string
+	decision        string // 결정된 값 (요청을 보내지 못했으면 바로 기본 동작)
+}
+
+// sendApproval은 텔레그램으로 구매 승인을 요청합니다 (응답은 wait로 기다림)
+// 응답 대기는 계정의 승인 대기 시간과 deadline(판매 마감 - 재시도 여유 시간) 중 이른 시각까지입니다
+func sendApproval(account config.Account, bot *telegram.Bot, gameInfo lottery.LottoGameInfo, balance, quantity int, deadline time.Time) *approvalRequest {
+	approval := account.Approval
+
+	req := &approvalRequest{
+		name:            account.DisplayName(),
+		bot:             bot,
+		expires:         time.Now().Add(approval.TimeoutDuration()),
+		timeoutDecision: lottery.ApprovalTimeoutSkipped,
+	}
+	if deadline.Before(req.expires) {
+		req.expires = deadline
+	}
+
+	timeoutLabel := "건너뜁니다"
+	if approval.BuyOnTimeout() {
+		req.timeoutDecision = lottery.ApprovalTimeoutBuy
+		timeoutLabel = "구매합니다"
+	}
+
+	if bot == nil {
+		log.Printf("⚠️  텔레그램 설정이 없어 승인을 요청할 수 없습니다. 기본 동작으로 진행합니다 (%s)\n", req.timeoutDecision)
+		req.decision = req.timeoutDecision
+		return req
+	}
+
+	req.message = fmt.Sprintf(
+		"(%s) 🛒 <b>로또 구매 승인 요청</b>\n\n"+
+			"📅 %s회 (추첨일 %s)\n"+
+			"🎱 자동 <b>%d게임</b>\n"+
+			"💰 구매 금액: <b>%s원</b>\n"+
+			"💳 현재 예치금: %s원\n\n"+
+			"⏰ %s까지 응답이 없으면 %s.",
+		telegram.Escape(account.DisplayName()),
+		gameInfo.CurRound,
+		gameInfo.RoundDrawDate,
+		quantity,
+		lottery.FormatMoney(quantity*1000),
+		lottery.FormatMoney(balance),
+		req.expires.Format("01/02 15:04"),
+		timeoutLabel,
+	)
+
+	messageID, err := bot.SendMessageWithButtons(req.message, []telegram.InlineButton{
+		{Text: "✅ 구매", CallbackData: approvalCallbackBuy},
+		{Text: "⏭ 건너뛰기", CallbackData: approvalCallbackSkip},
+	})
+	if err != nil {
+		log.Printf("⚠️  승인 요청 전송 실패, 기본 동작으로 진행합니다: %v\n", err)
+		req.decision = req.timeoutDecision
+		return req
+	}
+	req.messageID = messageID
+
+	log.Printf("📨 구매 승인 요청 전송 (%s까지 응답 대기)\n", req.expires.Format("01/02 15:04"))
+	return req
+}
+
+// wait는 승인 응답을 기다려 결정을 반환합니다
+func (req *approvalRequest) wait() string {
+	if req.decision != "" {
+		return req.decision
+	}
+
+	data, answered, err := req.bot.WaitForCallback(req.messageID, time.Until(req.expires))
+	if err != nil {
+		log.Printf("⚠️  %s: 승인 응답 확인 실패, 기본 동작으로 진행합니다: %v\n", req.name, err)
+	}
+
+	req.decision = req.timeoutDecision
+	if answered {
+		switch data {
+		case approvalCallbackBuy:
+			req.decision = lottery.ApprovalApproved
+		case approvalCallbackSkip:
+			req.decision = lottery.ApprovalSkipped
+		}
+	}
+
+	var result string
+	switch req.decision {
+	case lottery.ApprovalApproved:
+		result = "✅ 구매 승인됨"
+	case lottery.ApprovalSkipped:
+		result = "⏭ 이번 회차 건너뜀"
+	case lottery.ApprovalTimeoutBuy:
+		result = "⌛ 응답 없음 → 구매 진행"
+	default:
+		result = "⌛ 응답 없음 → 건너뜀"
+	}
+	log.Printf("📝 %s 구매 승인 결정: %s\n", req.name, result)
+
+	if err := req.bot.EditMessage(req.messageID, req.message+"\n\n<b>"+result+"</b>"); err != nil {
+		log.Printf("⚠️  승인 메시지 수정 실패: %v\n", err)
+	}
+
+	return req.decision
+}
+
+// waitApprovals는 보낸 승인 요청들의 응답을 동시에 기다립니다
+// 계정마다 차례로 기다리면 계정 수만큼 대기 시간이 늘어나 뒤 계정이 판매 마감을 놓칠 수 있습니다
+func waitApprovals(requests []*approvalRequest) {
+	pending := 0
+	for _, req := range requests {
+		if req.decision == "" {
+			pending++
+		}
+	}
+	if pending == 0 {
+		return
+	}
+
+	log.Println()
+	log.Printf("⏳ 구매 승인 대기 중... (%d개 계정)\n", pending)
+
+	var wg sync.WaitGroup
+	for _, req := range requests {
+		wg.Add(1)
+		go func(req *approvalRequest) {
+			defer wg.Done()
+			req.wait()
+		}(req)
+	}
+	wg.Wait()
+}
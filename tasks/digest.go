@@ -0,0 +1,226 @@
+package tasks
+
+import (
+	"dhlottery/config"
+	"dhlottery/datadir"
+	"dhlottery/lottery"
+	"dhlottery/message"
+	"dhlottery/notify"
+	"dhlottery/report"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// lowBalance는 예치금 부족 알림 기준 금액입니다 (5게임 구매 금액)
+const lowBalance = 10000
+
+// balancesFileName은 데이터 디렉토리의 state 폴더에 저장하는 계정별 마지막 예치금 파일입니다 (회차 요약에 사용)
+const balancesFileName = "balances.json"
+
+// savedBalance는 마지막으로 확인한 예치금입니다 (구매 후에는 구매 금액을 뺀 값)
+type savedBalance struct {
+	Balance   int       `json:"balance"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+var balancesMu sync.Mutex
+
+// loadBalances는 저장된 계정별 예치금을 읽어옵니다 (파일이 없으면 빈 목록)
+func loadBalances() (map[string]savedBalance, error) {
+	balances := make(map[string]savedBalance)
+
+	data, err := os.ReadFile(datadir.Path(datadir.State, balancesFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return balances, nil
+		}
+		return balances, fmt.Errorf("예치금 기록 파일 읽기 실패: %w", err)
+	}
+	if err := json.Unmarshal(data, &balances); err != nil {
+		return make(map[string]savedBalance), fmt.Errorf("예치금 기록 파일 파싱 실패: %w", err)
+	}
+	return balances, nil
+}
+
+// saveBalance는 계정의 예치금을 기록합니다 (실패해도 작업은 계속, 중간에 끊겨도 기존 기록 유지)
+func saveBalance(userID string, balance int) {
+	balancesMu.Lock()
+	defer balancesMu.Unlock()
+
+	balances, err := loadBalances()
+	if err != nil {
+		log.Printf("⚠️  %v (새로 기록합니다)\n", err)
+	}
+	balances[userID] = savedBalance{Balance: balance, CheckedAt: time.Now()}
+
+	data, err := json.MarshalIndent(balances, "", "  ")
+	if err == nil {
+		err = datadir.WriteFile(datadir.Path(datadir.State, balancesFileName), data)
+	}
+	if err != nil {
+		log.Printf("⚠️  예치금 기록 저장 실패: %v\n", err)
+	}
+}
+
+// chatGroup은 같은 곳(채팅방, 웹훅)으로 요약을 받는 계정들입니다
+type chatGroup struct {
+	dest    config.Destination
+	indexes []int // 계정 목록의 인덱스
+}
+
+// groupByChat은 계정들을 이벤트를 받을 곳별로 묶습니다 (include가 false인 계정은 제외)
+func groupByChat(accounts []config.Account, event string, include func(config.Account) bool) []chatGroup {
+	var groups []chatGroup
+	for i, account := range accounts {
+		if !include(account) {
+			continue
+		}
+		for _, dest := range account.Recipients(event, message.SeverityInfo) {
+			j := slices.IndexFunc(groups, func(g chatGroup) bool { return g.dest == dest })
+			if j < 0 {
+				groups = append(groups, chatGroup{dest: dest})
+				j = len(groups) - 1
+			}
+			groups[j].indexes = append(groups[j].indexes, i)
+		}
+	}
+	return groups
+}
+
+// send는 요약을 받는 곳으로 보냅니다 (계정 알림과 같은 전송 정책 적용)
+func (g chatGroup) send(accounts []config.Account, hub *notify.Hub, msg notify.Message) {
+	to := hub.To(g.dest)
+	if to == nil {
+		return
+	}
+	policy := accounts[g.indexes[0]].NotifyPolicy()
+	if d, ok := gate(policy, to, msg.Event, msg.Severity, msg.Text, notify.Options{}); ok {
+		d.to.Send(msg, d.opts)
+	}
+}
+
+// sendDigest는 요약 모드인 계정들의 작업 결과를 받는 곳별로 모아 요약 하나로 보냅니다
+// rows는 accounts와 같은 순서입니다
+func sendDigest(accounts []config.Account, hub *notify.Hub, task string, rows []message.DigestRow) {
+	if hub == nil {
+		return
+	}
+
+	for _, group := range groupByChat(accounts, message.EventDigest, config.Account.Digest) {
+		data := message.Digest{Task: task, Threshold: lowBalance}
+		for _, i := range group.indexes {
+			row := rows[i]
+			data.Rows = append(data.Rows, row)
+			data.Games += row.Games
+			data.Amount += row.Amount
+			if row.Status == report.StatusFailed {
+				data.Failures++
+			}
+			if row.Low {
+				data.Low++
+			}
+		}
+		group.send(accounts, hub, compose(message.EventDigest, message.SeverityInfo, data))
+	}
+}
+
+// balanceDigestRow는 예치금 확인 결과를 요약 행으로 바꿉니다
+func balanceDigestRow(account config.Account, record report.Balance) message.DigestRow {
+	row := message.DigestRow{Account: account.DisplayName()}
+	if !record.OK {
+		row.Status = report.StatusFailed
+		row.Reason = record.Error
+		return row
+	}
+	row.HasBalance = true
+	row.Balance = record.Balance
+	row.Low = record.Balance < lowBalance
+	return row
+}
+
+// purchaseDigestRow는 구매 결과를 요약 행으로 바꿉니다
+func purchaseDigestRow(account config.Account, record report.Purchase) message.DigestRow {
+	row := message.DigestRow{
+		Account: account.DisplayName(),
+		Status:  record.Status,
+		Games:   len(record.Games),
+		Amount:  record.Amount,
+		Reason:  record.Reason,
+	}
+	if record.Balance != nil {
+		row.HasBalance = true
+		row.Balance = *record.Balance
+		row.Low = row.Balance < lowBalance
+	}
+	return row
+}
+
+// sendWeekly는 당첨 확인 후 회차 요약(구매 금액, 당첨금, 최고 등수, 예치금 부족 계정)을 받는 곳별로 보냅니다
+// records는 cfg.Accounts와 같은 순서입니다
+func sendWeekly(cfg config.Config, hub *notify.Hub, result *lottery.LottoResult, records []report.Winning) {
+	if hub == nil {
+		return
+	}
+
+	balances, err := loadBalances()
+	if err != nil {
+		log.Printf("⚠️  %v\n", err)
+	}
+
+	all := func(config.Account) bool { return true }
+	for _, group := range groupByChat(cfg.Accounts, message.EventWeekly, all) {
+		data := message.Weekly{
+			Round:     result.Round,
+			DrawDate:  result.DrawDate,
+			Numbers:   result.Numbers,
+			Bonus:     result.BonusNumber,
+			Threshold: lowBalance,
+		}
+		for _, i := range group.indexes {
+			account, record := cfg.Accounts[i], records[i]
+
+			row := message.WeeklyRow{
+				Account:  account.DisplayName(),
+				Status:   record.Status,
+				Games:    len(record.Games),
+				Spent:    len(record.Games) * 1000,
+				BestRank: record.BestRank,
+			}
+			for _, game := range record.Games {
+				if game.Rank == 0 {
+					continue
+				}
+				row.Wins++
+				prize := int(result.Prize(game.Rank))
+				if prize == 0 {
+					data.PrizeUnknown = true
+				}
+				row.Won += prize
+			}
+			data.Rows = append(data.Rows, row)
+
+			data.Spent += row.Spent
+			data.Won += row.Won
+			data.Wins += row.Wins
+			if row.BestRank > 0 && (data.BestRank == 0 || row.BestRank < data.BestRank) {
+				data.BestRank = row.BestRank
+			}
+
+			if saved, ok := balances[account.UserID]; ok && saved.Balance < lowBalance {
+				data.LowBalances = append(data.LowBalances, message.Balance{
+					Account:   account.DisplayName(),
+					Kind:      message.BalanceLow,
+					Balance:   saved.Balance,
+					Threshold: lowBalance,
+				})
+			}
+		}
+
+		group.send(cfg.Accounts, hub, compose(message.EventWeekly, message.SeverityInfo, data))
+	}
+}
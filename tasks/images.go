@@ -0,0 +1,112 @@
+package tasks
+
+import (
+	"dhlottery/lottery"
+	"dhlottery/notify"
+	"dhlottery/render"
+	"dhlottery/telegram"
+	"fmt"
+	"html"
+	"log"
+)
+
+// ticketPhoto는 구매한 번호를 공 이미지로 그립니다 (구매 실패면 false)
+func ticketPhoto(name string, result map[string]interface{}, prefix string) (telegram.Photo, bool) {
+	games, ok := lottery.PurchasedGames(result)
+	if !ok || len(games) == 0 {
+		return telegram.Photo{}, false
+	}
+
+	round, drawDate := lottery.PurchaseDrawInfo(result)
+	data, err := render.Ticket(round, drawDate, renderGames(games))
+	if err != nil {
+		log.Printf("⚠️  구매 번호 이미지 생성 실패: %v\n", err)
+		return telegram.Photo{}, false
+	}
+
+	caption := fmt.Sprintf("%s(%s) 🎱 <b>%s회 구매 번호</b>", prefix, html.EscapeString(name), html.EscapeString(round))
+	return telegram.Photo{Data: data, Caption: caption}, true
+}
+
+// resultPhoto는 계정의 당첨 결과 카드 이미지를 만듭니다 (구매 내역이 없거나 건너뛴 회차면 false, name은 캡션에 표시할 이름)
+func resultPhoto(userID, name string, result *lottery.LottoResult, history *lottery.PurchaseHistory) (telegram.Photo, bool) {
+	if history == nil || history.Round != result.Round {
+		return telegram.Photo{}, false
+	}
+	purchase, ok := history.Users[userID]
+	if !ok || !purchase.Success || len(purchase.Games) == 0 || lottery.IsApprovalSkip(purchase.Approval) {
+		return telegram.Photo{}, false
+	}
+
+	games := make([]render.GameResult, len(purchase.Games))
+	bestRank := 0
+	for i, game := range purchase.Games {
+		rank, matches, bonus := lottery.CheckWinning(game.Numbers, result)
+		games[i] = render.GameResult{Game: renderGame(game), Rank: rank, Matches: matches, Bonus: bonus}
+		if rank > 0 && (bestRank == 0 || rank < bestRank) {
+			bestRank = rank
+		}
+	}
+
+	draw := render.Draw{Round: result.Round, Date: result.DrawDate, Numbers: result.Numbers, Bonus: result.BonusNumber}
+	data, err := render.Result(draw, games)
+	if err != nil {
+		log.Printf("⚠️  당첨 결과 이미지 생성 실패: %v\n", err)
+		return telegram.Photo{}, false
+	}
+
+	summary := "낙첨"
+	if bestRank > 0 {
+		summary = fmt.Sprintf("🎉 %d등 당첨", bestRank)
+	}
+	caption := fmt.Sprintf("(%s) 🎰 <b>%s회 당첨 결과</b> - %s", html.EscapeString(name), html.EscapeString(result.Round), summary)
+	return telegram.Photo{Data: data, Caption: caption}, true
+}
+
+// renderGames는 구매 내역의 게임을 이미지용 게임으로 바꿉니다
+func renderGames(games []lottery.GamePurchase) []render.Game {
+	out := make([]render.Game, len(games))
+	for i, game := range games {
+		out[i] = renderGame(game)
+	}
+	return out
+}
+
+func renderGame(game lottery.GamePurchase) render.Game {
+	return render.Game{Label: game.Type, Numbers: game.Numbers, Auto: game.Auto}
+}
+
+// photoBatch는 받는 곳별로 모은 사진입니다 (추가한 순서대로 전송, 사진을 보낼 수 있는 곳만)
+type photoBatch struct {
+	targets []delivery
+	photos  map[string][]telegram.Photo
+}
+
+// add는 사진을 받는 곳 묶음에 추가합니다 (조용한 시간에 미룬 메시지가 있으면 가장 늦은 시각에 함께 전송)
+func (b *photoBatch) add(d delivery, photo telegram.Photo) {
+	if _, ok := d.to.(notify.PhotoSender); !ok {
+		return
+	}
+	if b.photos == nil {
+		b.photos = make(map[string][]telegram.Photo)
+	}
+	key := d.to.Key()
+	if _, ok := b.photos[key]; !ok {
+		b.targets = append(b.targets, d)
+	} else {
+		for i := range b.targets {
+			if b.targets[i].to.Key() == key && d.opts.NotBefore.After(b.targets[i].opts.NotBefore) {
+				b.targets[i].opts.NotBefore = d.opts.NotBefore
+			}
+		}
+	}
+	b.photos[key] = append(b.photos[key], photo)
+}
+
+func (b *photoBatch) send() {
+	for _, d := range b.targets {
+		opts := d.opts
+		opts.Silent = true
+		d.to.(notify.PhotoSender).SendPhotos(b.photos[d.to.Key()], opts)
+	}
+}
@@ -0,0 +1,157 @@
+package tasks
+
+import (
+	"dhlottery/config"
+	"dhlottery/lottery"
+	"dhlottery/message"
+	"dhlottery/notify"
+	"dhlottery/telegram"
+	"dhlottery/throttle"
+	"log"
+	"strings"
+	"time"
+)
+
+// notifier는 알림을 라우팅 규칙에 맞는 곳(텔레그램 채팅방, 슬랙·디스코드 웹훅)으로 전송 정책을 적용해 보냅니다
+type notifier struct {
+	hub        *notify.Hub
+	name       string // 메시지에 표시할 계정 이름 (특정 계정이 아닌 알림이면 빈 문자열)
+	recipients func(event string, severity message.Severity) []config.Destination
+	policy     throttle.Policy // 조용한 시간, 중복 제거, 전송 제한
+	digest     bool            // 요약 모드 (error 미만 알림은 보내지 않고 작업 요약에 포함)
+	buying     bool            // 구매 작업 (판매 마감 임박 실패는 critical)
+}
+
+// accountNotifier는 계정 알림을 보내는 notifier입니다 (계정의 수신자와 별칭 사용)
+func accountNotifier(account config.Account, hub *notify.Hub) notifier {
+	return notifier{
+		hub:        hub,
+		name:       account.DisplayName(),
+		recipients: account.Recipients,
+		policy:     account.NotifyPolicy(),
+		digest:     account.Digest(),
+	}
+}
+
+// configNotifier는 특정 계정이 아닌 알림을 보내는 notifier입니다 (원래 한 번만 보내는 알림이라 요약하지 않음)
+func configNotifier(cfg config.Config, hub *notify.Hub) notifier {
+	return notifier{hub: hub, recipients: cfg.Recipients, policy: cfg.NotifyPolicy()}
+}
+
+// delivery는 받는 곳 하나로 보낼 알림입니다 (조용한 시간이면 opts.NotBefore에 보낼 시각)
+type delivery struct {
+	to   notify.Notifier
+	opts notify.Options
+}
+
+// deliveries는 이벤트를 받을 곳별 전송 방법입니다
+// 채널 설정이 없거나, 요약 모드에서 모아 보낼 알림이거나, 전송 정책에 따라 버린 곳은 빠집니다
+func (n notifier) deliveries(event string, severity message.Severity, text string, opts notify.Options) []delivery {
+	if n.hub == nil {
+		return nil
+	}
+	severity = n.escalate(severity)
+	if n.digest && severity < message.SeverityError {
+		log.Printf("📋 요약 모드: %s 알림은 작업 요약으로 보냅니다\n", event)
+		return nil
+	}
+
+	var out []delivery
+	for _, dest := range n.recipients(event, severity) {
+		to := n.hub.To(dest)
+		if to == nil {
+			continue
+		}
+		if d, ok := gate(n.policy, to, event, severity, text, opts); ok {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// escalate는 구매 작업에서 판매 마감이 임박했을 때의 실패를 critical로 올립니다 (조용한 시간에도 바로 알림)
+func (n notifier) escalate(severity message.Severity) message.Severity {
+	if !n.buying || severity != message.SeverityError {
+		return severity
+	}
+	now := time.Now()
+	if lottery.SaleDeadline(now).Sub(now) <= retryUrgentWithin {
+		return message.SeverityCritical
+	}
+	return severity
+}
+
+// gate는 받는 곳 하나에 전송 정책을 적용합니다 (보내지 않을 알림이면 false)
+func gate(policy throttle.Policy, to notify.Notifier, event string, severity message.Severity, text string, opts notify.Options) (delivery, bool) {
+	decision := policy.Check(time.Now(), to.Key(), event, severity, text)
+	switch decision.Action {
+	case throttle.Drop:
+		log.Printf("🔕 %s 알림을 보내지 않습니다: %s\n", event, decision.Reason)
+		return delivery{}, false
+	case throttle.Defer:
+		log.Printf("🌙 %s 알림은 %s에 보냅니다 (%s)\n", event, decision.Until.Format("01/02 15:04"), decision.Reason)
+		opts.NotBefore = decision.Until
+	}
+	return delivery{to: to, opts: opts}, true
+}
+
+// compose는 이벤트(세부 종류 포함) 데이터로 보낼 알림을 만듭니다 (텔레그램 문구는 템플릿으로 생성)
+func compose(event string, severity message.Severity, data interface{}) notify.Message {
+	head, _, _ := strings.Cut(event, ".")
+	return notify.Message{
+		Event:    event,
+		Severity: severity,
+		Data:     data,
+		Text:     message.Text(head, message.Telegram, data),
+	}
+}
+
+// send는 알림을 이벤트를 받을 모든 곳으로 보냅니다
+func (n notifier) send(msg notify.Message, opts notify.Options) {
+	for _, d := range n.deliveries(msg.Event, msg.Severity, msg.Text, opts) {
+		d.to.Send(msg, d.opts)
+	}
+}
+
+// failure는 작업 실패 알림을 보냅니다 (이벤트 failure.<종류>)
+func (n notifier) failure(kind, stage string, err error) {
+	data := message.Failure{Account: n.name, Kind: kind, Stage: stage, Detail: err.Error()}
+	n.send(compose(message.EventFailure+"."+kind, message.SeverityError, data), notify.Options{})
+}
+
+// balance는 예치금 알림을 보냅니다 (이벤트 balance.<종류>, 구매할 수 없을 만큼 부족하면 error)
+func (n notifier) balance(data message.Balance) {
+	data.Account = n.name
+	severity := message.SeverityWarning
+	if data.Kind == message.BalanceInsufficient {
+		severity = message.SeverityError
+	}
+	n.send(compose(message.EventBalance+"."+data.Kind, severity, data), notify.Options{})
+}
+
+// purchase는 구매 결과 메시지와 구매 번호 이미지를 보냅니다 (구매 실패면 error)
+// text는 구매할 때 만든 텔레그램 문구이고, 슬랙·디스코드용 카드는 구매 응답에서 다시 만듭니다
+func (n notifier) purchase(result map[string]interface{}, text, prefix string) {
+	severity := message.SeverityInfo
+	if !lottery.IsBuySuccess(result) {
+		severity = message.SeverityError
+	}
+
+	data := lottery.PurchaseMessage(n.name, result, 0)
+	data.DryRun = prefix != ""
+	msg := notify.Message{Event: message.EventPurchase, Severity: severity, Data: data, Text: text}
+
+	deliveries := n.deliveries(msg.Event, severity, text, notify.Options{})
+	photo, hasPhoto := telegram.Photo{}, false
+	if len(deliveries) > 0 && n.hub.Images() {
+		photo, hasPhoto = ticketPhoto(n.name, result, prefix)
+	}
+	for _, d := range deliveries {
+		d.to.Send(msg, d.opts)
+		if sender, ok := d.to.(notify.PhotoSender); ok && hasPhoto {
+			opts := d.opts
+			opts.Silent = true
+			sender.SendPhotos([]telegram.Photo{photo}, opts)
+		}
+	}
+}
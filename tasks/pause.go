@@ -0,0 +1,223 @@
+package tasks
+
+import (
+	"dhlottery/config"
+	"dhlottery/lottery"
+	"dhlottery/notify"
+	"dhlottery/pause"
+	"dhlottery/scheduler"
+	"dhlottery/telegram"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SkipPaused는 일시정지된 계정을 제외한 설정을 반환합니다 (실행할 계정이 없으면 false)
+// 당첨 확인은 일시정지 전에 구매한 내역이 있는 계정은 그대로 진행합니다
+func SkipPaused(cfg config.Config, hub *notify.Hub, job, label string) (config.Config, bool) {
+	var keep func(config.Account) bool
+	if job == config.JobWinning {
+		keep = hasLastPurchase()
+	}
+
+	active, skipped := filterPaused(cfg.Accounts, keep)
+	if len(skipped) > 0 {
+		notifyPaused(hub, label, skipped, job != config.JobWinning)
+	}
+
+	cfg.Accounts = active
+	return cfg, len(active) > 0
+}
+
+// pausedAccount는 일시정지로 건너뛴 계정입니다
+type pausedAccount struct {
+	UserID string
+	Name   string // 메시지에 표시할 이름 (별칭)
+	Entry  pause.Entry
+}
+
+// filterPaused는 계정을 실행할 계정과 일시정지로 건너뛸 계정으로 나눕니다
+// keep이 true를 반환하는 계정은 일시정지 중이어도 실행합니다
+func filterPaused(accounts []config.Account, keep func(config.Account) bool) ([]config.Account, []pausedAccount) {
+	state, err := pause.Load()
+	if err != nil {
+		// 상태를 읽을 수 없으면 일시정지 없이 진행
+		log.Printf("⚠️  %v\n", err)
+		return accounts, nil
+	}
+
+	now := time.Now()
+	active := make([]config.Account, 0, len(accounts))
+	var skipped []pausedAccount
+	for _, account := range accounts {
+		entry, paused := state.Active(account.UserID, now)
+		if paused && (keep == nil || !keep(account)) {
+			skipped = append(skipped, pausedAccount{UserID: account.UserID, Name: account.DisplayName(), Entry: entry})
+			continue
+		}
+		active = append(active, account)
+	}
+
+	return active, skipped
+}
+
+// hasLastPurchase는 마지막 구매 내역에 구매 성공 기록이 있는 계정인지 확인하는 함수를 반환합니다
+func hasLastPurchase() func(config.Account) bool {
+	history, err := lottery.GetLastPurchaseHistory()
+	if err != nil || history == nil {
+		return nil
+	}
+	return func(account config.Account) bool {
+		return history.Users[account.UserID].Success
+	}
+}
+
+// notifyPaused는 일시정지로 건너뛴 계정을 로그와 알림으로 알립니다
+func notifyPaused(hub *notify.Hub, label string, skipped []pausedAccount, report bool) {
+	var sb strings.Builder
+	for _, p := range skipped {
+		log.Printf("⏸  %s 건너뜀: %s (일시정지 %s)\n", label, p.UserID, p.Entry.Describe())
+		sb.WriteString(fmt.Sprintf("• %s: %s\n", telegram.Escape(p.Name), telegram.Escape(p.Entry.Describe())))
+	}
+
+	if report {
+		hub.Broadcast(fmt.Sprintf("⏸ <b>일시정지 중 - %s 건너뜀</b>\n\n%s", label, sb.String()), notify.Options{Silent: true})
+	}
+}
+
+// PrintPauseStatus는 현재 일시정지 상태를 출력합니다
+func PrintPauseStatus(cfg config.Config) {
+	lines, err := PauseStatus(cfg)
+	if err != nil {
+		log.Printf("⚠️  %v\n", err)
+		return
+	}
+	for _, line := range lines {
+		log.Println(line)
+	}
+}
+
+// PauseStatus는 현재 일시정지 상태를 한 줄씩 설명합니다 (설정된 계정만)
+func PauseStatus(cfg config.Config) ([]string, error) {
+	state, err := pause.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	now := time.Now()
+	if state.Global != nil && state.Global.ActiveAt(now) {
+		lines = append(lines, fmt.Sprintf("⏸  전체 일시정지: %s", state.Global.Describe()))
+	}
+
+	for _, account := range cfg.Accounts {
+		if entry, ok := state.Accounts[account.UserID]; ok && entry.ActiveAt(now) {
+			lines = append(lines, fmt.Sprintf("⏸  계정 일시정지: %s - %s", account.UserID, entry.Describe()))
+		}
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, "▶️  일시정지 없음 (모든 계정 실행)")
+	}
+	return lines, nil
+}
+
+// Pause는 계정(userID가 비어 있으면 전체)을 until까지 일시정지합니다 (until이 비어 있으면 재개할 때까지)
+func Pause(cfg config.Config, userID string, until time.Time, reason string) (pause.Entry, error) {
+	if err := checkAccount(cfg, userID); err != nil {
+		return pause.Entry{}, err
+	}
+
+	state, err := pause.Load()
+	if err != nil {
+		return pause.Entry{}, err
+	}
+
+	entry := pause.Entry{Until: until, Reason: reason, PausedAt: time.Now()}
+	state.Pause(userID, entry)
+	if err := state.Save(); err != nil {
+		return pause.Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// Resume은 계정(userID가 비어 있으면 전체)의 일시정지를 해제합니다
+func Resume(cfg config.Config, userID string) (bool, error) {
+	if err := checkAccount(cfg, userID); err != nil {
+		return false, err
+	}
+
+	state, err := pause.Load()
+	if err != nil {
+		return false, err
+	}
+
+	resumed := state.Resume(userID)
+	if err := state.Save(); err != nil {
+		return false, err
+	}
+
+	return resumed, nil
+}
+
+// SkipRoundsUntil은 다음 예약 구매가 구매할 회차부터 n개 회차를 건너뛸 때의 해제 시각(마지막 회차 판매 마감)과 건너뛸 회차 범위를 반환합니다
+// 이번 회차를 이미 구매한 뒤라면 다음 예약 구매는 다음 회차를 사므로, 그 회차부터 셉니다 (userID가 비어 있으면 전체 계정의 구매 일정 기준)
+func SkipRoundsUntil(cfg config.Config, userID string, now time.Time, n int) (until time.Time, first, last int) {
+	next := nextBuyRun(cfg, userID, now)
+	first = lottery.RoundAt(next)
+	return lottery.SaleDeadline(next).AddDate(0, 0, 7*(n-1)), first, first + n - 1
+}
+
+// nextBuyRun은 계정(userID가 비어 있으면 아무 계정)의 다음 예약 구매 시각을 반환합니다 (구매 일정이 없으면 now)
+func nextBuyRun(cfg config.Config, userID string, now time.Time) time.Time {
+	var next time.Time
+	for _, entry := range cfg.ScheduleList() {
+		if entry.Job != config.JobBuy || !entry.IsEnabled() {
+			continue
+		}
+		if userID != "" && len(entry.Accounts) > 0 && !slices.Contains(entry.Accounts, userID) {
+			continue
+		}
+
+		spec, err := entry.Spec()
+		if err != nil {
+			continue
+		}
+		run, err := scheduler.NextRun(spec, now.In(lottery.Location()))
+		if err != nil || run.IsZero() {
+			continue
+		}
+		if next.IsZero() || run.Before(next) {
+			next = run
+		}
+	}
+
+	if next.IsZero() {
+		return now
+	}
+	return next
+}
+
+// FormatRounds는 회차 범위를 "1247회" 또는 "1247~1249회"로 포맷합니다
+func FormatRounds(first, last int) string {
+	if first == last {
+		return fmt.Sprintf("%d회", first)
+	}
+	return fmt.Sprintf("%d~%d회", first, last)
+}
+
+// checkAccount는 설정에 등록된 계정인지 확인합니다 (빈 값은 전체)
+func checkAccount(cfg config.Config, userID string) error {
+	if userID == "" {
+		return nil
+	}
+	for _, account := range cfg.Accounts {
+		if account.UserID == userID {
+			return nil
+		}
+	}
+	return fmt.Errorf("등록되지 않은 계정입니다: %s", userID)
+}
@@ -0,0 +1,171 @@
+package tasks
+
+import (
+	"dhlottery/config"
+	"dhlottery/lottery"
+	"dhlottery/policy"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// defaultGames는 구매 정책이 없을 때 구매하는 게임 수입니다
+const defaultGames = 5
+
+// decidePurchase는 계정의 구매 정책을 평가하여 이번 회차 구매 게임 수를 결정합니다
+func decidePurchase(account config.Account, gameInfo lottery.LottoGameInfo, balance int) (policy.Decision, policy.Inputs, error) {
+	rules, err := account.Policy.Compile()
+	if err != nil {
+		return policy.Decision{}, policy.Inputs{}, err
+	}
+
+	inputs, err := collectPolicyInputs(account, gameInfo, balance)
+	if err != nil {
+		return policy.Decision{}, inputs, err
+	}
+
+	decision := policy.Decide(rules, inputs, account.Policy.Default())
+	return decision, inputs, decision.Err()
+}
+
+// collectPolicyInputs는 이번 회차, 예치금, 직전 회차 결과와 최근 구매 금액을 모읍니다
+func collectPolicyInputs(account config.Account, gameInfo lottery.LottoGameInfo, balance int) (policy.Inputs, error) {
+	inputs := policy.Inputs{Balance: balance}
+
+	if round, err := strconv.Atoi(gameInfo.CurRound); err == nil {
+		inputs.Round = round
+	}
+
+	// 직전 회차 당첨 결과 (1등 당첨금, 이월 여부)
+	result, err := lottery.GetLatestResult()
+	if err != nil {
+		return inputs, fmt.Errorf("직전 회차 당첨 결과 조회 실패: %w", err)
+	}
+
+	inputs.LastJackpot = result.FirstPrize
+	inputs.LastJackpotTotal = result.FirstPrizeTotal
+	inputs.LastWinners = result.FirstWinners
+	inputs.Carryover = result.FirstWinners == 0
+
+	// 직전 회차 구매 및 당첨 여부
+	history, err := lottery.LoadPurchaseHistory(result.Round)
+	if err != nil {
+		return inputs, fmt.Errorf("직전 회차 구매 내역 조회 실패: %w", err)
+	}
+	if history != nil {
+		if purchase, ok := history.Users[account.UserID]; ok && purchase.Success {
+			inputs.LastPlayed = true
+			inputs.LastRank = lottery.BestRank(purchase, result)
+		}
+	}
+
+	// 최근 구매 금액
+	spend, err := lottery.RecentSpend(account.UserID, account.Policy.Recent())
+	if err != nil {
+		return inputs, fmt.Errorf("최근 구매 금액 계산 실패: %w", err)
+	}
+	inputs.RecentSpend = spend
+
+	return inputs, nil
+}
+
+// ExplainPolicy는 각 계정의 구매 정책을 현재 회차 정보로 평가하고 어떤 규칙이 적용되는지 보여줍니다 (구매 안 함)
+func ExplainPolicy(cfg config.Config) {
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	log.Println("          📐 구매 정책 설명")
+	log.Printf("          (총 %d개 계정)\n", len(cfg.Accounts))
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	for i, account := range cfg.Accounts {
+		log.Println()
+		log.Printf("┌─────────────────────────────────────┐")
+		log.Printf("│ 계정 %d/%d: %s", i+1, len(cfg.Accounts), account.UserID)
+		log.Printf("└─────────────────────────────────────┘")
+		log.Println()
+
+		explainPolicyForAccount(account)
+	}
+
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	log.Println()
+}
+
+// explainPolicyForAccount는 특정 계정의 정책 평가 과정을 출력합니다
+func explainPolicyForAccount(account config.Account) {
+	if account.Policy == nil {
+		log.Printf("ℹ️  구매 정책 없음 → 항상 %d게임 구매\n", defaultGames)
+		return
+	}
+
+	client, err := newClient(account)
+	if err != nil {
+		log.Printf("❌ 클라이언트 생성 실패: %v\n", err)
+		return
+	}
+
+	if err := client.Login(); err != nil {
+		log.Printf("❌ 로그인 실패: %v\n", err)
+		return
+	}
+
+	balance, err := client.CheckBalance()
+	if err != nil {
+		log.Printf("❌ 예치금 확인 실패: %v\n", err)
+		return
+	}
+
+	gameInfo, err := client.GetGameInfo()
+	if err != nil {
+		log.Printf("❌ 회차 정보 조회 실패: %v\n", err)
+		return
+	}
+
+	decision, inputs, err := decidePurchase(account, gameInfo, balance)
+	if err != nil && len(decision.Traces) == 0 {
+		log.Printf("❌ 구매 정책 평가 실패: %v\n", err)
+		return
+	}
+
+	log.Println()
+	log.Println("=== 입력값 ===")
+	env := inputs.Env()
+	for _, name := range policy.VariableNames() {
+		value := env[name]
+		if f, ok := value.(float64); ok && f >= 10000 {
+			value = lottery.FormatMoney(int(f))
+		}
+		log.Printf("   %-13s = %-16v  # %s\n", name, value, policy.VariableDescription(name))
+	}
+
+	log.Println()
+	log.Println("=== 규칙 평가 ===")
+	applied := -1
+	for i, trace := range decision.Traces {
+		if trace.Matched {
+			applied = i
+			break
+		}
+	}
+	for i, trace := range decision.Traces {
+		mark := "❌"
+		switch {
+		case trace.Err != nil:
+			mark = "⚠️ "
+		case trace.Matched:
+			mark = "✅"
+		}
+
+		note := ""
+		if i == applied {
+			note = "  ← 적용"
+		}
+
+		log.Printf("   %s %d. %s: %s → %d게임%s\n", mark, i+1, trace.Rule.Name, trace.Rule.When, trace.Rule.Games, note)
+		if trace.Err != nil {
+			log.Printf("        오류: %v\n", trace.Err)
+		}
+	}
+
+	log.Println()
+	log.Printf("📐 결과: %s\n", decision.Describe())
+}
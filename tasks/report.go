@@ -0,0 +1,200 @@
+package tasks
+
+import (
+	"dhlottery/config"
+	"dhlottery/lottery"
+	"dhlottery/report"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// PrintResult는 당첨번호를 출력하고 반환합니다 (round가 0이면 최근 회차)
+func PrintResult(round int) (report.Result, error) {
+	var result *lottery.LottoResult
+	var err error
+	if round > 0 {
+		result, err = lottery.GetResult(round)
+	} else {
+		result, err = lottery.GetLatestResult()
+	}
+	if err != nil {
+		return report.Result{}, fmt.Errorf("당첨번호 조회 실패: %w", err)
+	}
+
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	log.Printf("          🎱 로또 %s회 당첨번호\n", result.Round)
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	log.Printf("추첨일: %s\n", result.DrawDate)
+	log.Printf("당첨번호: %s\n", formatNumbers(result.Numbers))
+	log.Printf("보너스번호: %02d\n", result.BonusNumber)
+	if result.FirstWinners > 0 {
+		log.Printf("1등: %d명, 1인당 %s원\n", result.FirstWinners, lottery.FormatMoney(int(result.FirstPrize)))
+	} else {
+		log.Println("1등: 당첨자 없음 (이월)")
+	}
+
+	return report.Result{
+		Round:           result.Round,
+		DrawDate:        result.DrawDate,
+		Numbers:         result.Numbers,
+		Bonus:           result.BonusNumber,
+		FirstPrize:      result.FirstPrize,
+		FirstPrizeTotal: result.FirstPrizeTotal,
+		FirstWinners:    result.FirstWinners,
+	}, nil
+}
+
+// PrintHistory는 보관된 구매 내역을 최신 회차부터 출력하고 반환합니다 (limit개 회차, 설정된 계정만)
+func PrintHistory(cfg config.Config, limit int) ([]report.HistoryEntry, error) {
+	histories, err := lottery.ListPurchaseHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	log.Println("          📜 구매 내역")
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	entries := []report.HistoryEntry{}
+	shown := 0
+	for _, history := range histories {
+		if limit > 0 && shown >= limit {
+			break
+		}
+
+		var lines []string
+		for _, account := range cfg.Accounts {
+			purchase, ok := history.Users[account.UserID]
+			if !ok {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("   %s: %s", account.UserID, describePurchase(purchase)))
+			for _, game := range purchase.Games {
+				lines = append(lines, fmt.Sprintf("      [%s] %s", game.Type, formatNumbers(game.Numbers)))
+			}
+
+			entries = append(entries, report.HistoryEntry{
+				Round:    history.Round,
+				DrawDate: history.PurchaseDate,
+				Account:  account.UserID,
+				Status:   purchaseStatus(purchase),
+				Approval: purchase.Approval,
+				Games:    reportGames(purchase.Games),
+			})
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		log.Println()
+		log.Printf("▶ %s회 (추첨일 %s)\n", history.Round, history.PurchaseDate)
+		for _, line := range lines {
+			log.Println(line)
+		}
+		shown++
+	}
+
+	if shown == 0 {
+		log.Println("ℹ️  저장된 구매 내역이 없습니다")
+	}
+	return entries, nil
+}
+
+// describePurchase는 계정의 회차별 구매 상태를 설명합니다
+func describePurchase(purchase lottery.UserPurchase) string {
+	switch {
+	case lottery.IsApprovalSkip(purchase.Approval):
+		return "건너뜀 (승인 안 함)"
+	case purchase.Success:
+		return fmt.Sprintf("구매 %d게임 (%s원)", len(purchase.Games), lottery.FormatMoney(len(purchase.Games)*1000))
+	default:
+		return "구매 실패"
+	}
+}
+
+// purchaseStatus는 구매 내역의 상태를 출력용 값으로 바꿉니다
+func purchaseStatus(purchase lottery.UserPurchase) string {
+	switch {
+	case lottery.IsApprovalSkip(purchase.Approval):
+		return report.StatusSkipped
+	case purchase.Success:
+		return report.StatusPurchased
+	default:
+		return report.StatusFailed
+	}
+}
+
+// recordPurchase는 구매 결과를 record에 기록합니다
+func recordPurchase(record *report.Purchase, result map[string]interface{}) {
+	games, ok := lottery.PurchasedGames(result)
+	if !ok {
+		record.Status = report.StatusFailed
+		record.Reason = lottery.BuyFailureReason(result)
+		return
+	}
+	record.Status = report.StatusPurchased
+	record.Reason = ""
+	record.Games = reportGames(games)
+	record.Amount = len(games) * 1000
+}
+
+// winningRecord는 계정의 당첨 확인 결과를 만듭니다 (FormatWinningMessage와 같은 순서로 판단)
+func winningRecord(userID string, result *lottery.LottoResult, history *lottery.PurchaseHistory) report.Winning {
+	record := report.Winning{Account: userID, Round: result.Round, Games: []report.Game{}}
+
+	if history == nil {
+		record.Status = report.WinningNoHistory
+		return record
+	}
+	if history.Round != result.Round {
+		record.Status = report.WinningRoundMismatch
+		return record
+	}
+	purchase, ok := history.Users[userID]
+	switch {
+	case !ok:
+		record.Status = report.WinningNoPurchase
+		return record
+	case lottery.IsApprovalSkip(purchase.Approval):
+		record.Status = report.WinningSkipped
+		return record
+	case !purchase.Success || len(purchase.Games) == 0:
+		record.Status = report.WinningFailed
+		return record
+	}
+
+	record.Status = report.WinningChecked
+	for _, game := range purchase.Games {
+		rank, matches, bonus := lottery.CheckWinning(game.Numbers, result)
+		record.Games = append(record.Games, report.Game{
+			Type:    game.Type,
+			Numbers: game.Numbers,
+			Rank:    rank,
+			Matches: matches,
+			Bonus:   bonus,
+		})
+		if rank > 0 && (record.BestRank == 0 || rank < record.BestRank) {
+			record.BestRank = rank
+		}
+	}
+	return record
+}
+
+// reportGames는 구매 번호를 출력용 게임 목록으로 바꿉니다
+func reportGames(games []lottery.GamePurchase) []report.Game {
+	out := make([]report.Game, len(games))
+	for i, game := range games {
+		out[i] = report.Game{Type: game.Type, Numbers: game.Numbers}
+	}
+	return out
+}
+
+// formatNumbers는 번호를 두 자리로 나열합니다 (예: 03, 11, 25)
+func formatNumbers(numbers []int) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = fmt.Sprintf("%02d", n)
+	}
+	return strings.Join(parts, ", ")
+}
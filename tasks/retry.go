@@ -0,0 +1,399 @@
+package tasks
+
+import (
+	"dhlottery/config"
+	"dhlottery/datadir"
+	"dhlottery/lottery"
+	"dhlottery/notify"
+	"dhlottery/scheduler"
+	"dhlottery/telegram"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// buyFailure는 재시도가 필요한 계정별 구매 실패 정보입니다
+type buyFailure struct {
+	Account config.Account
+	Err     error
+}
+
+// 마감 임박 알림 단계
+const (
+	retryWarnWithin   = 24 * time.Hour
+	retryUrgentWithin = 3 * time.Hour
+)
+
+// retryStateFileName은 예약된 구매 재시도를 저장하는 파일 이름입니다 (상태 디렉토리)
+// 서비스를 재시작해도 재시도가 사라지지 않도록 ResumeBuyRetries로 다시 예약합니다
+const retryStateFileName = "buy_retry.json"
+
+// pendingRetry는 파일에 저장하는 예약된 재시도 하나입니다
+type pendingRetry struct {
+	Accounts []string  `json:"accounts"` // 다시 구매할 계정 ID
+	Attempt  int       `json:"attempt"`  // 지금까지 재시도한 횟수
+	Deadline time.Time `json:"deadline"`
+	Next     time.Time `json:"next"`
+}
+
+// buyRetry는 한 회차의 구매 재시도 상태입니다
+type buyRetry struct {
+	id       string // 상태 파일의 키 (처음 실패한 시각)
+	cfg      config.Config
+	hub      *notify.Hub
+	sched    *scheduler.Scheduler
+	deadline time.Time // 재시도 중단 시각 (판매 마감 - 여유 시간)
+	attempt  int
+}
+
+// CheckBalanceAndBuyWithRetry는 예치금 확인 후 구매하고, 실패한 계정은 판매 마감 전까지 재시도합니다
+// 구매에 실패한 계정이 있으면 (재시도 예약 여부와 관계없이) 에러를 반환합니다
+func CheckBalanceAndBuyWithRetry(cfg config.Config, hub *notify.Hub, sched *scheduler.Scheduler) error {
+	now := time.Now()
+	deadline := purchaseDeadline(cfg, now)
+
+	_, failures := checkBalanceAndBuyAccounts(cfg.Accounts, hub, deadline)
+	if len(failures) == 0 {
+		return nil
+	}
+
+	if !cfg.Retry.Enabled || sched == nil {
+		log.Printf("⚠️  %d개 계정 구매 실패 (재시도 비활성화)\n", len(failures))
+		return fmt.Errorf("%d개 계정 구매 실패: %s", len(failures), formatFailedAccountIDs(failures))
+	}
+
+	r := &buyRetry{
+		id:       now.Format(time.RFC3339Nano),
+		cfg:      cfg,
+		hub:      hub,
+		sched:    sched,
+		deadline: deadline,
+	}
+
+	r.scheduleNext(time.Now(), failures)
+	return fmt.Errorf("%d개 계정 구매 실패 (재시도 예약됨): %s", len(failures), formatFailedAccountIDs(failures))
+}
+
+// purchaseDeadline은 이번 회차 구매를 시도할 마지막 시각입니다 (판매 마감 - 재시도 여유 시간)
+func purchaseDeadline(cfg config.Config, now time.Time) time.Time {
+	return lottery.SaleDeadline(now).Add(-cfg.Retry.RetryDeadlineMargin())
+}
+
+// run은 실패한 계정들의 구매를 다시 시도합니다
+func (r *buyRetry) run(accounts []config.Account) error {
+	r.attempt++
+
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	log.Printf("      🔁 로또 구매 재시도 (%d회차 시도)\n", r.attempt)
+	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	// 재시도 대기 중에 일시정지된 계정은 제외
+	accounts, skipped := filterPaused(accounts, nil)
+	if len(skipped) > 0 {
+		notifyPaused(r.hub, "구매 재시도", skipped, true)
+	}
+	if len(accounts) == 0 {
+		r.clear()
+		return nil
+	}
+
+	// 재시도를 기다리는 동안 예약 구매나 /buy로 이미 구매한 계정은 제외
+	cfg := r.cfg
+	cfg.Accounts = accounts
+	remaining, ok := SkipPurchased(cfg)
+	if !ok {
+		r.clear()
+		log.Println("✅ 재시도할 계정이 모두 이미 구매했습니다")
+		return nil
+	}
+	accounts = remaining.Accounts
+
+	_, failures := checkBalanceAndBuyAccounts(accounts, r.hub, r.deadline)
+	if len(failures) == 0 {
+		r.clear()
+		log.Println("✅ 재시도 구매 완료")
+		r.hub.Broadcast(fmt.Sprintf("✅ <b>재시도 구매 성공</b>\n\n%d번째 재시도에서 모든 계정 구매를 완료했습니다.", r.attempt), notify.Options{})
+		return nil
+	}
+
+	r.scheduleNext(time.Now(), failures)
+	return fmt.Errorf("%d개 계정 재시도 구매 실패: %s", len(failures), formatFailedAccountIDs(failures))
+}
+
+// scheduleNext는 다음 재시도를 예약하거나, 마감이 지났으면 포기 알림을 보냅니다
+func (r *buyRetry) scheduleNext(now time.Time, failures []buyFailure) {
+	if !now.Before(r.deadline) {
+		r.giveUp(failures)
+		return
+	}
+
+	intervals := r.cfg.Retry.RetryIntervals()
+	interval := intervals[min(r.attempt, len(intervals)-1)]
+
+	// 마지막 시도는 마감 시각에 맞춰 실행
+	next := now.Add(interval)
+	if next.After(r.deadline) {
+		next = r.deadline
+	}
+
+	accounts := make([]config.Account, len(failures))
+	for i, f := range failures {
+		accounts[i] = f.Account
+	}
+
+	log.Printf("🔁 %d개 계정 재시도 예약: %s (마감까지 %s)\n",
+		len(accounts), next.In(r.sched.Location()).Format("01/02 15:04"), formatRemaining(r.deadline.Sub(now)))
+
+	r.notifyScheduled(now, next, failures)
+	r.save(accounts, next)
+	r.schedule(next, accounts)
+}
+
+// schedule은 next에 accounts의 재시도를 예약합니다 (구매 작업과 같은 묶음이라 겹쳐 실행되지 않음)
+func (r *buyRetry) schedule(next time.Time, accounts []config.Account) {
+	r.sched.AddOnce("buy-retry", config.JobBuy, next, func() error {
+		return r.run(accounts)
+	})
+}
+
+// ResumeBuyRetries는 상태 파일에 저장된 재시도를 다시 예약합니다 (서비스 재시작 시)
+// 마감이 지난 재시도는 재시도가 끝났을 때처럼 구매를 놓쳤다고 알리고 지우며, 설정에 없는 계정은 제외합니다
+func ResumeBuyRetries(cfg config.Config, hub *notify.Hub, sched *scheduler.Scheduler) {
+	pending, err := loadRetries()
+	if err != nil {
+		log.Printf("⚠️  %v\n", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for id, p := range pending {
+		r := &buyRetry{id: id, cfg: cfg, hub: hub, sched: sched, deadline: p.Deadline, attempt: p.Attempt}
+
+		if !now.Before(p.Deadline) {
+			log.Printf("🗑  서비스가 꺼져 있는 동안 재시도 마감이 지났습니다: %s\n", strings.Join(p.Accounts, ", "))
+			r.missed(p.Accounts)
+			continue
+		}
+		if !cfg.Retry.Enabled {
+			log.Printf("🗑  재시도가 꺼져 있어 예약된 재시도를 지웁니다: %s\n", strings.Join(p.Accounts, ", "))
+			r.clear()
+			continue
+		}
+
+		var accounts []config.Account
+		if len(p.Accounts) > 0 {
+			accounts = cfg.WithAccounts(p.Accounts).Accounts
+		}
+		if len(accounts) < len(p.Accounts) {
+			log.Printf("⚠️  설정에 없는 계정은 재시도에서 제외합니다 (%d개 중 %d개 예약)\n", len(p.Accounts), len(accounts))
+		}
+		if len(accounts) == 0 {
+			r.clear()
+			continue
+		}
+
+		next := p.Next
+		if next.Before(now) {
+			next = now
+		}
+		log.Printf("🔁 저장된 재시도 다시 예약: %d개 계정, %s (마감까지 %s)\n",
+			len(accounts), next.In(sched.Location()).Format("01/02 15:04"), formatRemaining(p.Deadline.Sub(now)))
+		r.schedule(next, accounts)
+	}
+}
+
+// notifyScheduled는 마감까지 남은 시간에 따라 단계별로 재시도 예약 알림을 보냅니다
+func (r *buyRetry) notifyScheduled(now, next time.Time, failures []buyFailure) {
+	if r.hub == nil {
+		return
+	}
+
+	remaining := r.deadline.Sub(now)
+
+	var header string
+	switch {
+	case remaining <= retryUrgentWithin:
+		header = "🚨 <b>구매 마감 임박 - 긴급</b>"
+	case remaining <= retryWarnWithin:
+		header = "⚠️ <b>구매 마감 하루 전</b>"
+	default:
+		// 여유가 있을 때는 첫 실패만 알림
+		if r.attempt > 0 {
+			return
+		}
+		header = "🔁 <b>로또 구매 재시도 예약</b>"
+	}
+
+	msg := header + "\n\n"
+	msg += formatFailedAccounts(failures)
+	msg += fmt.Sprintf("\n⏰ 다음 시도: %s\n", next.In(r.sched.Location()).Format("01/02 15:04"))
+	msg += fmt.Sprintf("⌛ 재시도 마감까지: %s", formatRemaining(remaining))
+
+	r.hub.Broadcast(msg, notify.Options{})
+}
+
+// giveUp은 재시도를 중단하고 이번 회차 구매를 놓쳤음을 알립니다
+func (r *buyRetry) giveUp(failures []buyFailure) {
+	log.Printf("❌ 재시도 마감 도달: %d개 계정 이번 회차 구매 실패\n", len(failures))
+	r.clear()
+
+	if r.hub == nil {
+		return
+	}
+
+	msg := "❌ <b>이번 회차 구매를 놓쳤습니다</b>\n\n"
+	msg += formatFailedAccounts(failures)
+	msg += fmt.Sprintf("\n총 %d회 재시도했지만 판매 마감 전까지 구매하지 못했습니다.", r.attempt)
+
+	r.hub.Broadcast(msg, notify.Options{})
+}
+
+// missed는 서비스가 꺼져 있는 동안 마감이 지난 재시도를 포기합니다
+// 그 사이 직접 구매한 계정은 빼고, 설정에서 지운 계정도 아이디로 알립니다
+func (r *buyRetry) missed(userIDs []string) {
+	cfg := r.cfg
+	cfg.Accounts = nil
+	for _, id := range userIDs {
+		account := config.Account{UserID: id}
+		if found := r.cfg.WithAccounts([]string{id}).Accounts; len(found) == 1 {
+			account = found[0]
+		}
+		cfg.Accounts = append(cfg.Accounts, account)
+	}
+
+	remaining, ok := skipPurchasedIn(cfg, lottery.RoundAt(r.deadline))
+	if !ok {
+		r.clear()
+		return
+	}
+
+	failures := make([]buyFailure, len(remaining.Accounts))
+	for i, account := range remaining.Accounts {
+		failures[i] = buyFailure{Account: account, Err: fmt.Errorf("서비스가 꺼져 있는 동안 재시도 마감(%s)이 지났습니다", r.deadline.Format("01/02 15:04"))}
+	}
+	r.giveUp(failures)
+}
+
+// save는 예약한 재시도를 상태 파일에 기록합니다
+func (r *buyRetry) save(accounts []config.Account, next time.Time) {
+	p := pendingRetry{Attempt: r.attempt, Deadline: r.deadline, Next: next}
+	for _, account := range accounts {
+		p.Accounts = append(p.Accounts, account.UserID)
+	}
+	r.update(func(pending map[string]pendingRetry) { pending[r.id] = p })
+}
+
+// clear는 끝난 재시도를 상태 파일에서 지웁니다
+func (r *buyRetry) clear() {
+	r.update(func(pending map[string]pendingRetry) { delete(pending, r.id) })
+}
+
+// retryStateMu는 여러 재시도가 상태 파일을 동시에 고치지 않도록 막습니다
+var retryStateMu sync.Mutex
+
+// update는 상태 파일을 읽어 change를 적용한 뒤 다시 저장합니다 (실패는 로그만 남김)
+func (r *buyRetry) update(change func(map[string]pendingRetry)) {
+	retryStateMu.Lock()
+	defer retryStateMu.Unlock()
+
+	pending, err := loadRetries()
+	if err != nil {
+		log.Printf("⚠️  %v\n", err)
+		return
+	}
+	change(pending)
+	if err := saveRetries(pending); err != nil {
+		log.Printf("⚠️  %v\n", err)
+	}
+}
+
+// loadRetries는 저장된 재시도를 읽습니다 (파일이 없으면 빈 맵)
+func loadRetries() (map[string]pendingRetry, error) {
+	pending := map[string]pendingRetry{}
+	data, err := os.ReadFile(datadir.Path(datadir.State, retryStateFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pending, nil
+		}
+		return nil, fmt.Errorf("재시도 상태 파일 읽기 실패: %w", err)
+	}
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("재시도 상태 파일 파싱 실패: %w", err)
+	}
+	return pending, nil
+}
+
+// saveRetries는 재시도를 저장합니다 (남은 재시도가 없으면 파일 삭제)
+func saveRetries(pending map[string]pendingRetry) error {
+	path := datadir.Path(datadir.State, retryStateFileName)
+	if len(pending) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("재시도 상태 파일 삭제 실패: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(datadir.Path(datadir.State), 0700); err != nil {
+		return fmt.Errorf("상태 디렉토리 생성 실패: %w", err)
+	}
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON 마샬링 실패: %w", err)
+	}
+
+	// 임시 파일에 쓴 뒤 이름을 바꿔 중간에 끊겨도 기존 파일 유지
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("재시도 상태 파일 저장 실패: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("재시도 상태 파일 저장 실패: %w", err)
+	}
+	return nil
+}
+
+// formatFailedAccounts는 실패한 계정과 사유를 목록으로 포맷합니다
+func formatFailedAccounts(failures []buyFailure) string {
+	var sb strings.Builder
+	for _, f := range failures {
+		sb.WriteString(fmt.Sprintf("• %s: %s\n", telegram.Escape(f.Account.DisplayName()), telegram.Escape(f.Err)))
+	}
+	return sb.String()
+}
+
+// formatFailedAccountIDs는 실패한 계정 ID를 쉼표로 이어 붙입니다
+func formatFailedAccountIDs(failures []buyFailure) string {
+	ids := make([]string, len(failures))
+	for i, f := range failures {
+		ids[i] = f.Account.UserID
+	}
+	return strings.Join(ids, ", ")
+}
+
+// formatRemaining은 남은 시간을 "N일 N시간 N분" 형식으로 포맷합니다
+func formatRemaining(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%d일 %d시간", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%d시간 %d분", hours, minutes)
+	default:
+		return fmt.Sprintf("%d분", minutes)
+	}
+}
@@ -3,18 +3,28 @@ package tasks
 import (
 	"dhlottery/config"
 	"dhlottery/lottery"
+	"dhlottery/message"
+	"dhlottery/notify"
+	"dhlottery/report"
 	"dhlottery/telegram"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// CheckBalance는 예치금 확인 작업을 수행합니다 (모든 계정)
-func CheckBalance(cfg config.Config, bot *telegram.Bot) {
+// CheckBalance는 예치금 확인 작업을 수행하고 계정별 결과를 반환합니다 (모든 계정)
+// 확인에 실패한 계정이 있으면 에러를 반환합니다
+func CheckBalance(cfg config.Config, hub *notify.Hub) ([]report.Balance, error) {
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println("          💰 예치금 확인 작업")
 	log.Printf("          (총 %d개 계정)\n", len(cfg.Accounts))
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
+	var failed []string
+	records := make([]report.Balance, 0, len(cfg.Accounts))
+	rows := make([]message.DigestRow, 0, len(cfg.Accounts))
 	for i, account := range cfg.Accounts {
 		log.Println()
 		log.Printf("┌─────────────────────────────────────┐")
@@ -22,72 +32,81 @@ func CheckBalance(cfg config.Config, bot *telegram.Bot) {
 		log.Printf("└─────────────────────────────────────┘")
 		log.Println()
 
-		checkBalanceForAccount(account, bot)
+		record := report.Balance{Account: account.UserID}
+		balance, err := checkBalanceForAccount(account, hub)
+		if err != nil {
+			failed = append(failed, account.UserID)
+			record.Error = err.Error()
+		} else {
+			record.OK = true
+			record.Balance = balance
+			record.Sufficient = balance >= lowBalance
+		}
+		records = append(records, record)
+		rows = append(rows, balanceDigestRow(account, record))
 	}
 
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println()
+
+	sendDigest(cfg.Accounts, hub, message.DigestBalance, rows)
+
+	if len(failed) > 0 {
+		return records, fmt.Errorf("%d개 계정 예치금 확인 실패: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return records, nil
 }
 
 // checkBalanceForAccount는 특정 계정의 예치금을 확인합니다
-func checkBalanceForAccount(account config.Account, bot *telegram.Bot) {
+func checkBalanceForAccount(account config.Account, hub *notify.Hub) (int, error) {
+	alert := accountNotifier(account, hub)
+
 	// 클라이언트 생성
-	client, err := lottery.NewClient(account.UserID, account.Password)
+	client, err := newClient(account)
 	if err != nil {
 		log.Printf("❌ 클라이언트 생성 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("(%s) ❌ <b>예치금 확인 실패</b>\n\n클라이언트 생성 오류: %v", account.UserID, err))
-		}
-		return
+		alert.failure(message.FailureBalance, message.StageClient, err)
+		return 0, fmt.Errorf("클라이언트 생성 실패: %w", err)
 	}
 
 	// 로그인
 	if err := client.Login(); err != nil {
 		log.Printf("❌ 로그인 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("(%s) ❌ <b>동행복권 로그인 실패</b>\n\n%v", account.UserID, err))
-		}
-		return
+		alert.failure(message.FailureLogin, "", err)
+		return 0, fmt.Errorf("로그인 실패: %w", err)
 	}
 
 	// 예치금 확인
 	balance, err := client.CheckBalance()
 	if err != nil {
 		log.Printf("❌ 예치금 확인 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("(%s) ❌ <b>예치금 확인 실패</b>\n\n%v", account.UserID, err))
-		}
-		return
+		alert.failure(message.FailureBalance, "", err)
+		return 0, fmt.Errorf("예치금 확인 실패: %w", err)
 	}
+	saveBalance(account.UserID, balance)
 
 	// 예치금이 10,000원 미만인 경우 알림
-	if balance < 10000 {
+	if balance < lowBalance {
 		log.Printf("⚠️  예치금 부족: %s원 (10,000원 미만)\n", lottery.FormatMoney(balance))
 
-		if bot != nil {
-			message := fmt.Sprintf(
-				"(%s) ⚠️ <b>예치금 부족 알림</b>\n\n"+
-					"현재 예치금: <b>%s원</b>\n"+
-					"기준 금액: 10,000원\n\n"+
-					"💡 예치금을 충전해주세요!",
-				account.UserID,
-				lottery.FormatMoney(balance),
-			)
-			bot.SendMessageSafe(message)
-		}
+		alert.balance(message.Balance{Kind: message.BalanceLow, Balance: balance, Threshold: lowBalance})
 	} else {
 		log.Printf("✅ 예치금 충분: %s원\n", lottery.FormatMoney(balance))
 		// 10,000원 이상이면 텔레그램 알림 보내지 않음
 	}
+
+	return balance, nil
 }
 
-// BuyLotto는 로또 구매 작업을 수행합니다 (모든 계정)
-func BuyLotto(cfg config.Config, bot *telegram.Bot) {
+// BuyLotto는 로또 구매 작업을 수행하고 계정별 구매 결과를 반환합니다 (모든 계정)
+func BuyLotto(cfg config.Config, hub *notify.Hub) []report.Purchase {
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println("          🎱 로또 구매 작업")
 	log.Printf("          (총 %d개 계정)\n", len(cfg.Accounts))
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
+	records := make([]report.Purchase, 0, len(cfg.Accounts))
+	rows := make([]message.DigestRow, 0, len(cfg.Accounts))
 	for i, account := range cfg.Accounts {
 		log.Println()
 		log.Printf("┌─────────────────────────────────────┐")
@@ -95,22 +114,33 @@ func BuyLotto(cfg config.Config, bot *telegram.Bot) {
 		log.Printf("└─────────────────────────────────────┘")
 		log.Println()
 
-		buyLottoForAccount(account, bot)
+		record := report.Purchase{Account: account.UserID}
+		buyLottoForAccount(account, hub, &record)
+		records = append(records, record)
+		rows = append(rows, purchaseDigestRow(account, record))
 	}
 
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println()
+
+	sendDigest(cfg.Accounts, hub, message.DigestPurchase, rows)
+
+	return records
 }
 
-// buyLottoForAccount는 특정 계정으로 로또를 구매합니다
-func buyLottoForAccount(account config.Account, bot *telegram.Bot) {
+// buyLottoForAccount는 특정 계정으로 로또를 구매하고 결과를 record에 기록합니다
+func buyLottoForAccount(account config.Account, hub *notify.Hub, record *report.Purchase) {
+	record.Status = report.StatusFailed
+
+	alert := accountNotifier(account, hub)
+	alert.buying = true // 판매 마감 임박 실패는 critical
+
 	// 클라이언트 생성
-	client, err := lottery.NewClient(account.UserID, account.Password)
+	client, err := newClient(account)
 	if err != nil {
 		log.Printf("❌ 클라이언트 생성 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("(%s) ❌ <b>로또 구매 실패</b>\n\n클라이언트 생성 오류: %v", account.UserID, err))
-		}
+		alert.failure(message.FailureBuy, message.StageClient, err)
+		record.Reason = err.Error()
 		return
 	}
 
@@ -118,9 +148,8 @@ func buyLottoForAccount(account config.Account, bot *telegram.Bot) {
 	log.Println("=== 로그인 시작 ===")
 	if err := client.Login(); err != nil {
 		log.Printf("❌ 로그인 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("(%s) ❌ <b>로또 구매 실패</b>\n\n로그인 오류: %v", account.UserID, err))
-		}
+		alert.failure(message.FailureBuy, message.StageLogin, err)
+		record.Reason = err.Error()
 		return
 	}
 
@@ -129,9 +158,8 @@ func buyLottoForAccount(account config.Account, bot *telegram.Bot) {
 	log.Println("=== 로또 6/45 구매 페이지 접근 ===")
 	if err := client.NavigateToLottoBuyPage(); err != nil {
 		log.Printf("❌ 구매 페이지 접근 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("(%s) ❌ <b>로또 구매 실패</b>\n\n페이지 접근 오류: %v", account.UserID, err))
-		}
+		alert.failure(message.FailureBuy, message.StagePage, err)
+		record.Reason = err.Error()
 		return
 	}
 
@@ -141,52 +169,148 @@ func buyLottoForAccount(account config.Account, bot *telegram.Bot) {
 	result, resultMsg, err := client.BuyLottoAutoWithResult(account.UserID, 5)
 	if err != nil {
 		log.Printf("❌ 구매 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("(%s) ❌ <b>로또 구매 실패</b>\n\n%v", account.UserID, err))
-		}
+		alert.failure(message.FailureBuy, "", err)
+		record.Reason = err.Error()
 		return
 	}
 
 	// 구매 결과 출력
 	client.PrintBuyResult(result)
+	recordPurchase(record, result)
 
 	// 텔레그램 알림 전송
-	if bot != nil {
-		bot.SendMessageSafe(resultMsg)
+	alert.purchase(result, resultMsg, "")
+}
+
+// CheckBalanceAndBuy는 예치금 확인 후 로또 구매 작업을 수행하고 계정별 구매 결과를 반환합니다 (모든 계정)
+func CheckBalanceAndBuy(cfg config.Config, hub *notify.Hub) []report.Purchase {
+	records, _ := checkBalanceAndBuyAccounts(cfg.Accounts, hub, purchaseDeadline(cfg, time.Now()))
+	return records
+}
+
+// SkipPurchased는 이번 회차에 이미 구매한 계정을 제외한 설정을 반환합니다 (구매할 계정이 없으면 false)
+// 직접 실행한 buy나 봇의 /buy로 먼저 구매했으면, 정기 구매나 재시작 후 놓친 구매를 따라잡을 때 다시 사지 않습니다
+// 구매 내역은 회차당 계정별 한 번의 구매만 기록하므로 같은 회차에 두 번 사면 앞의 번호를 당첨 확인할 수 없습니다
+func SkipPurchased(cfg config.Config) (config.Config, bool) {
+	return skipPurchasedIn(cfg, lottery.RoundAt(time.Now()))
+}
+
+// skipPurchasedIn은 round 회차를 이미 구매한 계정을 뺀 설정을 반환합니다 (남은 계정이 없으면 false)
+func skipPurchasedIn(cfg config.Config, roundNo int) (config.Config, bool) {
+	round := strconv.Itoa(roundNo)
+	history, err := lottery.LoadPurchaseHistory(round)
+	if err != nil {
+		// 내역을 읽을 수 없으면 그대로 구매 진행
+		log.Printf("⚠️  %v\n", err)
+		return cfg, true
+	}
+	if history == nil {
+		return cfg, true
+	}
+
+	active := make([]config.Account, 0, len(cfg.Accounts))
+	for _, account := range cfg.Accounts {
+		if history.Users[account.UserID].Success {
+			log.Printf("⏭ %s: %s회차를 이미 구매해 건너뜁니다\n", account.UserID, round)
+			continue
+		}
+		active = append(active, account)
 	}
+
+	cfg.Accounts = active
+	return cfg, len(active) > 0
 }
 
-// CheckBalanceAndBuy는 예치금 확인 후 로또 구매 작업을 수행합니다 (모든 계정)
-func CheckBalanceAndBuy(cfg config.Config, bot *telegram.Bot) {
+// checkBalanceAndBuyAccounts는 주어진 계정들로 예치금 확인 후 구매하고, 계정별 결과와 재시도가 필요한 계정을 반환합니다
+func checkBalanceAndBuyAccounts(accounts []config.Account, hub *notify.Hub, deadline time.Time) ([]report.Purchase, []buyFailure) {
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println("      💰 예치금 확인 및 로또 구매 작업")
-	log.Printf("          (총 %d개 계정)\n", len(cfg.Accounts))
+	log.Printf("          (총 %d개 계정)\n", len(accounts))
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
-	for i, account := range cfg.Accounts {
+	records := make([]report.Purchase, len(accounts))
+	errs := make([]error, len(accounts))
+	pending := make([]*accountPurchase, len(accounts)) // 승인을 기다리는 계정
+
+	// 승인이 필요 없는 계정은 바로 구매하고, 승인 모드 계정은 승인 요청까지만 보냄
+	var approvals []*approvalRequest
+	for i, account := range accounts {
 		log.Println()
 		log.Printf("┌─────────────────────────────────────┐")
-		log.Printf("│ 계정 %d/%d: %s", i+1, len(cfg.Accounts), account.UserID)
+		log.Printf("│ 계정 %d/%d: %s", i+1, len(accounts), account.UserID)
 		log.Printf("└─────────────────────────────────────┘")
 		log.Println()
 
-		checkBalanceAndBuyForAccount(account, bot)
+		records[i] = report.Purchase{Account: account.UserID}
+		purchase, err := prepareBuyForAccount(account, hub, &records[i], deadline)
+		switch {
+		case purchase == nil:
+			errs[i] = err
+		case purchase.approval == nil:
+			errs[i] = purchase.buy()
+		default:
+			pending[i] = purchase
+			approvals = append(approvals, purchase.approval)
+		}
+	}
+
+	// 승인 요청을 모두 보낸 뒤 한꺼번에 기다리고, 결정에 따라 구매
+	waitApprovals(approvals)
+	for i, purchase := range pending {
+		if purchase == nil {
+			continue
+		}
+		log.Println()
+		log.Printf("┌─────────────────────────────────────┐")
+		log.Printf("│ 계정 %d/%d: %s (승인 후 구매)", i+1, len(accounts), accounts[i].UserID)
+		log.Printf("└─────────────────────────────────────┘")
+		errs[i] = purchase.buy()
+	}
+
+	var failures []buyFailure
+	rows := make([]message.DigestRow, 0, len(accounts))
+	for i, account := range accounts {
+		if errs[i] != nil {
+			failures = append(failures, buyFailure{Account: account, Err: errs[i]})
+			records[i].Status = report.StatusFailed
+			records[i].Reason = errs[i].Error()
+		}
+		rows = append(rows, purchaseDigestRow(account, records[i]))
 	}
 
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println()
+
+	sendDigest(accounts, hub, message.DigestPurchase, rows)
+
+	return records, failures
+}
+
+// accountPurchase는 구매 준비를 마친 계정입니다 (승인 모드는 승인 응답을 기다린 뒤 buy)
+type accountPurchase struct {
+	account  config.Account
+	alert    notifier
+	client   *lottery.Client
+	record   *report.Purchase
+	gameInfo lottery.LottoGameInfo
+	balance  int
+	quantity int
+	approval *approvalRequest // 승인 모드가 아니면 nil
 }
 
-// checkBalanceAndBuyForAccount는 특정 계정으로 예치금 확인 후 구매합니다
-func checkBalanceAndBuyForAccount(account config.Account, bot *telegram.Bot) {
+// prepareBuyForAccount는 특정 계정으로 로그인해 예치금을 확인하고 구매할 게임 수를 정합니다
+// 승인 모드면 승인 요청을 보내고 응답은 기다리지 않습니다 (deadline까지만 대기하도록 요청에 기록)
+// 구매하지 않고 끝난 경우(정책상 건너뜀, 실패) nil을 반환하며, 재시도로 해결될 수 있는 실패면 에러도 함께 반환합니다
+func prepareBuyForAccount(account config.Account, hub *notify.Hub, record *report.Purchase, deadline time.Time) (*accountPurchase, error) {
+	alert := accountNotifier(account, hub)
+	alert.buying = true // 판매 마감 임박 실패는 critical
+
 	// 클라이언트 생성
-	client, err := lottery.NewClient(account.UserID, account.Password)
+	client, err := newClient(account)
 	if err != nil {
 		log.Printf("❌ 클라이언트 생성 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("(%s) ❌ <b>작업 실패</b>\n\n클라이언트 생성 오류: %v", account.UserID, err))
-		}
-		return
+		alert.failure(message.FailureJob, message.StageClient, err)
+		return nil, fmt.Errorf("클라이언트 생성 실패: %w", err)
 	}
 
 	// 1단계: 로그인
@@ -194,10 +318,8 @@ func checkBalanceAndBuyForAccount(account config.Account, bot *telegram.Bot) {
 	log.Println("=== 1단계: 로그인 ===")
 	if err := client.Login(); err != nil {
 		log.Printf("❌ 로그인 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("(%s) ❌ <b>로그인 실패</b>\n\n%v", account.UserID, err))
-		}
-		return
+		alert.failure(message.FailureLogin, "", err)
+		return nil, fmt.Errorf("로그인 실패: %w", err)
 	}
 
 	// 2단계: 예치금 확인
@@ -206,41 +328,109 @@ func checkBalanceAndBuyForAccount(account config.Account, bot *telegram.Bot) {
 	balance, err := client.CheckBalance()
 	if err != nil {
 		log.Printf("❌ 예치금 확인 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("(%s) ❌ <b>예치금 확인 실패</b>\n\n%v", account.UserID, err))
+		alert.failure(message.FailureBalance, "", err)
+		return nil, fmt.Errorf("예치금 확인 실패: %w", err)
+	}
+	record.Balance = &balance
+	saveBalance(account.UserID, balance)
+
+	// 회차 정보 (구매 정책 또는 승인 모드 사용 시)
+	quantity := defaultGames
+	var gameInfo lottery.LottoGameInfo
+	if account.Policy != nil || account.Approval.Enabled {
+		gameInfo, err = client.GetGameInfo()
+		if err != nil {
+			log.Printf("❌ 회차 정보 조회 실패: %v\n", err)
+			alert.failure(message.FailureRound, "", err)
+			return nil, fmt.Errorf("회차 정보 조회 실패: %w", err)
 		}
-		return
+		record.Round = gameInfo.CurRound
 	}
 
-	// 예치금 부족 체크
-	if balance < 5000 {
-		log.Printf("⚠️  예치금 부족: %s원 (최소 5,000원 필요)\n", lottery.FormatMoney(balance))
-		if bot != nil {
-			message := fmt.Sprintf(
-				"(%s) ⚠️ <b>예치금 부족 알림</b>\n\n"+
-					"현재 예치금: <b>%s원</b>\n"+
-					"필요 금액: 5,000원\n\n"+
-					"💡 예치금을 충전해주세요!",
-				account.UserID,
-				lottery.FormatMoney(balance),
-			)
-			bot.SendMessageSafe(message)
+	// 구매 정책 평가
+	if account.Policy != nil {
+		log.Println()
+		log.Println("=== 구매 정책 평가 ===")
+		decision, _, err := decidePurchase(account, gameInfo, balance)
+		if err != nil {
+			log.Printf("❌ 구매 정책 평가 실패: %v\n", err)
+			alert.failure(message.FailurePolicy, "", err)
+			return nil, fmt.Errorf("구매 정책 평가 실패: %w", err)
 		}
-		return
+
+		log.Printf("📐 %s\n", decision.Describe())
+		quantity = decision.Games
+
+		if quantity == 0 {
+			log.Println("⏭ 정책에 따라 이번 회차는 구매하지 않습니다")
+			alert.send(compose(message.EventPolicySkip, message.SeverityInfo, message.PolicySkip{Account: alert.name, Reason: decision.Describe()}), notify.Options{Silent: true})
+			record.Status = report.StatusSkipped
+			record.Reason = decision.Describe()
+			return nil, nil
+		}
+	}
+
+	// 예치금 부족 체크
+	required := quantity * 1000
+	if balance < required {
+		log.Printf("⚠️  예치금 부족: %s원 (최소 %s원 필요)\n", lottery.FormatMoney(balance), lottery.FormatMoney(required))
+		alert.balance(message.Balance{Kind: message.BalanceInsufficient, Balance: balance, Required: required})
+		// 충전 후 재시도하면 구매할 수 있으므로 재시도 대상
+		return nil, fmt.Errorf("예치금 부족: %s원", lottery.FormatMoney(balance))
 	}
 
 	log.Printf("✅ 예치금 충분: %s원\n", lottery.FormatMoney(balance))
 
 	// 예치금 알림 (텔레그램)
-	if bot != nil && balance < 10000 {
-		message := fmt.Sprintf(
-			"(%s) ⚠️ <b>예치금 알림</b>\n\n"+
-				"현재 예치금: <b>%s원</b>\n\n"+
-				"💡 예치금이 10,000원 미만입니다.",
-			account.UserID,
-			lottery.FormatMoney(balance),
-		)
-		bot.SendMessageSafe(message)
+	if balance < lowBalance {
+		alert.balance(message.Balance{Kind: message.BalanceNotice, Balance: balance, Threshold: lowBalance})
+	}
+
+	purchase := &accountPurchase{
+		account:  account,
+		alert:    alert,
+		client:   client,
+		record:   record,
+		gameInfo: gameInfo,
+		balance:  balance,
+		quantity: quantity,
+	}
+
+	// 구매 승인 요청 (승인 모드 사용 시)
+	if account.Approval.Enabled {
+		log.Println()
+		log.Println("=== 구매 승인 요청 ===")
+		purchase.approval = sendApproval(account, accountBot(account, hub), gameInfo, balance, quantity, deadline)
+	}
+
+	return purchase, nil
+}
+
+// buy는 승인 결정을 반영해 구매하고 결과를 record에 기록합니다
+// 재시도로 해결될 수 있는 실패인 경우 에러를 반환합니다
+func (p *accountPurchase) buy() error {
+	account, alert, client, record := p.account, p.alert, p.client, p.record
+
+	// 구매 승인 결정 반영 (승인 모드 사용 시)
+	if p.approval != nil {
+		decision := p.approval.decision
+		if err := lottery.RecordApproval(account.UserID, p.gameInfo.CurRound, p.gameInfo.RoundDrawDate, decision); err != nil {
+			log.Printf("⚠️  승인 결정 기록 실패: %v\n", err)
+		}
+
+		if lottery.IsApprovalSkip(decision) {
+			log.Println("⏭ 이번 회차 구매를 건너뜁니다")
+			record.Status = report.StatusSkipped
+			record.Reason = "승인 안 함 (" + decision + ")"
+			return nil
+		}
+
+		// 승인 대기 중 세션이 만료될 수 있으므로 다시 로그인
+		if err := client.Login(); err != nil {
+			log.Printf("❌ 재로그인 실패: %v\n", err)
+			alert.failure(message.FailureLogin, "", err)
+			return fmt.Errorf("로그인 실패: %w", err)
+		}
 	}
 
 	// 3단계: 구매 페이지 접근
@@ -248,35 +438,44 @@ func checkBalanceAndBuyForAccount(account config.Account, bot *telegram.Bot) {
 	log.Println("=== 3단계: 로또 6/45 구매 페이지 접근 ===")
 	if err := client.NavigateToLottoBuyPage(); err != nil {
 		log.Printf("❌ 구매 페이지 접근 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("(%s) ❌ <b>로또 구매 실패</b>\n\n페이지 접근 오류: %v", account.UserID, err))
-		}
-		return
+		alert.failure(message.FailureBuy, message.StagePage, err)
+		return fmt.Errorf("구매 페이지 접근 실패: %w", err)
 	}
 
-	// 4단계: 로또 구매 (5게임)
+	// 4단계: 로또 구매
 	log.Println()
-	log.Println("=== 4단계: 로또 자동 구매 (5게임) ===")
-	result, resultMsg, err := client.BuyLottoAutoWithResult(account.UserID, 5)
+	log.Printf("=== 4단계: 로또 자동 구매 (%d게임) ===\n", p.quantity)
+	result, resultMsg, err := client.BuyLottoAutoWithResult(account.UserID, p.quantity)
 	if err != nil {
 		log.Printf("❌ 구매 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("(%s) ❌ <b>로또 구매 실패</b>\n\n%v", account.UserID, err))
-		}
-		return
+		alert.failure(message.FailureBuy, "", err)
+		return err
 	}
 
 	// 구매 결과 출력
 	client.PrintBuyResult(result)
+	recordPurchase(record, result)
+	if record.Status == report.StatusPurchased {
+		saveBalance(account.UserID, p.balance-record.Amount)
+	}
 
 	// 텔레그램 알림 전송
-	if bot != nil {
-		bot.SendMessageSafe(resultMsg)
+	alert.purchase(result, resultMsg, "")
+
+	if !lottery.IsBuySuccess(result) {
+		// 이미 한도까지 구매한 경우 재시도하지 않음
+		if lottery.IsPurchaseLimitReached(result) {
+			record.Status = report.StatusSkipped
+			return nil
+		}
+		return fmt.Errorf("구매 실패: %s", lottery.BuyFailureReason(result))
 	}
+
+	return nil
 }
 
 // DryRun은 구매하지 않고 테스트만 수행합니다 (모든 계정)
-func DryRun(cfg config.Config, bot *telegram.Bot) {
+func DryRun(cfg config.Config, hub *notify.Hub) {
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println("    🔍 테스트 모드 (실제 구매 안 함)")
 	log.Printf("          (총 %d개 계정)\n", len(cfg.Accounts))
@@ -289,17 +488,20 @@ func DryRun(cfg config.Config, bot *telegram.Bot) {
 		log.Printf("└─────────────────────────────────────┘")
 		log.Println()
 
-		dryRunForAccount(account)
+		dryRunForAccount(account, hub)
 	}
 
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println()
 }
 
-// dryRunForAccount는 특정 계정으로 테스트를 수행합니다
-func dryRunForAccount(account config.Account) {
+// dryRunForAccount는 특정 계정으로 실제 구매 요청 직전까지 전체 과정을 테스트합니다
+func dryRunForAccount(account config.Account, hub *notify.Hub) {
+	alert := accountNotifier(account, hub)
+	alert.digest = false // 테스트 결과는 요약하지 않고 바로 보냄
+
 	// 클라이언트 생성
-	client, err := lottery.NewClient(account.UserID, account.Password)
+	client, err := newClient(account)
 	if err != nil {
 		log.Printf("❌ 클라이언트 생성 실패: %v\n", err)
 		return
@@ -332,47 +534,81 @@ func dryRunForAccount(account config.Account) {
 		return
 	}
 
+	// 구매 요청 직전까지 진행 (5게임)
+	log.Println()
+	log.Println("=== 4단계: 로또 자동 구매 시뮬레이션 (5게임) ===")
+	result, resultMsg, err := client.DryRunBuy(account.UserID, 5)
+	if err != nil {
+		log.Printf("❌ 구매 시뮬레이션 실패: %v\n", err)
+		return
+	}
+
+	// 가상 구매 결과 출력
+	client.PrintBuyResult(result)
+
+	log.Println("=== 텔레그램 메시지 미리보기 ===")
+	log.Println(resultMsg)
+
+	// 텔레그램 알림 전송 ([DRY RUN] 표시)
+	alert.purchase(result, resultMsg, lottery.DryRunPrefix)
+
 	log.Println()
 	log.Println("✅ 테스트 완료! (실제 구매는 하지 않았습니다)")
 }
 
-// CheckWinning은 당첨번호를 확인하고 구매 번호와 비교합니다 (모든 계정)
-func CheckWinning(cfg config.Config, bot *telegram.Bot) {
+// CheckWinning은 최근 당첨번호를 확인하고 마지막 구매 번호와 비교합니다 (모든 계정)
+func CheckWinning(cfg config.Config, hub *notify.Hub) error {
+	_, err := CheckWinningRound(cfg, hub, 0)
+	return err
+}
+
+// CheckWinningRound는 지정한 회차의 당첨번호를 확인하고 그 회차의 구매 번호와 비교해 계정별 결과를 반환합니다
+// round가 0이면 최근 회차와 마지막 구매 내역을 사용합니다
+func CheckWinningRound(cfg config.Config, hub *notify.Hub, round int) ([]report.Winning, error) {
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println("          🎰 당첨번호 확인 작업")
 	log.Printf("          (총 %d개 계정)\n", len(cfg.Accounts))
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println()
 
-	// 1단계: 최근 당첨번호 조회
+	// 1단계: 당첨번호 조회
 	log.Println("=== 1단계: 당첨번호 조회 ===")
-	result, err := lottery.GetLatestResult()
+	var result *lottery.LottoResult
+	var err error
+	if round > 0 {
+		result, err = lottery.GetResult(round)
+	} else {
+		result, err = lottery.GetLatestResult()
+	}
 	if err != nil {
 		log.Printf("❌ 당첨번호 조회 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("❌ <b>당첨번호 조회 실패</b>\n\n%v", err))
-		}
-		return
+		configNotifier(cfg, hub).failure(message.FailureResult, "", err)
+		return nil, fmt.Errorf("당첨번호 조회 실패: %w", err)
 	}
 
 	// 2단계: 구매 내역 조회
 	log.Println()
 	log.Println("=== 2단계: 구매 내역 조회 ===")
-	history, err := lottery.GetLastPurchaseHistory()
+	var history *lottery.PurchaseHistory
+	if round > 0 {
+		history, err = lottery.LoadPurchaseHistory(result.Round)
+	} else {
+		history, err = lottery.GetLastPurchaseHistory()
+	}
 	if err != nil {
 		log.Printf("❌ 구매 내역 조회 실패: %v\n", err)
-		if bot != nil {
-			bot.SendMessageSafe(fmt.Sprintf("❌ <b>구매 내역 조회 실패</b>\n\n%v", err))
-		}
-		return
+		configNotifier(cfg, hub).failure(message.FailureHistory, "", err)
+		return nil, fmt.Errorf("구매 내역 조회 실패: %w", err)
 	}
 
+	records := make([]report.Winning, 0, len(cfg.Accounts))
 	if history == nil {
 		log.Println("ℹ️  저장된 구매 내역이 없습니다")
-		if bot != nil {
-			bot.SendMessageSafe("ℹ️ <b>당첨 확인 불가</b>\n\n저장된 구매 내역이 없습니다.")
+		configNotifier(cfg, hub).send(compose(message.EventWinning, message.SeverityInfo, lottery.WinningMessage("", result, nil)), notify.Options{})
+		for _, account := range cfg.Accounts {
+			records = append(records, winningRecord(account.UserID, result, nil))
 		}
-		return
+		return records, nil
 	}
 
 	log.Printf("✅ 구매 내역 조회 완료: %s회\n", history.Round)
@@ -381,6 +617,8 @@ func CheckWinning(cfg config.Config, bot *telegram.Bot) {
 	log.Println()
 	log.Println("=== 3단계: 당첨 확인 ===")
 
+	var cards photoBatch
+
 	for i, account := range cfg.Accounts {
 		log.Println()
 		log.Printf("┌─────────────────────────────────────┐")
@@ -388,16 +626,56 @@ func CheckWinning(cfg config.Config, bot *telegram.Bot) {
 		log.Printf("└─────────────────────────────────────┘")
 		log.Println()
 
-		// 당첨 메시지 생성
-		message := lottery.FormatWinningMessage(account.UserID, result, history)
+		// 당첨 메시지 생성 (별칭으로 표시)
+		winning := lottery.WinningMessage(account.UserID, result, history)
+		winning.Account = account.DisplayName()
+		msg := compose(message.EventWinning, message.WinningSeverity(winning.BestRank), winning)
+		records = append(records, winningRecord(account.UserID, result, history))
 		log.Printf("✅ 당첨 확인 완료\n")
 
-		// 텔레그램 전송
-		if bot != nil {
-			bot.SendMessageSafe(message)
+		// 알림 전송 (라우팅 규칙과 전송 정책에 따라, 1~3등 당첨은 critical)
+		deliveries := accountNotifier(account, hub).deliveries(msg.Event, msg.Severity, msg.Text, notify.Options{})
+		photo, hasPhoto := telegram.Photo{}, false
+		if len(deliveries) > 0 && hub.Images() {
+			photo, hasPhoto = resultPhoto(account.UserID, account.DisplayName(), result, history)
 		}
+		for _, d := range deliveries {
+			d.to.Send(msg, d.opts)
+			if hasPhoto {
+				cards.add(d, photo)
+			}
+		}
+	}
+
+	// 결과 카드 이미지는 텔레그램 채팅방별로 모아 한 번에 전송 (여러 장이면 앨범)
+	cards.send()
+
+	// 회차 요약 (요약 모드이거나 alert.digest.weekly 설정 시)
+	if cfg.Notify.Digest.WeeklySummary() {
+		sendWeekly(cfg, hub, result, records)
 	}
 
 	log.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	log.Println()
+
+	return records, nil
+}
+
+// accountBot은 계정의 기본 채팅방으로 보내는 봇을 반환합니다 (구매 승인처럼 한 채팅방에서 답을 받는 경우, 텔레그램 설정이 없으면 nil)
+func accountBot(account config.Account, hub *notify.Hub) *telegram.Bot {
+	return hub.Bot().WithChatID(account.ChatID())
+}
+
+// newClient는 계정 비밀번호를 가져와 클라이언트를 생성합니다 (비밀번호는 필요할 때만 읽음)
+func newClient(account config.Account) (*lottery.Client, error) {
+	password, err := account.ResolvePassword()
+	if err != nil {
+		return nil, err
+	}
+	client, err := lottery.NewClient(account.UserID, password)
+	if err != nil {
+		return nil, err
+	}
+	client.Alias = account.Alias
+	return client, nil
 }
@@ -0,0 +1,141 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// 재시도 설정
+const (
+	maxAttempts   = 4               // 요청당 최대 시도 횟수
+	maxRetryAfter = 5 * time.Minute // 이보다 오래 기다리라고 하면 재시도하지 않음
+	retryBackoff  = 2 * time.Second // 네트워크 오류, 5xx 응답 시 첫 대기 시간 (시도마다 두 배)
+)
+
+// apiResponse는 Bot API 공통 응답 형식입니다
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Parameters  *struct {
+		RetryAfter int `json:"retry_after"` // 429 응답 시 기다려야 하는 시간 (초)
+	} `json:"parameters"`
+}
+
+// APIError는 Bot API가 거절한 요청입니다
+type APIError struct {
+	Method      string
+	Code        int
+	Description string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("텔레그램 API 오류 (%s, %d): %s", e.Method, e.Code, e.Description)
+}
+
+// call은 Bot API 메서드를 호출하고 result 필드를 out에 디코딩합니다
+// 429 응답은 retry_after만큼 기다렸다가, 네트워크 오류와 5xx 응답은 점점 길게 기다렸다가 다시 시도합니다
+func (b *Bot) call(method string, payload interface{}, out interface{}) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("JSON 마샬링 실패: %w", err)
+	}
+	return b.post(method, jsonData, "application/json", requestTimeout(b.timeout, payload), out)
+}
+
+// post는 요청 본문을 보내고, 다시 시도할 수 있는 실패면 기다렸다가 다시 보냅니다
+func (b *Bot) post(method string, body []byte, contentType string, timeout time.Duration, out interface{}) error {
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		wait, err := b.do(method, body, contentType, timeout, out)
+		if err == nil {
+			return nil
+		}
+		if wait == 0 || attempt >= maxAttempts {
+			return err
+		}
+
+		if wait < 0 {
+			wait = backoff
+			backoff *= 2
+		}
+		log.Printf("⏳ 텔레그램 %s 재시도 (%d/%d, %v 후): %v\n", method, attempt, maxAttempts-1, wait, err)
+		time.Sleep(wait)
+	}
+}
+
+// do는 요청을 한 번 보냅니다
+// 다시 시도할 수 있는 실패면 wait에 기다릴 시간(-1이면 기본 간격)을, 아니면 0을 반환합니다
+func (b *Bot) do(method string, body []byte, contentType string, timeout time.Duration, out interface{}) (wait time.Duration, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	apiURL := fmt.Sprintf("%s/bot%s/%s", b.baseURL, b.Token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("텔레그램 요청 생성 실패: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		// 토큰이 들어 있는 URL이 로그에 남지 않도록 원인만 표시
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return -1, fmt.Errorf("텔레그램 API 호출 실패 (%s): %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+
+	var apiResp apiResponse
+	if err := json.Unmarshal(data, &apiResp); err != nil {
+		if resp.StatusCode >= 500 {
+			return -1, fmt.Errorf("텔레그램 서버 오류 (%s, 상태: %d)", method, resp.StatusCode)
+		}
+		return 0, fmt.Errorf("텔레그램 응답 파싱 실패 (상태: %d): %s", resp.StatusCode, string(data))
+	}
+
+	if !apiResp.OK {
+		apiErr := &APIError{Method: method, Code: apiResp.ErrorCode, Description: apiResp.Description}
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests && apiResp.Parameters != nil:
+			retryAfter := time.Duration(apiResp.Parameters.RetryAfter) * time.Second
+			if retryAfter > maxRetryAfter {
+				return 0, apiErr
+			}
+			return max(retryAfter, time.Second), apiErr
+		case resp.StatusCode >= 500:
+			return -1, apiErr
+		}
+		return 0, apiErr
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(apiResp.Result, out); err != nil {
+			return 0, fmt.Errorf("텔레그램 결과 파싱 실패: %w", err)
+		}
+	}
+	return 0, nil
+}
+
+// requestTimeout은 요청 시간 제한입니다 (getUpdates 롱 폴링은 대기 시간만큼 더 김)
+func requestTimeout(base time.Duration, payload interface{}) time.Duration {
+	if m, ok := payload.(map[string]interface{}); ok {
+		if seconds, ok := m["timeout"].(int); ok && seconds > 0 {
+			return base + time.Duration(seconds)*time.Second
+		}
+	}
+	return base
+}
@@ -1,61 +1,219 @@
 package telegram
 
 import (
-	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"html"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
+// 기본 API 설정
+const (
+	DefaultBaseURL = "https://api.telegram.org"
+	defaultTimeout = 15 * time.Second // 요청 시간 제한 (롱 폴링은 대기 시간만큼 더함)
+)
+
+// Options는 Bot API 연결 설정입니다 (빈 값은 기본값)
+type Options struct {
+	BaseURL string        // API 주소 (로컬 Bot API 서버 등, 기본 https://api.telegram.org)
+	Proxy   string        // 프록시 URL (http://, https://, socks5://), 비우면 HTTPS_PROXY 환경변수
+	Timeout time.Duration // 요청 시간 제한
+	Silent  bool          // 모든 메시지를 무음(disable_notification)으로 전송
+	Images  bool          // 구매 번호, 당첨 결과를 공 이미지로도 전송
+}
+
+// SendOptions는 메시지별 전송 옵션입니다
+type SendOptions struct {
+	Silent    bool      // 알림 소리 없이 전송 (disable_notification)
+	NotBefore time.Time // 이 시각 이후에 전송 (조용한 시간, 보관함이 있을 때만 적용되고 없으면 바로 전송)
+}
+
+// Queue는 알림을 바로 보내지 않고 맡아 두었다가 전달하는 보관함입니다 (outbox)
+type Queue interface {
+	Enqueue(chatID, message string, opts SendOptions) error
+	EnqueuePhotos(chatID string, photos []Photo, opts SendOptions) error
+}
+
 // Bot은 텔레그램 봇 구조체입니다
 type Bot struct {
 	Token  string
 	ChatID string
+
+	client  *http.Client
+	baseURL string
+	timeout time.Duration
+	silent  bool
+	images  bool
+	queue   Queue // 설정되어 있으면 SendSafe 계열 알림은 보관함을 거쳐 전송
 }
 
-// New는 텔레그램 봇을 생성합니다
+// New는 기본 연결 설정으로 텔레그램 봇을 생성합니다
 func New(token, chatID string) *Bot {
+	bot, _ := NewWithOptions(token, chatID, Options{})
+	return bot
+}
+
+// NewWithOptions는 연결 설정을 지정해 텔레그램 봇을 생성합니다
+// 프록시 주소가 잘못되면 에러와 함께 기본 설정의 봇을 반환합니다
+func NewWithOptions(token, chatID string, opts Options) (*Bot, error) {
+	bot := &Bot{
+		Token:   token,
+		ChatID:  chatID,
+		baseURL: strings.TrimRight(opts.BaseURL, "/"),
+		timeout: opts.Timeout,
+		silent:  opts.Silent,
+		images:  opts.Images,
+	}
+	if bot.baseURL == "" {
+		bot.baseURL = DefaultBaseURL
+	}
+	if bot.timeout <= 0 {
+		bot.timeout = defaultTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	var err error
+	if opts.Proxy != "" {
+		var proxyURL *url.URL
+		if proxyURL, err = url.Parse(opts.Proxy); err == nil && proxyURL.Host == "" {
+			err = fmt.Errorf("호스트가 없습니다")
+		}
+		if err != nil {
+			err = fmt.Errorf("텔레그램 프록시 주소 오류 (%s): %w", opts.Proxy, err)
+		} else {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	// 요청별 시간 제한은 call에서 지정 (롱 폴링 요청은 더 길게)
+	bot.client = &http.Client{Transport: transport}
+	return bot, err
+}
+
+// WithChatID는 다른 채팅방으로 메시지를 보내는 봇 사본을 반환합니다 (chatID가 비어 있으면 그대로)
+func (b *Bot) WithChatID(chatID string) *Bot {
+	if b == nil || chatID == "" || chatID == b.ChatID {
+		return b
+	}
 	return &Bot{
-		Token:  token,
-		ChatID: chatID,
+		Token:   b.Token,
+		ChatID:  chatID,
+		client:  b.client,
+		baseURL: b.baseURL,
+		timeout: b.timeout,
+		silent:  b.silent,
+		images:  b.images,
+		queue:   b.queue,
+	}
+}
+
+// SetQueue는 알림 보관함을 지정합니다 (nil이면 바로 전송)
+func (b *Bot) SetQueue(q Queue) {
+	if b != nil {
+		b.queue = q
 	}
 }
 
-// SendMessage는 텔레그램 메시지를 전송합니다
+// SendMessage는 텔레그램 메시지를 전송합니다 (4096자를 넘으면 줄 단위로 나눠 전송)
 func (b *Bot) SendMessage(message string) error {
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", b.Token)
+	return b.Send(message, SendOptions{})
+}
 
-	payload := map[string]interface{}{
-		"chat_id":    b.ChatID,
-		"text":       message,
-		"parse_mode": "HTML",
-	}
+// Send는 옵션을 지정해 텔레그램 메시지를 전송합니다
+func (b *Bot) Send(message string, opts SendOptions) error {
+	_, err := b.SendFrom(message, 0, opts)
+	return err
+}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("JSON 마샬링 실패: %w", err)
+// SendFrom은 메시지를 나눈 부분 중 from번째(0부터)부터 전송하고, 보낸 부분까지 센 수를 반환합니다
+// 중간에 실패해도 그때까지 보낸 수를 돌려주므로, 보관함은 다음 시도에서 보내지 않은 부분부터 이어 보냅니다
+func (b *Bot) SendFrom(message string, from int, opts SendOptions) (sent int, err error) {
+	parts := SplitMessage(message, MaxMessageLength)
+	for sent = from; sent < len(parts); sent++ {
+		if err := b.sendPart(parts[sent], len(parts) > 1, opts); err != nil {
+			if len(parts) > 1 {
+				return sent, fmt.Errorf("텔레그램 메시지 전송 실패 (%d/%d): %w", sent+1, len(parts), err)
+			}
+			return sent, fmt.Errorf("텔레그램 메시지 전송 실패: %w", err)
+		}
 	}
 
-	resp, err := http.Post(apiURL, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("텔레그램 API 호출 실패: %w", err)
+	log.Println("✅ 텔레그램 메시지 전송 완료")
+	return sent, nil
+}
+
+// sendPart는 나눈 메시지 한 부분을 전송합니다
+// 나눈 부분이 형식 오류나 길이 초과로 거부되면 (나누면서 생긴 문제) 더 잘게 나누거나 서식 없이 다시 보냅니다
+func (b *Bot) sendPart(part string, split bool, opts SendOptions) error {
+	err := b.sendText(part, "HTML", opts)
+
+	var apiErr *APIError
+	if err == nil || !split || !errors.As(err, &apiErr) || apiErr.Code != http.StatusBadRequest {
+		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("텔레그램 메시지 전송 실패 (상태: %d): %s", resp.StatusCode, string(body))
+	description := strings.ToLower(apiErr.Description)
+	switch {
+	case strings.Contains(description, "too long"):
+		log.Printf("⚠️  나눈 메시지가 너무 길어 다시 나눠 보냅니다: %v\n", err)
+		for _, sub := range SplitMessage(part, MaxMessageLength/2) {
+			if err := b.sendText(sub, "HTML", opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	case strings.Contains(description, "parse entities"):
+		log.Printf("⚠️  나눈 메시지의 서식 오류로 서식 없이 보냅니다: %v\n", err)
+		return b.sendText(html.UnescapeString(htmlTag.ReplaceAllString(part, "")), "", opts)
 	}
+	return err
+}
 
-	log.Println("✅ 텔레그램 메시지 전송 완료")
-	return nil
+// sendText는 sendMessage를 호출합니다 (parseMode가 비어 있으면 서식 없이)
+func (b *Bot) sendText(text, parseMode string, opts SendOptions) error {
+	payload := map[string]interface{}{
+		"chat_id": b.ChatID,
+		"text":    text,
+	}
+	if parseMode != "" {
+		payload["parse_mode"] = parseMode
+	}
+	if opts.Silent || b.silent {
+		payload["disable_notification"] = true
+	}
+	return b.call("sendMessage", payload, nil)
 }
 
 // SendMessageSafe는 텔레그램 메시지를 전송하고 에러를 로그로 출력합니다
 func (b *Bot) SendMessageSafe(message string) {
-	if err := b.SendMessage(message); err != nil {
-		log.Printf("⚠️  텔레그램 메시지 전송 실패: %v\n", err)
+	b.SendSafe(message, SendOptions{})
+}
+
+// SendSilentSafe는 알림 소리 없이 메시지를 전송합니다 (안내성 메시지용)
+func (b *Bot) SendSilentSafe(message string) {
+	b.SendSafe(message, SendOptions{Silent: true})
+}
+
+// SendSafe는 옵션을 지정해 메시지를 전송하고 에러를 로그로 출력합니다
+// 보관함이 있으면 보관함에 넣고 바로 반환합니다 (전송은 보관함의 발송기가 재시도하며 처리)
+func (b *Bot) SendSafe(message string, opts SendOptions) {
+	if b.queue != nil {
+		err := b.queue.Enqueue(b.ChatID, message, opts)
+		if err == nil {
+			return
+		}
+		log.Printf("⚠️  알림 보관함 저장 실패, 바로 전송합니다: %v\n", err)
+	}
+	if err := b.Send(message, opts); err != nil {
+		log.Printf("⚠️  %v\n", err)
 	}
 }
+
+// Escape는 HTML 메시지에 넣을 값을 이스케이프합니다 (에러 메시지, 사용자 입력 등)
+func Escape(v interface{}) string {
+	return html.EscapeString(fmt.Sprint(v))
+}
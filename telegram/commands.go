@@ -0,0 +1,384 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// staleCommandAge는 처리하지 않고 버리는 오래된 명령의 기준입니다
+// (서비스가 꺼져 있는 동안 쌓인 /buy 같은 명령이 재시작 시 실행되지 않도록)
+const staleCommandAge = 2 * time.Minute
+
+// Command는 봇에게 온 명령 메시지입니다 (예: "/buy your_id")
+type Command struct {
+	Name      string   // 명령 이름 ("/"와 "@봇이름" 제외, 예: "buy")
+	Args      []string // 명령 뒤의 인자
+	ChatID    int64    // 명령이 온 채팅방
+	UserID    int64    // 명령을 보낸 사용자
+	UserName  string   // 보낸 사람 표시 이름 (로그용)
+	MessageID int      // 답장을 달 메시지
+}
+
+// CommandHandler는 명령을 처리하고 답장 메시지(HTML)를 반환합니다 (빈 문자열이면 답장 안 함)
+type CommandHandler func(cmd Command) string
+
+// commandEntry는 등록된 명령입니다
+type commandEntry struct {
+	description string
+	handler     CommandHandler
+}
+
+// commandMessage는 getUpdates 응답의 텍스트 메시지입니다
+type commandMessage struct {
+	MessageID int    `json:"message_id"`
+	Date      int64  `json:"date"`
+	Text      string `json:"text"`
+	Chat      Chat   `json:"chat"`
+	From      *struct {
+		ID        int64  `json:"id"`
+		Username  string `json:"username"`
+		FirstName string `json:"first_name"`
+	} `json:"from"`
+}
+
+// listenerUpdate는 명령 수신기가 처리하는 업데이트입니다
+type listenerUpdate struct {
+	UpdateID      int             `json:"update_id"`
+	Message       *commandMessage `json:"message"`
+	CallbackQuery *callbackQuery  `json:"callback_query"`
+}
+
+// Listener는 getUpdates 롱 폴링으로 명령을 받아 처리하는 수신기입니다 (스케줄러 모드)
+// 실행 중에는 같은 봇의 인라인 버튼 응답(WaitForCallback)도 이 수신기를 통해 전달됩니다
+// (텔레그램은 한 토큰에 getUpdates를 동시에 하나만 허용)
+type Listener struct {
+	bot     *Bot
+	allowed map[int64]bool
+
+	mu       sync.Mutex
+	commands map[string]commandEntry
+	waiters  map[callbackKey]chan string // 채팅방과 메시지 ID별 버튼 응답 대기자
+
+	queue chan Command
+	stop  chan struct{}
+	done  chan struct{} // 명령 처리 고루틴 종료
+}
+
+// activeListeners는 실행 중인 수신기입니다 (봇 토큰별)
+var (
+	activeListenersMu sync.Mutex
+	activeListeners   = make(map[string]*Listener)
+)
+
+// listenerFor는 토큰으로 실행 중인 수신기를 찾습니다
+func listenerFor(token string) *Listener {
+	activeListenersMu.Lock()
+	defer activeListenersMu.Unlock()
+	return activeListeners[token]
+}
+
+// NewListener는 허용된 채팅방/사용자 ID의 명령만 처리하는 수신기를 만듭니다
+func NewListener(bot *Bot, allowed []int64) *Listener {
+	l := &Listener{
+		bot:      bot,
+		allowed:  make(map[int64]bool),
+		commands: make(map[string]commandEntry),
+		waiters:  make(map[callbackKey]chan string),
+		queue:    make(chan Command, 16),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, id := range allowed {
+		l.allowed[id] = true
+	}
+	return l
+}
+
+// Handle은 명령을 등록합니다 (name은 "/" 없이, 예: "balance")
+func (l *Listener) Handle(name, description string, handler CommandHandler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.commands[name] = commandEntry{description: description, handler: handler}
+}
+
+// Start는 명령 목록을 봇 메뉴에 등록하고 수신을 시작합니다
+func (l *Listener) Start() error {
+	activeListenersMu.Lock()
+	if _, ok := activeListeners[l.bot.Token]; ok {
+		activeListenersMu.Unlock()
+		return fmt.Errorf("이미 명령 수신기가 실행 중입니다")
+	}
+	activeListeners[l.bot.Token] = l
+	activeListenersMu.Unlock()
+
+	if err := l.bot.call("setMyCommands", map[string]interface{}{"commands": l.menu()}, nil); err != nil {
+		log.Printf("⚠️  텔레그램 명령 메뉴 등록 실패: %v\n", err)
+	}
+
+	go l.poll()
+	go l.work()
+	return nil
+}
+
+// Stop은 수신을 멈추고 처리 중인 명령이 끝날 때까지 기다립니다
+// (진행 중인 롱 폴링 요청은 기다리지 않음)
+func (l *Listener) Stop() {
+	activeListenersMu.Lock()
+	if activeListeners[l.bot.Token] == l {
+		delete(activeListeners, l.bot.Token)
+	}
+	activeListenersMu.Unlock()
+
+	close(l.stop)
+	<-l.done
+}
+
+// menu는 setMyCommands에 보낼 명령 목록입니다 (이름순)
+func (l *Listener) menu() []map[string]string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	names := make([]string, 0, len(l.commands))
+	for name := range l.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	menu := make([]map[string]string, 0, len(names))
+	for _, name := range names {
+		menu = append(menu, map[string]string{"command": name, "description": l.commands[name].description})
+	}
+	return menu
+}
+
+// poll은 업데이트를 받아 명령은 작업 큐로, 버튼 응답은 대기자에게 전달합니다
+func (l *Listener) poll() {
+	started := time.Now()
+	offset := 0
+	for {
+		select {
+		case <-l.stop:
+			return
+		default:
+		}
+
+		payload := map[string]interface{}{
+			"offset":          offset,
+			"timeout":         pollTimeoutSeconds,
+			"allowed_updates": []string{"message", "callback_query"},
+		}
+		var updates []listenerUpdate
+		if err := l.bot.call("getUpdates", payload, &updates); err != nil {
+			log.Printf("⚠️  텔레그램 업데이트 조회 실패, 재시도합니다: %v\n", err)
+			select {
+			case <-l.stop:
+				return
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			select {
+			case <-l.stop:
+				return
+			default:
+			}
+			if u.CallbackQuery != nil {
+				l.deliverCallback(u.CallbackQuery)
+			}
+			if u.Message != nil {
+				l.dispatch(u.Message, started)
+			}
+		}
+	}
+}
+
+// dispatch는 메시지가 허용된 명령이면 작업 큐에 넣습니다
+func (l *Listener) dispatch(m *commandMessage, started time.Time) {
+	cmd, ok := parseCommand(m)
+	if !ok {
+		return
+	}
+
+	if !l.allowed[cmd.ChatID] && !l.allowed[cmd.UserID] {
+		log.Printf("🚫 허용되지 않은 텔레그램 명령 무시: /%s (채팅방 %d, 사용자 %d %s)\n", cmd.Name, cmd.ChatID, cmd.UserID, cmd.UserName)
+		return
+	}
+
+	if time.Unix(m.Date, 0).Before(started.Add(-staleCommandAge)) {
+		log.Printf("⏭ 오래된 텔레그램 명령 무시: /%s (%s)\n", cmd.Name, time.Unix(m.Date, 0).Format("01/02 15:04"))
+		return
+	}
+
+	l.mu.Lock()
+	_, known := l.commands[cmd.Name]
+	l.mu.Unlock()
+	if !known {
+		l.Reply(cmd, fmt.Sprintf("❓ 알 수 없는 명령입니다: /%s\n\n/help 로 명령 목록을 확인하세요.", html.EscapeString(cmd.Name)))
+		return
+	}
+
+	select {
+	case l.queue <- cmd:
+	default:
+		l.Reply(cmd, "⏳ 처리 중인 명령이 많습니다. 잠시 후 다시 시도해주세요.")
+	}
+}
+
+// work는 명령을 받은 순서대로 하나씩 처리합니다 (구매 등 작업이 겹치지 않도록)
+func (l *Listener) work() {
+	defer close(l.done)
+	for {
+		var cmd Command
+		select {
+		case <-l.stop:
+			return
+		case cmd = <-l.queue:
+		}
+
+		log.Printf("📨 텔레그램 명령: /%s %s (%s)\n", cmd.Name, strings.Join(cmd.Args, " "), cmd.UserName)
+		if reply := l.run(cmd); reply != "" {
+			l.Reply(cmd, reply)
+		}
+	}
+}
+
+// run은 명령 처리기를 실행합니다 (패닉이 나도 수신기는 계속 동작)
+func (l *Listener) run(cmd Command) (reply string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("💥 텔레그램 명령 처리 중 패닉: /%s: %v\n%s", cmd.Name, r, debug.Stack())
+			reply = fmt.Sprintf("💥 <b>명령 처리 중 오류</b>\n\n%s", html.EscapeString(fmt.Sprint(r)))
+		}
+	}()
+
+	l.mu.Lock()
+	entry := l.commands[cmd.Name]
+	l.mu.Unlock()
+	return entry.handler(cmd)
+}
+
+// Reply는 명령 메시지에 답장합니다 (백그라운드에서 끝난 명령의 결과 등)
+func (l *Listener) Reply(cmd Command, message string) {
+	if err := l.bot.Reply(cmd.ChatID, cmd.MessageID, message); err != nil {
+		log.Printf("⚠️  텔레그램 답장 실패: %v\n", err)
+	}
+}
+
+// Commands는 등록된 명령과 설명을 이름순으로 반환합니다 (/help 답장용)
+func (l *Listener) Commands() [][2]string {
+	var list [][2]string
+	for _, item := range l.menu() {
+		list = append(list, [2]string{item["command"], item["description"]})
+	}
+	return list
+}
+
+// waitCallback은 수신기를 통해 인라인 버튼 응답을 기다립니다
+func (l *Listener) waitCallback(chatID string, messageID int, timeout time.Duration) (string, bool) {
+	key := callbackKey{chatID: chatID, messageID: messageID}
+	ch := make(chan string, 1)
+	l.mu.Lock()
+	l.waiters[key] = ch
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		delete(l.waiters, key)
+		l.mu.Unlock()
+	}()
+
+	select {
+	case data := <-ch:
+		return data, true
+	case <-time.After(timeout):
+		return "", false
+	}
+}
+
+// deliverCallback은 버튼 응답을 기다리는 대기자에게 전달합니다
+func (l *Listener) deliverCallback(cq *callbackQuery) {
+	if cq.Message == nil {
+		return
+	}
+
+	// 메시지를 보낸 채팅방에서 온 응답만 인정 (메시지 ID는 채팅방마다 따로 매겨짐)
+	key := callbackKey{chatID: strconv.FormatInt(cq.Message.Chat.ID, 10), messageID: cq.Message.MessageID}
+	l.mu.Lock()
+	ch, ok := l.waiters[key]
+	l.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if err := l.bot.call("answerCallbackQuery", map[string]interface{}{"callback_query_id": cq.ID}, nil); err != nil {
+		log.Printf("⚠️  콜백 응답 실패: %v\n", err)
+	}
+	select {
+	case ch <- cq.Data:
+	default:
+	}
+}
+
+// parseCommand는 "/명령@봇이름 인자..." 형식의 메시지를 해석합니다
+func parseCommand(m *commandMessage) (Command, bool) {
+	fields := strings.Fields(m.Text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return Command{}, false
+	}
+
+	name, _, _ := strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+	cmd := Command{
+		Name:      strings.ToLower(name),
+		Args:      fields[1:],
+		ChatID:    m.Chat.ID,
+		MessageID: m.MessageID,
+		UserName:  m.Chat.Name(),
+	}
+	if m.From != nil {
+		cmd.UserID = m.From.ID
+		switch {
+		case m.From.Username != "":
+			cmd.UserName = "@" + m.From.Username
+		case m.From.FirstName != "":
+			cmd.UserName = m.From.FirstName
+		default:
+			cmd.UserName = strconv.FormatInt(m.From.ID, 10)
+		}
+	}
+	return cmd, cmd.Name != ""
+}
+
+// Reply는 지정한 채팅방의 메시지에 답장합니다 (원본 메시지가 지워졌으면 일반 메시지로)
+// 긴 답장은 나눠서 보내며 첫 부분만 원본 메시지에 답장으로 달립니다
+func (b *Bot) Reply(chatID int64, messageID int, message string) error {
+	for i, part := range SplitMessage(message, MaxMessageLength) {
+		payload := map[string]interface{}{
+			"chat_id":    chatID,
+			"text":       part,
+			"parse_mode": "HTML",
+		}
+		if i == 0 {
+			payload["reply_parameters"] = map[string]interface{}{
+				"message_id":                  messageID,
+				"allow_sending_without_reply": true,
+			}
+		}
+		if b.silent {
+			payload["disable_notification"] = true
+		}
+		if err := b.call("sendMessage", payload, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// callbackFor는 chatID 채팅방의 messageID 메시지에서 누른 버튼 응답을 만듭니다
+func callbackFor(t *testing.T, chatID int64, messageID int, data string) *callbackQuery {
+	t.Helper()
+	var cq callbackQuery
+	raw := fmt.Sprintf(`{"id":"cb-%d-%d","data":%q,"message":{"message_id":%d,"chat":{"id":%d}}}`, chatID, messageID, data, messageID, chatID)
+	if err := json.Unmarshal([]byte(raw), &cq); err != nil {
+		t.Fatal(err)
+	}
+	return &cq
+}
+
+func TestListenerCallbackSameMessageIDInTwoChats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	}))
+	defer server.Close()
+
+	bot, err := NewWithOptions("token", "100", Options{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewListener(bot, nil)
+
+	// 서로 다른 채팅방의 승인 메시지가 같은 메시지 ID를 받은 경우
+	type answer struct {
+		data string
+		ok   bool
+	}
+	first := make(chan answer, 1)
+	second := make(chan answer, 1)
+	go func() {
+		data, ok := l.waitCallback("100", 7, 2*time.Second)
+		first <- answer{data, ok}
+	}()
+	go func() {
+		data, ok := l.waitCallback("200", 7, 2*time.Second)
+		second <- answer{data, ok}
+	}()
+
+	// 두 대기자가 모두 등록될 때까지 기다림
+	for deadline := time.Now().Add(time.Second); ; {
+		l.mu.Lock()
+		n := len(l.waiters)
+		l.mu.Unlock()
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("대기자가 2개여야 함: %d", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	l.deliverCallback(callbackFor(t, 300, 7, "buy")) // 기다리지 않는 채팅방
+	l.deliverCallback(callbackFor(t, 200, 7, "skip"))
+	l.deliverCallback(callbackFor(t, 100, 7, "buy"))
+
+	if got := <-first; !got.ok || got.data != "buy" {
+		t.Errorf("채팅방 100: %+v, want buy", got)
+	}
+	if got := <-second; !got.ok || got.data != "skip" {
+		t.Errorf("채팅방 200: %+v, want skip", got)
+	}
+}
@@ -0,0 +1,208 @@
+package telegram
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 롱 폴링 대기 시간 (초)
+const pollTimeoutSeconds = 30
+
+// InlineButton은 인라인 키보드 버튼입니다
+type InlineButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+// callbackQuery는 인라인 버튼 클릭 이벤트입니다
+type callbackQuery struct {
+	ID      string `json:"id"`
+	Data    string `json:"data"`
+	Message *struct {
+		MessageID int `json:"message_id"`
+		Chat      struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+// update는 getUpdates 응답의 개별 업데이트입니다
+type update struct {
+	UpdateID      int            `json:"update_id"`
+	CallbackQuery *callbackQuery `json:"callback_query"`
+}
+
+// SendMessageWithButtons는 인라인 버튼이 달린 메시지를 전송하고 메시지 ID를 반환합니다
+func (b *Bot) SendMessageWithButtons(message string, buttons []InlineButton) (int, error) {
+	payload := map[string]interface{}{
+		"chat_id":    b.ChatID,
+		"text":       message,
+		"parse_mode": "HTML",
+		"reply_markup": map[string]interface{}{
+			"inline_keyboard": [][]InlineButton{buttons},
+		},
+	}
+
+	var sent struct {
+		MessageID int `json:"message_id"`
+	}
+	if err := b.call("sendMessage", payload, &sent); err != nil {
+		return 0, err
+	}
+
+	log.Println("✅ 텔레그램 승인 요청 메시지 전송 완료")
+	return sent.MessageID, nil
+}
+
+// EditMessage는 메시지 내용을 바꾸고 인라인 버튼을 제거합니다
+func (b *Bot) EditMessage(messageID int, message string) error {
+	payload := map[string]interface{}{
+		"chat_id":    b.ChatID,
+		"message_id": messageID,
+		"text":       message,
+		"parse_mode": "HTML",
+	}
+	return b.call("editMessageText", payload, nil)
+}
+
+// WaitForCallback은 지정한 메시지의 인라인 버튼 응답을 기다립니다
+// 시간 내 응답이 없으면 ok=false를 반환합니다
+// 여러 메시지의 응답을 동시에 기다려도 됩니다 (같은 토큰의 대기자끼리 getUpdates 호출을 나눠 씀)
+func (b *Bot) WaitForCallback(messageID int, timeout time.Duration) (data string, ok bool, err error) {
+	// 명령 수신기가 실행 중이면 getUpdates를 직접 호출하지 않고 수신기에서 전달받음
+	if l := listenerFor(b.Token); l != nil {
+		data, ok = l.waitCallback(b.ChatID, messageID, timeout)
+		return data, ok, nil
+	}
+
+	p := pollerFor(b.Token)
+	key := callbackKey{chatID: b.ChatID, messageID: messageID}
+	p.register(key)
+	defer p.unregister(key)
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if data, ok := p.answer(key); ok {
+			return data, true, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return "", false, nil
+		}
+
+		// 다른 대기자가 getUpdates를 호출 중이면 받은 응답을 넘겨받을 때까지 잠시 대기
+		if !p.polling.TryLock() {
+			time.Sleep(min(time.Second, remaining))
+			continue
+		}
+		b.pollCallbacks(p, min(pollTimeoutSeconds, int(remaining.Seconds())+1))
+		p.polling.Unlock()
+	}
+}
+
+// callbackKey는 버튼 응답을 기다리는 메시지입니다 (메시지 ID는 채팅방마다 따로 매겨짐)
+type callbackKey struct {
+	chatID    string
+	messageID int
+}
+
+// callbackPoller는 명령 수신기 없이 버튼 응답을 기다릴 때 쓰는 봇 토큰별 상태입니다
+// 텔레그램은 한 토큰에 getUpdates를 동시에 하나만 허용하므로, 대기자 중 하나만 호출하고 받은 응답은 주인에게 넘깁니다
+type callbackPoller struct {
+	polling sync.Mutex // getUpdates 호출 중
+
+	mu      sync.Mutex
+	offset  int                    // getUpdates 오프셋 (처리한 마지막 업데이트 + 1)
+	waiters map[callbackKey]bool   // 응답을 기다리는 메시지
+	answers map[callbackKey]string // 받아 두었지만 아직 가져가지 않은 응답
+}
+
+// callbackPollers는 봇 토큰별 버튼 응답 대기 상태입니다
+var (
+	callbackPollersMu sync.Mutex
+	callbackPollers   = make(map[string]*callbackPoller)
+)
+
+// pollerFor는 토큰의 버튼 응답 대기 상태를 찾거나 만듭니다
+func pollerFor(token string) *callbackPoller {
+	callbackPollersMu.Lock()
+	defer callbackPollersMu.Unlock()
+
+	p, ok := callbackPollers[token]
+	if !ok {
+		p = &callbackPoller{waiters: make(map[callbackKey]bool), answers: make(map[callbackKey]string)}
+		callbackPollers[token] = p
+	}
+	return p
+}
+
+func (p *callbackPoller) register(key callbackKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.waiters[key] = true
+}
+
+func (p *callbackPoller) unregister(key callbackKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.waiters, key)
+	delete(p.answers, key)
+}
+
+// answer는 받아 둔 응답을 가져갑니다
+func (p *callbackPoller) answer(key callbackKey) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	data, ok := p.answers[key]
+	return data, ok
+}
+
+// pollCallbacks는 getUpdates를 한 번 호출해 기다리는 메시지의 버튼 응답을 받아 둡니다 (p.polling을 잡은 상태에서 호출)
+func (b *Bot) pollCallbacks(p *callbackPoller, pollSeconds int) {
+	p.mu.Lock()
+	offset := p.offset
+	p.mu.Unlock()
+
+	payload := map[string]interface{}{
+		"offset":          offset,
+		"timeout":         pollSeconds,
+		"allowed_updates": []string{"callback_query"},
+	}
+
+	var updates []update
+	if err := b.call("getUpdates", payload, &updates); err != nil {
+		log.Printf("⚠️  텔레그램 업데이트 조회 실패, 재시도합니다: %v\n", err)
+		time.Sleep(5 * time.Second)
+		return
+	}
+
+	for _, u := range updates {
+		p.mu.Lock()
+		p.offset = u.UpdateID + 1
+		p.mu.Unlock()
+
+		cq := u.CallbackQuery
+		if cq == nil || cq.Message == nil {
+			continue
+		}
+		// 기다리는 메시지와 설정된 채팅방에서 온 응답만 인정
+		key := callbackKey{chatID: strconv.FormatInt(cq.Message.Chat.ID, 10), messageID: cq.Message.MessageID}
+		p.mu.Lock()
+		waiting := p.waiters[key]
+		p.mu.Unlock()
+		if !waiting {
+			continue
+		}
+
+		if err := b.call("answerCallbackQuery", map[string]interface{}{"callback_query_id": cq.ID}, nil); err != nil {
+			log.Printf("⚠️  콜백 응답 실패: %v\n", err)
+		}
+
+		p.mu.Lock()
+		p.answers[key] = cq.Data
+		p.mu.Unlock()
+	}
+}
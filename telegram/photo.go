@@ -0,0 +1,156 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// 사진 전송 제한
+const (
+	MaxCaptionLength = 1024             // 사진 캡션 최대 길이
+	maxMediaGroup    = 10               // sendMediaGroup 한 번에 보낼 수 있는 사진 수
+	uploadTimeout    = 60 * time.Second // 사진 업로드 최소 시간 제한
+)
+
+// Photo는 보낼 사진입니다 (PNG 등 이미지 파일 내용과 HTML 캡션)
+type Photo struct {
+	Data    []byte `json:"data"`
+	Caption string `json:"caption,omitempty"`
+}
+
+// ImagesEnabled는 번호 이미지 전송이 켜져 있는지 반환합니다
+func (b *Bot) ImagesEnabled() bool {
+	return b != nil && b.images
+}
+
+// SendPhotos는 사진을 전송합니다 (1장은 sendPhoto, 여러 장은 10장씩 묶어 sendMediaGroup)
+func (b *Bot) SendPhotos(photos []Photo, opts SendOptions) error {
+	_, err := b.SendPhotosFrom(photos, 0, opts)
+	return err
+}
+
+// SendPhotosFrom은 사진 묶음(maxMediaGroup장씩) 중 from번째(0부터)부터 전송하고, 보낸 묶음까지 센 수를 반환합니다
+func (b *Bot) SendPhotosFrom(photos []Photo, from int, opts SendOptions) (sent int, err error) {
+	for sent = from; sent*maxMediaGroup < len(photos); sent++ {
+		start := sent * maxMediaGroup
+		group := photos[start:min(start+maxMediaGroup, len(photos))]
+
+		var err error
+		if len(group) == 1 {
+			err = b.sendPhoto(group[0], opts)
+		} else {
+			err = b.sendMediaGroup(group, opts)
+		}
+		if err != nil {
+			return sent, fmt.Errorf("텔레그램 사진 전송 실패: %w", err)
+		}
+	}
+
+	log.Printf("✅ 텔레그램 사진 전송 완료 (%d장)\n", len(photos))
+	return sent, nil
+}
+
+// SendPhotosSafe는 사진을 전송하고 에러를 로그로 출력합니다 (보관함이 있으면 보관함을 거쳐 전송)
+func (b *Bot) SendPhotosSafe(photos []Photo, opts SendOptions) {
+	if len(photos) == 0 {
+		return
+	}
+	if b.queue != nil {
+		err := b.queue.EnqueuePhotos(b.ChatID, photos, opts)
+		if err == nil {
+			return
+		}
+		log.Printf("⚠️  알림 보관함 저장 실패, 바로 전송합니다: %v\n", err)
+	}
+	if err := b.SendPhotos(photos, opts); err != nil {
+		log.Printf("⚠️  %v\n", err)
+	}
+}
+
+func (b *Bot) sendPhoto(photo Photo, opts SendOptions) error {
+	fields := b.uploadFields(opts)
+	if photo.Caption != "" {
+		fields = append(fields, [2]string{"caption", caption(photo.Caption)}, [2]string{"parse_mode", "HTML"})
+	}
+	return b.upload("sendPhoto", fields, []attachment{{"photo", photo.Data}})
+}
+
+func (b *Bot) sendMediaGroup(photos []Photo, opts SendOptions) error {
+	type inputMedia struct {
+		Type      string `json:"type"`
+		Media     string `json:"media"`
+		Caption   string `json:"caption,omitempty"`
+		ParseMode string `json:"parse_mode,omitempty"`
+	}
+
+	media := make([]inputMedia, len(photos))
+	files := make([]attachment, len(photos))
+	for i, photo := range photos {
+		name := "photo" + strconv.Itoa(i)
+		media[i] = inputMedia{Type: "photo", Media: "attach://" + name}
+		if photo.Caption != "" {
+			media[i].Caption, media[i].ParseMode = caption(photo.Caption), "HTML"
+		}
+		files[i] = attachment{name, photo.Data}
+	}
+
+	mediaJSON, err := json.Marshal(media)
+	if err != nil {
+		return fmt.Errorf("JSON 마샬링 실패: %w", err)
+	}
+	return b.upload("sendMediaGroup", append(b.uploadFields(opts), [2]string{"media", string(mediaJSON)}), files)
+}
+
+// uploadFields는 사진 전송 공통 필드입니다
+func (b *Bot) uploadFields(opts SendOptions) [][2]string {
+	fields := [][2]string{{"chat_id", b.ChatID}}
+	if opts.Silent || b.silent {
+		fields = append(fields, [2]string{"disable_notification", "true"})
+	}
+	return fields
+}
+
+// attachment는 요청에 첨부할 파일입니다 (name은 폼 필드 이름)
+type attachment struct {
+	name string
+	data []byte
+}
+
+// upload는 multipart/form-data로 파일을 첨부해 Bot API를 호출합니다
+func (b *Bot) upload(method string, fields [][2]string, files []attachment) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, field := range fields {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return fmt.Errorf("요청 생성 실패: %w", err)
+		}
+	}
+	for _, file := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s.png"`, file.name, file.name))
+		header.Set("Content-Type", "image/png")
+		part, err := writer.CreatePart(header)
+		if err == nil {
+			_, err = part.Write(file.data)
+		}
+		if err != nil {
+			return fmt.Errorf("요청 생성 실패: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("요청 생성 실패: %w", err)
+	}
+
+	return b.post(method, body.Bytes(), writer.FormDataContentType(), max(b.timeout, uploadTimeout), nil)
+}
+
+// caption은 캡션을 최대 길이에 맞게 자릅니다 (열린 태그는 닫음)
+func caption(text string) string {
+	return SplitMessage(text, MaxCaptionLength)[0]
+}
@@ -0,0 +1,99 @@
+package telegram
+
+import (
+	"strconv"
+	"time"
+)
+
+// Chat은 메시지가 온 채팅방 정보입니다
+type Chat struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"` // private, group, supergroup, channel
+	Title     string `json:"title"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+}
+
+// Name은 채팅방 표시 이름을 반환합니다
+func (c Chat) Name() string {
+	switch {
+	case c.Title != "":
+		return c.Title
+	case c.Username != "":
+		return "@" + c.Username
+	default:
+		return c.FirstName
+	}
+}
+
+// IDString은 설정 파일에 적을 채팅방 ID를 반환합니다
+func (c Chat) IDString() string {
+	return strconv.FormatInt(c.ID, 10)
+}
+
+// chatMessage는 getUpdates 응답의 메시지입니다 (채팅방 정보만 사용)
+type chatMessage struct {
+	Chat Chat `json:"chat"`
+}
+
+// chatUpdate는 채팅방 찾기에 사용하는 업데이트입니다
+type chatUpdate struct {
+	UpdateID     int          `json:"update_id"`
+	Message      *chatMessage `json:"message"`
+	ChannelPost  *chatMessage `json:"channel_post"`
+	MyChatMember *chatMessage `json:"my_chat_member"`
+}
+
+// GetMe는 봇 토큰을 확인하고 봇 사용자 이름을 반환합니다
+func (b *Bot) GetMe() (string, error) {
+	var me struct {
+		Username string `json:"username"`
+	}
+	if err := b.call("getMe", map[string]interface{}{}, &me); err != nil {
+		return "", err
+	}
+	return me.Username, nil
+}
+
+// WaitForChat은 봇에게 메시지가 올 때까지 기다려 그 채팅방을 반환합니다 (설정 마법사용)
+// 이전에 쌓인 업데이트는 건너뛰고, 시간 내 메시지가 없으면 ok=false를 반환합니다
+func (b *Bot) WaitForChat(timeout time.Duration) (chat Chat, ok bool, err error) {
+	// 쌓여 있던 업데이트 건너뛰기 (offset -1: 마지막 업데이트만 조회)
+	var pending []chatUpdate
+	if err := b.call("getUpdates", map[string]interface{}{"offset": -1}, &pending); err != nil {
+		return Chat{}, false, err
+	}
+	offset := 0
+	for _, u := range pending {
+		offset = u.UpdateID + 1
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return Chat{}, false, nil
+		}
+
+		payload := map[string]interface{}{
+			"offset":          offset,
+			"timeout":         min(pollTimeoutSeconds, int(remaining.Seconds())+1),
+			"allowed_updates": []string{"message", "channel_post", "my_chat_member"},
+		}
+		var updates []chatUpdate
+		if err := b.call("getUpdates", payload, &updates); err != nil {
+			return Chat{}, false, err
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			for _, m := range []*chatMessage{u.Message, u.ChannelPost, u.MyChatMember} {
+				if m != nil && m.Chat.ID != 0 {
+					// 다음 getUpdates 호출에서 다시 받지 않도록 확인 처리
+					b.call("getUpdates", map[string]interface{}{"offset": offset, "timeout": 0}, nil)
+					return m.Chat, true, nil
+				}
+			}
+		}
+	}
+}
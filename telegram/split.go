@@ -0,0 +1,155 @@
+package telegram
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxMessageLength는 텔레그램 메시지 한 개의 최대 길이(글자 수)입니다
+const MaxMessageLength = 4096
+
+// htmlTag는 메시지의 HTML 태그입니다 (<b>, </b>, <a href="...">)
+var htmlTag = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9-]*)[^>]*>`)
+
+// SplitMessage는 긴 메시지를 limit 글자 이하로 줄 단위로 나눕니다
+// 나뉜 부분에 걸친 태그(<b>, <pre> 등)는 앞부분 끝에서 닫고 다음 부분 앞에서 다시 엽니다
+// 한 줄이 limit보다 길면 태그와 엔티티 바깥에서 글자 단위로 자릅니다
+func SplitMessage(message string, limit int) []string {
+	if utf8.RuneCountInString(message) <= limit {
+		return []string{message}
+	}
+
+	// 닫는 태그와 다시 여는 태그를 붙일 여유를 두고 자름
+	budget := limit - reserve(message)
+
+	var parts []string
+	var current strings.Builder
+	var open []string // 현재 열려 있는 태그 (여는 태그 원문)
+	prefix := 0       // current 앞에 다시 연 태그 길이 (바이트)
+
+	flush := func() {
+		parts = append(parts, strings.TrimRight(current.String(), "\n")+closingTags(open))
+		current.Reset()
+		reopened := strings.Join(open, "")
+		current.WriteString(reopened)
+		prefix = len(reopened)
+	}
+
+	for _, line := range splitLines(message, budget) {
+		if current.Len() > prefix && utf8.RuneCountInString(current.String())+utf8.RuneCountInString(line) > budget {
+			flush()
+		}
+		current.WriteString(line)
+		open = trackTags(open, line)
+	}
+	if current.Len() > prefix {
+		flush()
+	}
+	return parts
+}
+
+// reserve는 태그를 닫고 다시 여는 데 필요한 여유 글자 수입니다
+func reserve(message string) int {
+	longest := 0
+	for _, tag := range htmlTag.FindAllString(message, -1) {
+		longest = max(longest, utf8.RuneCountInString(tag))
+	}
+	// 중첩 태그 몇 단계를 여닫을 여유
+	return min(longest*4, MaxMessageLength/4)
+}
+
+// htmlToken은 자를 수 없는 단위입니다 (태그, &amp; 같은 엔티티)
+var htmlToken = regexp.MustCompile(`<[^>]*>|&#?[a-zA-Z0-9]+;`)
+
+// splitLines는 메시지를 줄(줄바꿈 포함) 단위로 나누고, limit보다 긴 줄은 글자 단위로 자릅니다
+// 태그나 엔티티 중간에서는 자르지 않으며 (텔레그램이 잘린 태그를 거부함), 자른 곳에 걸친 태그는 SplitMessage가 닫고 다시 엽니다
+func splitLines(message string, limit int) []string {
+	var lines []string
+	for _, line := range strings.SplitAfter(message, "\n") {
+		if utf8.RuneCountInString(line) > limit {
+			lines = append(lines, splitLine(line, limit)...)
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// splitLine은 긴 줄을 태그와 엔티티 바깥에서 limit 글자 이하로 자릅니다 (limit보다 긴 태그 하나는 그대로 한 조각)
+func splitLine(line string, limit int) []string {
+	var pieces []string
+	var current strings.Builder
+	count := 0
+
+	add := func(token string) {
+		n := utf8.RuneCountInString(token)
+		if count > 0 && count+n > limit {
+			pieces = append(pieces, current.String())
+			current.Reset()
+			count = 0
+		}
+		current.WriteString(token)
+		count += n
+	}
+
+	rest := line
+	for rest != "" {
+		loc := htmlToken.FindStringIndex(rest)
+		text := rest
+		if loc != nil {
+			text = rest[:loc[0]]
+		}
+		for _, r := range text {
+			add(string(r))
+		}
+		if loc == nil {
+			break
+		}
+		add(rest[loc[0]:loc[1]])
+		rest = rest[loc[1]:]
+	}
+	if count > 0 {
+		pieces = append(pieces, current.String())
+	}
+	return pieces
+}
+
+// trackTags는 한 줄의 태그를 반영해 열려 있는 태그 목록을 갱신합니다
+func trackTags(open []string, line string) []string {
+	for _, m := range htmlTag.FindAllStringSubmatch(line, -1) {
+		name := strings.ToLower(m[2])
+		if m[1] == "" {
+			open = append(open, m[0])
+			continue
+		}
+		// 가장 최근에 연 같은 이름의 태그를 닫음
+		for i := len(open) - 1; i >= 0; i-- {
+			if tagName(open[i]) == name {
+				open = append(open[:i], open[i+1:]...)
+				break
+			}
+		}
+	}
+	return open
+}
+
+// closingTags는 열려 있는 태그를 역순으로 닫는 문자열입니다
+func closingTags(open []string) string {
+	var sb strings.Builder
+	for i := len(open) - 1; i >= 0; i-- {
+		sb.WriteString("</" + tagName(open[i]) + ">")
+	}
+	return sb.String()
+}
+
+// tagName은 여는 태그의 이름입니다 (<a href="..."> → a)
+func tagName(tag string) string {
+	m := htmlTag.FindStringSubmatch(tag)
+	if m == nil {
+		return ""
+	}
+	return strings.ToLower(m[2])
+}
@@ -0,0 +1,147 @@
+package telegram
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// entity는 완전한 HTML 엔티티입니다
+var entity = regexp.MustCompile(`^&#?[a-zA-Z0-9]+;`)
+
+// checkPart는 나뉜 부분이 길이 제한 안이고 태그와 엔티티가 온전하며 태그가 모두 닫혔는지 검사합니다
+func checkPart(t *testing.T, part string, limit int) {
+	t.Helper()
+
+	if n := utf8.RuneCountInString(part); n > limit {
+		t.Errorf("길이 %d > %d: %q", n, limit, part)
+	}
+
+	var open []string
+	for i := 0; i < len(part); i++ {
+		switch part[i] {
+		case '<':
+			end := strings.IndexByte(part[i:], '>')
+			if end < 0 {
+				t.Fatalf("잘린 태그: %q", part[i:])
+			}
+			m := htmlTag.FindStringSubmatch(part[i : i+end+1])
+			if m == nil {
+				t.Fatalf("잘못된 태그: %q", part[i:i+end+1])
+			}
+			name := strings.ToLower(m[2])
+			if m[1] == "" {
+				open = append(open, name)
+			} else {
+				if len(open) == 0 || open[len(open)-1] != name {
+					t.Fatalf("짝이 맞지 않는 닫는 태그 </%s>: %q", name, part)
+				}
+				open = open[:len(open)-1]
+			}
+			i += end
+		case '&':
+			if !entity.MatchString(part[i:]) {
+				t.Fatalf("잘린 엔티티: %q", part[i:min(len(part), i+8)])
+			}
+		}
+	}
+	if len(open) > 0 {
+		t.Errorf("닫히지 않은 태그 %v: %q", open, part)
+	}
+}
+
+// text는 태그와 줄바꿈을 뺀 본문입니다
+func text(message string) string {
+	return strings.ReplaceAll(htmlTag.ReplaceAllString(message, ""), "\n", "")
+}
+
+func TestSplitMessageShort(t *testing.T) {
+	message := "<b>짧은</b> 메시지 &amp; 끝"
+	parts := SplitMessage(message, 100)
+	if len(parts) != 1 || parts[0] != message {
+		t.Fatalf("나누지 않아야 함: %q", parts)
+	}
+}
+
+func TestSplitMessageBoundaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		limit   int
+	}{
+		{
+			name:    "엔티티만 있는 긴 줄",
+			message: strings.Repeat("&amp;", 200),
+			limit:   100,
+		},
+		{
+			name:    "경계에 걸친 엔티티",
+			message: strings.Repeat("a", 97) + "&quot;" + strings.Repeat("b", 200),
+			limit:   120,
+		},
+		{
+			name:    "숫자 엔티티",
+			message: strings.Repeat("x&#39;", 100),
+			limit:   64,
+		},
+		{
+			name:    "굵은 글씨 안의 긴 줄",
+			message: "<b>" + strings.Repeat("가", 500) + "</b>",
+			limit:   100,
+		},
+		{
+			name:    "중첩 태그와 엔티티",
+			message: "<b>제목 <i>" + strings.Repeat("A &lt; B ", 80) + "</i></b> 끝",
+			limit:   100,
+		},
+		{
+			name:    "링크 태그",
+			message: strings.Repeat("링크 ", 30) + `<a href="https://dhlottery.co.kr/gameResult.do?method=byWin&amp;drwNo=1247">` + strings.Repeat("결과", 100) + "</a>",
+			limit:   120,
+		},
+		{
+			name:    "여러 줄에 걸친 pre",
+			message: "<pre>" + strings.Repeat("1등 | 2,000,000,000원 &amp; 당첨\n", 40) + "</pre>\n끝",
+			limit:   200,
+		},
+		{
+			name:    "줄마다 태그가 바뀌는 메시지",
+			message: strings.Repeat("<b>계정</b>: 구매 &gt; 5게임 <code>12 34 45</code>\n", 60),
+			limit:   150,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := SplitMessage(tt.message, tt.limit)
+			if len(parts) < 2 {
+				t.Fatalf("나뉘어야 함: %d개", len(parts))
+			}
+			for _, part := range parts {
+				checkPart(t, part, tt.limit)
+			}
+			if got, want := text(strings.Join(parts, "")), text(tt.message); got != want {
+				t.Errorf("본문이 달라짐\n got: %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSplitLineKeepsTokens(t *testing.T) {
+	line := "ab&amp;cd<b>ef</b>"
+	for limit := 1; limit <= len(line); limit++ {
+		pieces := splitLine(line, limit)
+		if strings.Join(pieces, "") != line {
+			t.Fatalf("limit %d: 이어 붙인 결과가 다름: %q", limit, pieces)
+		}
+		for _, piece := range pieces {
+			if strings.Count(piece, "<") != strings.Count(piece, ">") {
+				t.Errorf("limit %d: 태그가 잘림: %q", limit, piece)
+			}
+			if i := strings.IndexByte(piece, '&'); i >= 0 && !entity.MatchString(piece[i:]) {
+				t.Errorf("limit %d: 엔티티가 잘림: %q", limit, piece)
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package throttle은 알림 전송 정책(조용한 시간, 같은 알림 중복 제거, 이벤트별 전송 횟수 제한)을 적용합니다
+//
+// 보내거나 미룬 알림은 데이터 디렉토리의 state 폴더에 기록해 두므로,
+// 단발 명령과 serve 모드, 서로 다른 예약 작업 사이에서도 같은 알림이 반복되지 않습니다.
+package throttle
+
+import (
+	"crypto/sha256"
+	"dhlottery/datadir"
+	"dhlottery/message"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stateFileName은 데이터 디렉토리의 state 폴더에 저장하는 알림 전송 기록입니다
+const stateFileName = "notify_state.json"
+
+// Limit은 채팅방별 이벤트 전송 횟수 제한입니다 (Per 동안 Max번까지)
+type Limit struct {
+	Events []string // 이벤트 ("failure"는 "failure.login" 등 모든 세부 종류, 비우면 전체)
+	Max    int
+	Per    time.Duration
+}
+
+func (l Limit) matches(event string) bool {
+	return len(l.Events) == 0 || slices.ContainsFunc(l.Events, func(pattern string) bool { return message.MatchEvent(pattern, event) })
+}
+
+// Policy는 알림 전송 정책입니다 (zero 값이면 모든 알림을 바로 보냄)
+type Policy struct {
+	// 조용한 시간 (자정부터의 시각, 같으면 없음, Start가 End보다 늦으면 자정을 넘김)
+	QuietStart time.Duration
+	QuietEnd   time.Duration
+	Location   *time.Location
+
+	Dedupe time.Duration // 같은 채팅방에 같은 알림은 이 시간 안에 한 번만 (0이면 중복 제거 안 함)
+	Limits []Limit
+
+	// 이 중요도 이상은 조용한 시간, 중복 제거, 전송 제한과 관계없이 바로 보냄
+	Override message.Severity
+}
+
+// Action은 알림 처리 방법입니다
+type Action int
+
+const (
+	Send  Action = iota // 바로 보냄
+	Defer               // 조용한 시간이 끝나면 보냄
+	Drop                // 보내지 않음 (중복 또는 전송 제한)
+)
+
+// Decision은 알림 처리 결과입니다
+type Decision struct {
+	Action Action
+	Until  time.Time // Defer일 때 보낼 시각
+	Reason string    // Defer, Drop 사유 (로그용)
+}
+
+// entry는 보내거나 미룬 알림 기록입니다
+type entry struct {
+	ChatID string    `json:"chatId"`
+	Event  string    `json:"event"`
+	Hash   string    `json:"hash"` // 메시지 내용 해시 (중복 확인용)
+	At     time.Time `json:"at"`
+}
+
+var mu sync.Mutex
+
+// Enabled는 적용할 정책이 있는지 확인합니다
+func (p Policy) Enabled() bool {
+	return p.QuietStart != p.QuietEnd || p.Dedupe > 0 || len(p.Limits) > 0
+}
+
+// Check는 채팅방으로 보낼 알림을 바로 보낼지, 미룰지, 버릴지 정하고 보내거나 미룬 알림을 기록합니다
+// Override 이상의 알림은 기록하지 않으므로 뒤따르는 일반 알림의 중복 제거와 전송 제한에 영향을 주지 않습니다
+func (p Policy) Check(now time.Time, chatID, event string, severity message.Severity, text string) Decision {
+	if !p.Enabled() || severity >= p.Override {
+		return Decision{Action: Send}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		log.Printf("⚠️  %v (새로 기록합니다)\n", err)
+	}
+	hash := digest(text)
+
+	decision := p.decide(now, entries, chatID, event, hash)
+	if decision.Action != Drop {
+		entries = append(entries, entry{ChatID: chatID, Event: event, Hash: hash, At: now})
+		if err := save(p.prune(now, entries)); err != nil {
+			log.Printf("⚠️  %v\n", err)
+		}
+	}
+	return decision
+}
+
+// decide는 중복, 전송 제한, 조용한 시간 순서로 확인합니다
+func (p Policy) decide(now time.Time, entries []entry, chatID, event, hash string) Decision {
+	if p.Dedupe > 0 {
+		for _, e := range entries {
+			if e.ChatID == chatID && e.Hash == hash && now.Sub(e.At) < p.Dedupe {
+				return Decision{Action: Drop, Reason: fmt.Sprintf("%s 안에 같은 알림을 보냄 (중복 제거)", formatDuration(p.Dedupe))}
+			}
+		}
+	}
+
+	for _, limit := range p.Limits {
+		if !limit.matches(event) {
+			continue
+		}
+		count := 0
+		for _, e := range entries {
+			if e.ChatID == chatID && now.Sub(e.At) < limit.Per && limit.matches(e.Event) {
+				count++
+			}
+		}
+		if count >= limit.Max {
+			return Decision{Action: Drop, Reason: fmt.Sprintf("%s 동안 %d번까지 (전송 제한)", formatDuration(limit.Per), limit.Max)}
+		}
+	}
+
+	if until, quiet := p.QuietUntil(now); quiet {
+		return Decision{Action: Defer, Until: until, Reason: "조용한 시간"}
+	}
+	return Decision{Action: Send}
+}
+
+// QuietUntil은 now가 조용한 시간이면 조용한 시간이 끝나는 시각을 반환합니다
+func (p Policy) QuietUntil(now time.Time) (time.Time, bool) {
+	if p.QuietStart == p.QuietEnd {
+		return time.Time{}, false
+	}
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	local := now.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	clock := local.Sub(midnight)
+
+	switch {
+	case p.QuietStart < p.QuietEnd && clock >= p.QuietStart && clock < p.QuietEnd:
+		return midnight.Add(p.QuietEnd), true
+	case p.QuietStart > p.QuietEnd && clock >= p.QuietStart:
+		// 자정을 넘기는 조용한 시간의 시작 쪽 (예: 23:00 ~ 다음 날 07:00)
+		return midnight.AddDate(0, 0, 1).Add(p.QuietEnd), true
+	case p.QuietStart > p.QuietEnd && clock < p.QuietEnd:
+		return midnight.Add(p.QuietEnd), true
+	}
+	return time.Time{}, false
+}
+
+// prune은 더 이상 확인에 쓰이지 않는 오래된 기록을 지웁니다
+func (p Policy) prune(now time.Time, entries []entry) []entry {
+	keep := p.Dedupe
+	for _, limit := range p.Limits {
+		keep = max(keep, limit.Per)
+	}
+	return slices.DeleteFunc(entries, func(e entry) bool { return now.Sub(e.At) >= keep })
+}
+
+// formatDuration은 기간을 짧게 표시합니다 (24h0m0s -> 24h)
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// digest는 중복 확인에 쓰는 메시지 내용 해시입니다
+func digest(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// load는 알림 전송 기록을 읽어옵니다 (파일이 없으면 빈 기록)
+func load() ([]entry, error) {
+	data, err := os.ReadFile(datadir.Path(datadir.State, stateFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("알림 전송 기록 읽기 실패: %w", err)
+	}
+
+	var entries []entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("알림 전송 기록 파싱 실패: %w", err)
+	}
+	return entries, nil
+}
+
+// save는 알림 전송 기록을 저장합니다 (임시 파일에 쓴 뒤 이름을 바꿔 중간에 끊겨도 기존 기록 유지)
+func save(entries []entry) error {
+	if err := os.MkdirAll(datadir.Path(datadir.State), 0700); err != nil {
+		return fmt.Errorf("상태 디렉토리 생성 실패: %w", err)
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON 마샬링 실패: %w", err)
+	}
+
+	path := datadir.Path(datadir.State, stateFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("알림 전송 기록 저장 실패: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("알림 전송 기록 저장 실패: %w", err)
+	}
+	return nil
+}
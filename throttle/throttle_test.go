@@ -0,0 +1,173 @@
+package throttle
+
+import (
+	"dhlottery/datadir"
+	"dhlottery/message"
+	"os"
+	"testing"
+	"time"
+)
+
+var kst = time.FixedZone("KST", 9*60*60)
+
+// useTempDataDir는 알림 전송 기록을 테스트마다 새 데이터 디렉토리에 저장하게 합니다
+func useTempDataDir(t *testing.T) {
+	t.Helper()
+	if err := datadir.Init(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, 10, day, hour, minute, 0, 0, kst)
+}
+
+func TestQuietUntilAcrossMidnight(t *testing.T) {
+	p := Policy{QuietStart: 23 * time.Hour, QuietEnd: 7 * time.Hour, Location: kst}
+
+	tests := []struct {
+		now   time.Time
+		quiet bool
+		until time.Time
+	}{
+		{at(19, 22, 59), false, time.Time{}},
+		{at(19, 23, 0), true, at(20, 7, 0)},
+		{at(19, 23, 59), true, at(20, 7, 0)},
+		{at(20, 0, 0), true, at(20, 7, 0)},
+		{at(20, 6, 59), true, at(20, 7, 0)},
+		{at(20, 7, 0), false, time.Time{}},
+		{at(20, 12, 0), false, time.Time{}},
+		// 다른 시간대로 들어와도 정책 시간대 기준 (UTC 14:30 = KST 23:30)
+		{time.Date(2026, 10, 19, 14, 30, 0, 0, time.UTC), true, at(20, 7, 0)},
+	}
+
+	for _, tt := range tests {
+		until, quiet := p.QuietUntil(tt.now)
+		if quiet != tt.quiet || !until.Equal(tt.until) {
+			t.Errorf("%s: (%s, %v), want (%s, %v)", tt.now.Format(time.RFC3339), until, quiet, tt.until, tt.quiet)
+		}
+	}
+}
+
+func TestQuietUntilSameDay(t *testing.T) {
+	p := Policy{QuietStart: 13 * time.Hour, QuietEnd: 14 * time.Hour, Location: kst}
+
+	if _, quiet := p.QuietUntil(at(19, 12, 59)); quiet {
+		t.Error("12:59는 조용한 시간이 아님")
+	}
+	if until, quiet := p.QuietUntil(at(19, 13, 30)); !quiet || !until.Equal(at(19, 14, 0)) {
+		t.Errorf("13:30: (%s, %v), want (14:00, true)", until, quiet)
+	}
+	if _, quiet := p.QuietUntil(at(19, 14, 0)); quiet {
+		t.Error("14:00은 조용한 시간이 아님")
+	}
+}
+
+func TestCheckDefersDuringQuietHours(t *testing.T) {
+	useTempDataDir(t)
+	p := Policy{QuietStart: 23 * time.Hour, QuietEnd: 7 * time.Hour, Location: kst, Override: message.SeverityCritical}
+
+	d := p.Check(at(19, 23, 30), "chat", "result", message.SeverityInfo, "낙첨")
+	if d.Action != Defer || !d.Until.Equal(at(20, 7, 0)) {
+		t.Fatalf("조용한 시간에는 다음 날 07:00으로 미뤄야 함: %+v", d)
+	}
+
+	d = p.Check(at(19, 23, 30), "chat", "winning", message.SeverityCritical, "1등 당첨")
+	if d.Action != Send {
+		t.Fatalf("critical은 바로 보내야 함: %+v", d)
+	}
+}
+
+func TestCheckDedupeWindow(t *testing.T) {
+	useTempDataDir(t)
+	p := Policy{Dedupe: time.Hour, Override: message.SeverityCritical}
+
+	steps := []struct {
+		now    time.Time
+		chatID string
+		text   string
+		want   Action
+	}{
+		{at(19, 10, 0), "chat", "예치금 부족", Send},
+		{at(19, 10, 30), "chat", "예치금 부족", Drop},
+		{at(19, 10, 30), "other", "예치금 부족", Send}, // 다른 채팅방
+		{at(19, 10, 45), "chat", "구매 완료", Send},   // 다른 내용
+		{at(19, 11, 0), "chat", "예치금 부족", Send},   // 1시간 지남
+		{at(19, 11, 59), "chat", "예치금 부족", Drop},
+	}
+
+	for i, step := range steps {
+		d := p.Check(step.now, step.chatID, "balance", message.SeverityWarning, step.text)
+		if d.Action != step.want {
+			t.Errorf("%d단계 %s %s %q: %v, want %v (%s)", i+1, step.now.Format("15:04"), step.chatID, step.text, d.Action, step.want, d.Reason)
+		}
+	}
+}
+
+func TestCheckLimitWindow(t *testing.T) {
+	useTempDataDir(t)
+	p := Policy{
+		Limits:   []Limit{{Events: []string{"failure"}, Max: 2, Per: time.Hour}},
+		Override: message.SeverityCritical,
+	}
+
+	steps := []struct {
+		now   time.Time
+		event string
+		want  Action
+	}{
+		{at(19, 10, 0), "failure.login", Send},
+		{at(19, 10, 10), "failure.buy", Send},
+		{at(19, 10, 20), "failure.login", Drop}, // failure 전체로 2번까지
+		{at(19, 10, 20), "result", Send},        // 제한 대상 아님
+		{at(19, 11, 0), "failure.login", Send},  // 10:00 기록이 창을 벗어남
+		{at(19, 11, 5), "failure.login", Drop},
+		{at(19, 11, 10), "failure.login", Send},
+	}
+
+	for i, step := range steps {
+		d := p.Check(step.now, "chat", step.event, message.SeverityError, step.event)
+		if d.Action != step.want {
+			t.Errorf("%d단계 %s %s: %v, want %v (%s)", i+1, step.now.Format("15:04"), step.event, d.Action, step.want, d.Reason)
+		}
+	}
+}
+
+func TestCheckOverrideNotCounted(t *testing.T) {
+	useTempDataDir(t)
+	p := Policy{
+		Dedupe:   time.Hour,
+		Limits:   []Limit{{Max: 1, Per: time.Hour}},
+		Override: message.SeverityError,
+	}
+
+	// 제한을 넘겨도 Override 이상은 보내고, 기록하지 않음
+	for i := 0; i < 3; i++ {
+		if d := p.Check(at(19, 10, i), "chat", "failure.buy", message.SeverityError, "구매 실패"); d.Action != Send {
+			t.Fatalf("Override 이상은 바로 보내야 함: %+v", d)
+		}
+	}
+
+	if d := p.Check(at(19, 10, 5), "chat", "result", message.SeverityInfo, "낙첨"); d.Action != Send {
+		t.Fatalf("Override 알림이 전송 제한에 세어짐: %+v", d)
+	}
+	if d := p.Check(at(19, 10, 6), "chat", "result", message.SeverityInfo, "낙첨"); d.Action != Drop {
+		t.Fatalf("일반 알림은 제한되어야 함: %+v", d)
+	}
+}
+
+func TestSaveLeavesNoTempFile(t *testing.T) {
+	useTempDataDir(t)
+
+	if err := save([]entry{{ChatID: "chat", Event: "result", Hash: digest("낙첨"), At: at(19, 10, 0)}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(datadir.Path(datadir.State, stateFileName+".tmp")); !os.IsNotExist(err) {
+		t.Errorf("임시 파일이 남음: %v", err)
+	}
+
+	entries, err := load()
+	if err != nil || len(entries) != 1 || entries[0].ChatID != "chat" {
+		t.Errorf("저장한 기록을 다시 읽지 못함: %+v, %v", entries, err)
+	}
+}
@@ -0,0 +1,304 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// 금고 파일 형식
+const (
+	formatVersion = 1
+	keySize       = 32 // AES-256
+
+	kdfScrypt  = "scrypt"
+	kdfKeyFile = "keyfile"
+
+	// scrypt 매개변수 (2^15, 약 32MB 메모리)
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// additionalData는 암호문에 묶이는 고정 문자열입니다 (다른 용도의 파일과 혼용 방지)
+var additionalData = []byte("dhlottery-vault-v1")
+
+// ErrWrongKey는 암호가 틀렸거나 파일이 손상되어 복호화에 실패했을 때 반환됩니다
+var ErrWrongKey = errors.New("금고를 열 수 없습니다 (암호 또는 키 파일이 틀렸거나 파일이 손상됨)")
+
+// Key는 금고를 여는 암호 또는 키 파일입니다
+type Key struct {
+	passphrase []byte
+	raw        []byte // 키 파일에서 읽은 32바이트 키
+}
+
+// Passphrase는 암호로 만든 키를 반환합니다 (scrypt로 암호화 키 유도)
+func Passphrase(passphrase string) Key {
+	return Key{passphrase: []byte(passphrase)}
+}
+
+// KeyFromFile은 키 파일(base64로 인코딩된 32바이트)에서 키를 읽습니다
+func KeyFromFile(path string) (Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, fmt.Errorf("키 파일 읽기 실패: %w", err)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(raw) != keySize {
+		return Key{}, fmt.Errorf("키 파일 형식 오류: %s (base64로 인코딩된 %d바이트 키가 필요합니다)", path, keySize)
+	}
+	return Key{raw: raw}, nil
+}
+
+// GenerateKeyFile은 새 무작위 키를 만들어 파일에 저장합니다 (권한 0600)
+func GenerateKeyFile(path string) (Key, error) {
+	raw := make([]byte, keySize)
+	if _, err := rand.Read(raw); err != nil {
+		return Key{}, fmt.Errorf("키 생성 실패: %w", err)
+	}
+
+	if err := writeFileAtomic(path, []byte(base64.StdEncoding.EncodeToString(raw)+"\n")); err != nil {
+		return Key{}, fmt.Errorf("키 파일 저장 실패: %w", err)
+	}
+	return Key{raw: raw}, nil
+}
+
+// kdfParams는 암호화 키 유도 방식입니다
+type kdfParams struct {
+	Name string `json:"name"`
+	Salt []byte `json:"salt,omitempty"`
+	N    int    `json:"n,omitempty"`
+	R    int    `json:"r,omitempty"`
+	P    int    `json:"p,omitempty"`
+}
+
+// fileFormat은 디스크에 저장되는 금고 파일입니다 (비밀 값은 모두 암호문 안에 있음)
+type fileFormat struct {
+	Version    int       `json:"version"`
+	KDF        kdfParams `json:"kdf"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+// payload는 암호화되는 내용입니다
+type payload struct {
+	Secrets   map[string]string `json:"secrets"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// Vault는 복호화된 금고입니다
+type Vault struct {
+	path    string
+	kdf     kdfParams
+	aesKey  []byte
+	secrets map[string]string
+}
+
+// Exists는 금고 파일이 있는지 확인합니다
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Create는 새 금고를 만듭니다 (Save를 호출해야 파일에 저장됨)
+func Create(path string, key Key) (*Vault, error) {
+	v := &Vault{path: path, secrets: make(map[string]string)}
+	if err := v.setKey(key); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Open은 금고 파일을 열어 복호화합니다
+func Open(path string, key Key) (*Vault, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("금고 파일 읽기 실패: %w", err)
+	}
+
+	var f fileFormat
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("금고 파일 파싱 실패: %w", err)
+	}
+	if f.Version != formatVersion {
+		return nil, fmt.Errorf("지원하지 않는 금고 파일 버전입니다 (%d)", f.Version)
+	}
+
+	aesKey, err := deriveKey(key, f.KDF)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(aesKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, f.Nonce, f.Ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+
+	var p payload
+	if err := json.Unmarshal(plaintext, &p); err != nil {
+		return nil, fmt.Errorf("금고 내용 파싱 실패: %w", err)
+	}
+	if p.Secrets == nil {
+		p.Secrets = make(map[string]string)
+	}
+
+	return &Vault{path: path, kdf: f.KDF, aesKey: aesKey, secrets: p.Secrets}, nil
+}
+
+// Path는 금고 파일 경로를 반환합니다
+func (v *Vault) Path() string {
+	return v.path
+}
+
+// Get은 이름으로 비밀 값을 찾습니다
+func (v *Vault) Get(name string) (string, bool) {
+	secret, ok := v.secrets[name]
+	return secret, ok
+}
+
+// Set은 비밀 값을 추가하거나 바꿉니다
+func (v *Vault) Set(name, secret string) {
+	v.secrets[name] = secret
+}
+
+// Remove는 비밀 값을 삭제하고, 삭제된 항목이 있었는지 반환합니다
+func (v *Vault) Remove(name string) bool {
+	_, ok := v.secrets[name]
+	delete(v.secrets, name)
+	return ok
+}
+
+// Names는 저장된 항목 이름을 정렬하여 반환합니다
+func (v *Vault) Names() []string {
+	names := make([]string, 0, len(v.secrets))
+	for name := range v.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rotate는 새 키로 금고를 다시 암호화하여 저장합니다
+func (v *Vault) Rotate(key Key) error {
+	if err := v.setKey(key); err != nil {
+		return err
+	}
+	return v.Save()
+}
+
+// Save는 금고를 암호화하여 저장합니다 (저장할 때마다 새 nonce 사용)
+func (v *Vault) Save() error {
+	plaintext, err := json.Marshal(payload{Secrets: v.secrets, UpdatedAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("금고 내용 마샬링 실패: %w", err)
+	}
+
+	gcm, err := newGCM(v.aesKey)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("nonce 생성 실패: %w", err)
+	}
+
+	data, err := json.MarshalIndent(fileFormat{
+		Version:    formatVersion,
+		KDF:        v.kdf,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, additionalData),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("금고 파일 마샬링 실패: %w", err)
+	}
+
+	if err := writeFileAtomic(v.path, data); err != nil {
+		return fmt.Errorf("금고 파일 저장 실패: %w", err)
+	}
+	return nil
+}
+
+// setKey는 새 키(암호는 새 salt)로 암호화 키를 유도합니다
+func (v *Vault) setKey(key Key) error {
+	kdf := kdfParams{Name: kdfKeyFile}
+	if key.raw == nil {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("salt 생성 실패: %w", err)
+		}
+		kdf = kdfParams{Name: kdfScrypt, Salt: salt, N: scryptN, R: scryptR, P: scryptP}
+	}
+
+	aesKey, err := deriveKey(key, kdf)
+	if err != nil {
+		return err
+	}
+	v.kdf = kdf
+	v.aesKey = aesKey
+	return nil
+}
+
+// deriveKey는 금고 파일의 방식에 맞게 암호화 키를 만듭니다
+func deriveKey(key Key, kdf kdfParams) ([]byte, error) {
+	switch kdf.Name {
+	case kdfKeyFile:
+		if key.raw == nil {
+			return nil, fmt.Errorf("이 금고는 키 파일로 잠겨 있습니다 (vault.keyFile 설정 필요)")
+		}
+		return key.raw, nil
+
+	case kdfScrypt:
+		if key.raw != nil {
+			return nil, fmt.Errorf("이 금고는 암호로 잠겨 있습니다 (키 파일 대신 암호 필요)")
+		}
+		if len(key.passphrase) == 0 {
+			return nil, fmt.Errorf("금고 암호가 비어 있습니다")
+		}
+		aesKey, err := scrypt.Key(key.passphrase, kdf.Salt, kdf.N, kdf.R, kdf.P, keySize)
+		if err != nil {
+			return nil, fmt.Errorf("암호화 키 유도 실패: %w", err)
+		}
+		return aesKey, nil
+	}
+
+	return nil, fmt.Errorf("알 수 없는 키 유도 방식입니다: %s", kdf.Name)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("AES 초기화 실패: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic은 임시 파일에 쓴 뒤 이름을 바꿔 저장합니다 (권한 0600)
+func writeFileAtomic(path string, data []byte) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
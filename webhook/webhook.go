@@ -0,0 +1,189 @@
+// Package webhook은 JSON 본문을 POST하는 웹훅(슬랙 incoming webhook, 디스코드 웹훅)으로 알림을 보냅니다
+//
+// 웹훅 주소에는 토큰이 들어 있으므로 로그와 오류 메시지에는 Redact로 가린 주소만 남깁니다.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 요청 설정
+const (
+	requestTimeout = 15 * time.Second // 요청 시간 제한
+	maxAttempts    = 3                // 요청당 최대 시도 횟수
+	maxRetryAfter  = time.Minute      // 이보다 오래 기다리라고 하면 재시도하지 않음 (보관함이 나중에 다시 보냄)
+	retryBackoff   = 2 * time.Second  // 네트워크 오류, 5xx 응답 시 첫 대기 시간 (시도마다 두 배)
+	maxErrorBody   = 200              // 오류 메시지에 넣을 응답 본문 길이
+)
+
+// client는 웹훅 요청에 쓰는 HTTP 클라이언트입니다 (프록시는 HTTPS_PROXY 환경변수)
+var client = &http.Client{Timeout: requestTimeout}
+
+// Queue는 웹훅 알림을 바로 보내지 않고 맡아 두었다가 전달하는 보관함입니다 (outbox)
+type Queue interface {
+	EnqueueWebhook(channel, url string, payload []byte, notBefore time.Time) error
+}
+
+// StatusError는 웹훅 서버가 실패 응답을 보낸 요청입니다
+type StatusError struct {
+	Channel string
+	Code    int
+	Body    string // 응답 본문 앞부분 (슬랙은 invalid_blocks 같은 오류 코드)
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s 웹훅 오류 (%d): %s", e.Channel, e.Code, e.Body)
+}
+
+// Permanent는 다시 보내도 성공할 수 없는 응답인지 반환합니다 (잘못된 본문, 삭제된 웹훅 등, 429는 재시도)
+func (e *StatusError) Permanent() bool {
+	return e.Code >= 400 && e.Code < 500 && e.Code != http.StatusTooManyRequests
+}
+
+// Hook은 알림을 보낼 웹훅 하나입니다
+type Hook struct {
+	Channel string // 채널 이름 (slack, discord, 로그와 보관함 표시용)
+	URL     string
+	queue   Queue // 설정되어 있으면 SendSafe는 보관함을 거쳐 전송
+}
+
+// New는 웹훅을 생성합니다 (queue가 nil이면 바로 전송)
+func New(channel, url string, queue Queue) *Hook {
+	return &Hook{Channel: channel, URL: url, queue: queue}
+}
+
+// Send는 본문을 JSON으로 바꿔 바로 보냅니다
+func (h *Hook) Send(payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("JSON 마샬링 실패: %w", err)
+	}
+	return Post(h.Channel, h.URL, data)
+}
+
+// SendSafe는 본문을 보관함에 넣거나 (보관함이 없으면) 바로 보내고 에러를 로그로 출력합니다
+// notBefore는 보관함이 있을 때만 적용됩니다 (조용한 시간)
+func (h *Hook) SendSafe(payload interface{}, notBefore time.Time) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("⚠️  %s 알림 생성 실패: %v\n", h.Channel, err)
+		return
+	}
+	if h.queue != nil {
+		err := h.queue.EnqueueWebhook(h.Channel, h.URL, data, notBefore)
+		if err == nil {
+			return
+		}
+		log.Printf("⚠️  알림 보관함 저장 실패, 바로 전송합니다: %v\n", err)
+	}
+	if err := Post(h.Channel, h.URL, data); err != nil {
+		log.Printf("⚠️  %v\n", err)
+	}
+}
+
+// Post는 JSON 본문을 웹훅으로 보냅니다
+// 429 응답은 Retry-After만큼 기다렸다가, 네트워크 오류와 5xx 응답은 점점 길게 기다렸다가 다시 시도합니다
+func Post(channel, webhookURL string, payload []byte) error {
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		wait, err := post(channel, webhookURL, payload)
+		if err == nil {
+			log.Printf("✅ %s 메시지 전송 완료\n", channel)
+			return nil
+		}
+		if wait == 0 || attempt >= maxAttempts {
+			return err
+		}
+
+		if wait < 0 {
+			wait = backoff
+			backoff *= 2
+		}
+		log.Printf("⏳ %s 웹훅 재시도 (%d/%d, %v 후): %v\n", channel, attempt, maxAttempts-1, wait, err)
+		time.Sleep(wait)
+	}
+}
+
+// post는 요청을 한 번 보냅니다
+// 다시 시도할 수 있는 실패면 wait에 기다릴 시간(-1이면 기본 간격)을, 아니면 0을 반환합니다
+func post(channel, webhookURL string, payload []byte) (wait time.Duration, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("%s 웹훅 요청 생성 실패 (%s): %w", channel, Redact(webhookURL), unwrapURLError(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return -1, fmt.Errorf("%s 웹훅 호출 실패 (%s): %w", channel, Redact(webhookURL), unwrapURLError(err))
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode/100 == 2 {
+		return 0, nil
+	}
+
+	statusErr := &StatusError{Channel: channel, Code: resp.StatusCode, Body: truncate(strings.TrimSpace(string(body)), maxErrorBody)}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		if retryAfter > maxRetryAfter {
+			return 0, statusErr
+		}
+		return max(retryAfter, time.Second), statusErr
+	case resp.StatusCode >= 500:
+		return -1, statusErr
+	}
+	return 0, statusErr
+}
+
+// parseRetryAfter는 Retry-After 헤더(초, 디스코드는 소수점 포함)를 해석합니다 (없으면 0)
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// unwrapURLError는 웹훅 주소(토큰 포함)가 들어 있는 url.Error에서 원인만 꺼냅니다
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// Redact는 로그에 표시할 웹훅 주소입니다 (호스트만 남기고 토큰이 든 경로는 가림)
+func Redact(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Host == "" {
+		return "********"
+	}
+	return u.Scheme + "://" + u.Host + "/********"
+}
+
+// truncate는 문자열을 최대 n글자로 자릅니다
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}